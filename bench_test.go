@@ -109,8 +109,37 @@ func BenchmarkDecodeObjectJSON(b *testing.B) {
 	}
 }
 
+type benchItem struct {
+	ID    int               `binn:"id"`
+	Name  string            `binn:"name"`
+	Score float64           `binn:"score"`
+	Tags  []string          `binn:"tags"`
+	Attrs map[string]uint16 `binn:"attrs"`
+}
+
+type benchDocument struct {
+	Title string      `binn:"title"`
+	Items []benchItem `binn:"items"`
+}
+
+func newBenchDocument() benchDocument {
+	doc := benchDocument{Title: "document"}
+	for i := 0; i < 20; i++ {
+		doc.Items = append(doc.Items, benchItem{
+			ID:    i * 1000,
+			Name:  "item name",
+			Score: float64(i) / 3,
+			Tags:  []string{"first", "second", "third"},
+			Attrs: map[string]uint16{"width": 640, "height": 480},
+		})
+	}
+
+	return doc
+}
+
 func BenchmarkEncodeList(b *testing.B) {
 	v := []string{"hello", "world"}
+	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
 		_, err := binngo.Marshal(v)
@@ -122,6 +151,54 @@ func BenchmarkEncodeList(b *testing.B) {
 
 func BenchmarkEncodeListJSON(b *testing.B) {
 	v := []string{"hello", "world"}
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, err := json.Marshal(v)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeInt(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, err := binngo.Marshal(789)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeDocument(b *testing.B) {
+	v := newBenchDocument()
+	data, err := binngo.Marshal(v)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := binngo.Marshal(v)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeDocumentJSON(b *testing.B) {
+	v := newBenchDocument()
+	data, err := json.Marshal(v)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
 		_, err := json.Marshal(v)
@@ -1,6 +1,11 @@
 package binngo
 
 import (
+	"bytes"
+	"io"
+
+	"github.com/et-nik/binngo/binnjson"
+	"github.com/et-nik/binngo/compress"
 	"github.com/et-nik/binngo/decode"
 	"github.com/et-nik/binngo/encode"
 )
@@ -12,3 +17,29 @@ func Marshal(v interface{}) ([]byte, error) {
 func Unmarshal(data []byte, v interface{}) error {
 	return decode.Unmarshal(data, v)
 }
+
+// NewDecoder returns a decoder reading the items of r, which may be raw
+// or compressed by compress.Writer. Its InputOffset counts
+// the decompressed bytes. A raw stream isn't read past the items
+// decoded, as with decode.NewDecoder, but a compressed one is buffered,
+// see compress.Reader.
+func NewDecoder(r io.Reader) *decode.Decoder {
+	return decode.NewDecoder(compress.NewReader(r))
+}
+
+// ToJSON converts the BINN items of data to JSON values, one per line.
+// See the binnjson package for the JSON shapes of the BINN types.
+func ToJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	err := binnjson.ToJSON(&buf, bytes.NewReader(data))
+
+	return buf.Bytes(), err
+}
+
+// FromJSON converts the JSON values of data to BINN items.
+func FromJSON(data []byte, opts *binnjson.Options) ([]byte, error) {
+	var buf bytes.Buffer
+	err := binnjson.FromJSON(&buf, bytes.NewReader(data), opts)
+
+	return buf.Bytes(), err
+}
@@ -82,3 +82,7 @@ const (
 )
 
 type Type int
+
+// MaxKeySize is the maximum size of an object key, which is stored
+// with a one byte size.
+const MaxKeySize = 255
@@ -0,0 +1,189 @@
+package binn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	// ErrUnknownStorage is returned by ParseStorage for the names
+	// that aren't storage classes.
+	ErrUnknownStorage = errors.New("binn: unknown storage")
+	// ErrUnknownType is returned by ParseType for the names and values
+	// that aren't types.
+	ErrUnknownType = errors.New("binn: unknown type")
+)
+
+// Storage is the storage class of a type, which defines how the values
+// of the type are stored. It takes the values of the Storage constants.
+type Storage int
+
+var storageNames = [...]string{
+	"nobytes",
+	"byte",
+	"word",
+	"dword",
+	"qword",
+	"string",
+	"blob",
+	"container",
+}
+
+// String returns the name of the storage class, such as "dword".
+func (s Storage) String() string {
+	if s&^StorageMask == 0 {
+		return storageNames[s>>5]
+	}
+
+	return "Storage(" + strconv.Itoa(int(s)) + ")"
+}
+
+// FixedSize returns the size of the values of the storage class,
+// or false if the values are stored with their size.
+func (s Storage) FixedSize() (int, bool) {
+	switch s {
+	case StorageNoBytes:
+		return 0, true
+	case StorageByte:
+		return 1, true
+	case StorageWord:
+		return 2, true
+	case StorageDWord:
+		return 4, true
+	case StorageQWord:
+		return 8, true
+	}
+
+	return 0, false
+}
+
+// ParseStorage returns the storage class with the name returned
+// by Storage.String.
+func ParseStorage(name string) (Storage, error) {
+	for i, n := range storageNames {
+		if n == name {
+			return Storage(i << 5), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w %q", ErrUnknownStorage, name)
+}
+
+// typeNames are the names of the types defined by the format.
+var typeNames = map[Type]string{
+	Null:            "null",
+	True:            "true",
+	False:           "false",
+	Uint8Type:       "uint8",
+	Int8Type:        "int8",
+	Uint16Type:      "uint16",
+	Int16Type:       "int16",
+	Uint32Type:      "uint32",
+	Int32Type:       "int32",
+	Float32Type:     "float32",
+	Uint64Type:      "uint64",
+	Int64Type:       "int64",
+	Float64Type:     "float64",
+	CurrencyType:    "currency",
+	StringType:      "string",
+	DateTimeType:    "datetime",
+	DateType:        "date",
+	TimeType:        "time",
+	DecimalType:     "decimal",
+	CurrencyStrType: "currencystr",
+	SingleStrType:   "singlestr",
+	DoubleStrType:   "doublestr",
+	HTML:            "html",
+	XML:             "xml",
+	JSON:            "json",
+	JavaScript:      "javascript",
+	CSS:             "css",
+	BlobType:        "blob",
+	JPEG:            "jpeg",
+	GIF:             "gif",
+	PNG:             "png",
+	BMP:             "bmp",
+	ListType:        "list",
+	MapType:         "map",
+	ObjectType:      "object",
+}
+
+var typesByName = func() map[string]Type {
+	m := make(map[string]Type, len(typeNames))
+	for t, name := range typeNames {
+		m[name] = t
+	}
+
+	return m
+}()
+
+// String returns the name of the type, such as "object". The types
+// not defined by the format are formatted as their hexadecimal values,
+// such as "0xb009".
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+
+	return "0x" + strconv.FormatUint(uint64(t), 16)
+}
+
+// ParseType returns the type with the name returned by Type.String.
+// It also accepts the hexadecimal and decimal values of the types.
+func ParseType(name string) (Type, error) {
+	if t, ok := typesByName[name]; ok {
+		return t, nil
+	}
+
+	n, err := strconv.ParseUint(name, 0, 16)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrUnknownType, name)
+	}
+
+	t := Type(n)
+	if !t.IsValid() {
+		return 0, fmt.Errorf("%w %q", ErrUnknownType, name)
+	}
+
+	return t, nil
+}
+
+// IsValid reports whether the type can be encoded: a single byte without
+// the StorageHasMore bit, or two bytes with it.
+func (t Type) IsValid() bool {
+	if t <= 0xFF {
+		return t >= 0 && t&StorageHasMore == 0
+	}
+
+	return t <= 0xFFFF && (t>>8)&StorageHasMore != 0
+}
+
+// Storage returns the storage class of the type.
+func (t Type) Storage() Storage {
+	if t > 0xFF {
+		return Storage(t>>8) & StorageMask
+	}
+
+	return Storage(t) & StorageMask
+}
+
+// IsContainer reports whether the type is a list, a map, an object
+// or another container type.
+func (t Type) IsContainer() bool {
+	return t.Storage() == StorageContainer
+}
+
+// FixedSize returns the size of the values of the type,
+// or false if the values are stored with their size.
+func (t Type) FixedSize() (int, bool) {
+	return t.Storage().FixedSize()
+}
+
+// IsUserType reports whether the type isn't one of the types
+// defined by the format.
+func (t Type) IsUserType() bool {
+	_, ok := typeNames[t]
+
+	return !ok
+}
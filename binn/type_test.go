@@ -0,0 +1,77 @@
+package binn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestType(t *testing.T) {
+	tests := []struct {
+		typ       binn.Type
+		name      string
+		storage   binn.Storage
+		size      int
+		fixed     bool
+		container bool
+		user      bool
+	}{
+		{binn.Null, "null", binn.StorageNoBytes, 0, true, false, false},
+		{binn.Int16Type, "int16", binn.StorageWord, 2, true, false, false},
+		{binn.Float64Type, "float64", binn.StorageQWord, 8, true, false, false},
+		{binn.DateType, "date", binn.StorageString, 0, false, false, false},
+		{binn.HTML, "html", binn.StorageString, 0, false, false, false},
+		{binn.PNG, "png", binn.StorageBlob, 0, false, false, false},
+		{binn.ObjectType, "object", binn.StorageContainer, 0, false, true, false},
+		{0x64, "0x64", binn.StorageDWord, 4, true, false, true},
+		{0xB009, "0xb009", binn.StorageString, 0, false, false, true},
+		{0xE3, "0xe3", binn.StorageContainer, 0, false, true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.name, test.typ.String())
+			assert.Equal(t, test.storage, test.typ.Storage())
+			size, fixed := test.typ.FixedSize()
+			assert.Equal(t, test.size, size)
+			assert.Equal(t, test.fixed, fixed)
+			assert.Equal(t, test.container, test.typ.IsContainer())
+			assert.Equal(t, test.user, test.typ.IsUserType())
+
+			typ, err := binn.ParseType(test.name)
+			require.NoError(t, err)
+			assert.Equal(t, test.typ, typ)
+		})
+	}
+}
+
+func TestType_Format(t *testing.T) {
+	assert.Equal(t, "expected object", fmt.Sprintf("expected %v", binn.Type(binn.ObjectType)))
+}
+
+func TestParseType_Errors(t *testing.T) {
+	for _, name := range []string{"", "Object", "unknown", "0x10", "0x1ff", "0x10000", "-1"} {
+		_, err := binn.ParseType(name)
+		assert.ErrorIs(t, err, binn.ErrUnknownType, name)
+	}
+}
+
+func TestStorage(t *testing.T) {
+	for _, s := range []binn.Storage{
+		binn.StorageNoBytes, binn.StorageByte, binn.StorageWord, binn.StorageDWord,
+		binn.StorageQWord, binn.StorageString, binn.StorageBlob, binn.StorageContainer,
+	} {
+		parsed, err := binn.ParseStorage(s.String())
+		require.NoError(t, err)
+		assert.Equal(t, s, parsed)
+	}
+
+	assert.Equal(t, "dword", binn.Storage(binn.StorageDWord).String())
+	assert.Equal(t, "Storage(1)", binn.Storage(1).String())
+
+	_, err := binn.ParseStorage("bytes")
+	assert.ErrorIs(t, err, binn.ErrUnknownStorage)
+}
@@ -0,0 +1,83 @@
+package binnhttp
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// negotiate returns the content type preferred by the Accept headers,
+// or "" if both are unacceptable. The explicitly accepted types win over
+// the wildcards matching them, and BINN wins the ties between the explicit
+// types. The wildcards, like a missing header, select JSON, so the clients
+// not aware of BINN get JSON.
+func negotiate(accept []string) string {
+	if strings.TrimSpace(strings.Join(accept, "")) == "" {
+		return JSONContentType
+	}
+
+	binnQ, binnRank := quality(accept, ContentType)
+	jsonQ, jsonRank := quality(accept, JSONContentType)
+
+	switch {
+	case binnQ == 0 && jsonQ == 0:
+		return ""
+	case binnQ != jsonQ:
+		if binnQ > jsonQ {
+			return ContentType
+		}
+		return JSONContentType
+	case binnRank > jsonRank, binnRank == jsonRank && binnRank == explicit:
+		return ContentType
+	}
+
+	return JSONContentType
+}
+
+// The specificity of the media ranges.
+const (
+	anyType = iota
+	anySubtype
+	explicit
+)
+
+// quality returns the quality of the most specific media range of the
+// Accept headers matching the content type, and its specificity.
+func quality(accept []string, contentType string) (float64, int) {
+	q, rank := 0.0, -1
+
+	for _, header := range accept {
+		for _, mediaRange := range strings.Split(header, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+
+			var r int
+			switch {
+			case mediaType == contentType:
+				r = explicit
+			case mediaType == "application/*":
+				r = anySubtype
+			case mediaType == "*/*":
+				r = anyType
+			default:
+				continue
+			}
+
+			if r <= rank {
+				continue
+			}
+
+			rank = r
+			q = 1
+			if s, ok := params["q"]; ok {
+				if v, err := strconv.ParseFloat(s, 64); err == nil && v >= 0 && v <= 1 {
+					q = v
+				}
+			}
+		}
+	}
+
+	return q, rank
+}
@@ -0,0 +1,213 @@
+// Package binnhttp binds HTTP request bodies and renders responses
+// in BINN, or in JSON for the clients that ask for it.
+//
+// Handlers decode requests with Bind and write responses with Respond,
+// which uses the format negotiated from the Accept header by Negotiate:
+//
+//	http.Handle("/items", binnhttp.Negotiate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		var item Item
+//		if err := binnhttp.Bind(r, &item); err != nil {
+//			http.Error(w, err.Error(), binnhttp.Status(err))
+//			return
+//		}
+//		binnhttp.Respond(w, r, http.StatusOK, item)
+//	})))
+package binnhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+)
+
+const (
+	// ContentType is the media type of BINN.
+	ContentType = "application/x-binn"
+	// JSONContentType is the media type of JSON.
+	JSONContentType = "application/json"
+)
+
+// DefaultMaxBodySize is the maximum size of the request bodies read by Bind.
+const DefaultMaxBodySize = 10 << 20
+
+var (
+	// ErrBodyTooLarge is returned by Bind for bodies larger than the limit.
+	ErrBodyTooLarge = errors.New("binnhttp: request body too large")
+	// ErrUnsupportedMediaType is returned by Bind for bodies
+	// neither in BINN nor in JSON.
+	ErrUnsupportedMediaType = errors.New("binnhttp: unsupported media type")
+)
+
+// Bind decodes the request body into v, like BindLimit with
+// the DefaultMaxBodySize limit.
+func Bind(r *http.Request, v interface{}) error {
+	return BindLimit(r, v, DefaultMaxBodySize)
+}
+
+// BindLimit decodes the request body into v. The body is decoded as
+// JSON if its Content-Type is application/json, and as BINN if it's
+// application/x-binn or not set. Other content types are rejected
+// with ErrUnsupportedMediaType.
+//
+// The body is decoded as it's read, reading more than limit bytes
+// fails with ErrBodyTooLarge. An empty body returns io.EOF.
+func BindLimit(r *http.Request, v interface{}, limit int64) error {
+	contentType := ContentType
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return ErrUnsupportedMediaType
+		}
+		contentType = mediaType
+	}
+
+	if r.Body == nil {
+		return io.EOF
+	}
+
+	body := &limitedReader{r.Body, limit}
+
+	switch contentType {
+	case ContentType:
+		return decode.NewDecoder(body).Decode(v)
+	case JSONContentType:
+		return json.NewDecoder(body).Decode(v)
+	}
+
+	return ErrUnsupportedMediaType
+}
+
+// limitedReader reads at most n bytes, and fails with ErrBodyTooLarge
+// when more are requested.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// Tell the end of the body from a body over the limit.
+		var b [1]byte
+		if n, _ := l.r.Read(b[:]); n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
+
+// Status returns the HTTP status code for an error returned by Bind:
+// 413 for ErrBodyTooLarge, 415 for ErrUnsupportedMediaType
+// and 400 for the other errors.
+func Status(err error) int {
+	switch {
+	case errors.Is(err, ErrBodyTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	}
+
+	return http.StatusBadRequest
+}
+
+// Write writes v encoded in BINN as the response with the status code.
+//
+// The headers are written with the first bytes of v, so if v can't be
+// encoded, nothing is written and the handler can still respond
+// with an error.
+func Write(w http.ResponseWriter, status int, v interface{}) error {
+	return encode.NewEncoder(&responseWriter{w: w, contentType: ContentType, status: status}).Encode(v)
+}
+
+// WriteJSON is the JSON counterpart of Write.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	rw := &responseWriter{w: w, contentType: JSONContentType, status: status}
+	_, err = rw.Write(append(b, '\n'))
+
+	return err
+}
+
+// responseWriter writes the headers of a response before its body.
+type responseWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	status      int
+	wroteHeader bool
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.w.Header().Set("Content-Type", rw.contentType)
+		rw.w.WriteHeader(rw.status)
+	}
+
+	return rw.w.Write(p)
+}
+
+// Respond writes v as the response with the status code, in the format
+// negotiated for the request: with WriteJSON if it's JSON, with Write
+// otherwise.
+func Respond(w http.ResponseWriter, r *http.Request, status int, v interface{}) error {
+	if Negotiated(r) == JSONContentType {
+		return WriteJSON(w, status, v)
+	}
+
+	return Write(w, status, v)
+}
+
+type contextKey struct{}
+
+// Negotiate returns a handler choosing between BINN and JSON by
+// the Accept header of the requests before calling next. The choice
+// is available to next with Negotiated. Requests accepting neither
+// are answered with 406 Not Acceptable.
+func Negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		contentType := negotiate(r.Header.Values("Accept"))
+		if contentType == "" {
+			http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, contentType)))
+	})
+}
+
+// Negotiated returns the content type negotiated for the request,
+// ContentType or JSONContentType. Without Negotiate, it's negotiated
+// from the Accept header of the request, and is ContentType
+// if the request accepts neither.
+func Negotiated(r *http.Request) string {
+	if contentType, ok := r.Context().Value(contextKey{}).(string); ok {
+		return contentType
+	}
+
+	if contentType := negotiate(r.Header.Values("Accept")); contentType != "" {
+		return contentType
+	}
+
+	return ContentType
+}
@@ -0,0 +1,220 @@
+package binnhttp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/et-nik/binngo/binnhttp"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	Name  string `binn:"name" json:"name"`
+	Count int    `binn:"count" json:"count"`
+}
+
+func binnBody(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	b, err := encode.Marshal(v)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestBind(t *testing.T) {
+	b := binnBody(t, item{"a", 1})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+	}{
+		{"binn", binnhttp.ContentType, b},
+		{"no content type", "", b},
+		{"json", "application/json; charset=utf-8", []byte(`{"name":"a","count":1}`)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(test.body))
+			if test.contentType != "" {
+				r.Header.Set("Content-Type", test.contentType)
+			}
+			var v item
+
+			err := binnhttp.Bind(r, &v)
+
+			require.NoError(t, err)
+			assert.Equal(t, item{"a", 1}, v)
+		})
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	b := binnBody(t, item{"a", 1})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		limit       int64
+		err         error
+		status      int
+	}{
+		{"too large", binnhttp.ContentType, b, int64(len(b) - 1), binnhttp.ErrBodyTooLarge, http.StatusRequestEntityTooLarge},
+		{"too large json", binnhttp.JSONContentType, []byte(`{"name":"a"}`), 4, binnhttp.ErrBodyTooLarge, http.StatusRequestEntityTooLarge},
+		{"unsupported", "text/plain", b, 100, binnhttp.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{"malformed content type", "application/", b, 100, binnhttp.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{"empty", binnhttp.ContentType, nil, 100, io.EOF, http.StatusBadRequest},
+		{"truncated", binnhttp.ContentType, b[:len(b)-1], 100, io.ErrUnexpectedEOF, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(test.body))
+			r.Header.Set("Content-Type", test.contentType)
+			var v item
+
+			err := binnhttp.BindLimit(r, &v, test.limit)
+
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.status, binnhttp.Status(err))
+		})
+	}
+}
+
+func TestBindLimitExact(t *testing.T) {
+	b := binnBody(t, item{"a", 1})
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b))
+	var v item
+
+	err := binnhttp.BindLimit(r, &v, int64(len(b)))
+
+	require.NoError(t, err)
+	assert.Equal(t, item{"a", 1}, v)
+}
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := binnhttp.Write(w, http.StatusCreated, item{"a", 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, binnhttp.ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, binnBody(t, item{"a", 1}), w.Body.Bytes())
+}
+
+func TestWriteError(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := binnhttp.Write(w, http.StatusCreated, make(chan int))
+
+	require.Error(t, err)
+	assert.False(t, w.Flushed)
+	assert.Empty(t, w.Header().Get("Content-Type"))
+	assert.Zero(t, w.Body.Len())
+
+	// The handler can still respond with an error.
+	http.Error(w, "internal error", http.StatusInternalServerError)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept   []string
+		expected string
+	}{
+		{nil, binnhttp.JSONContentType},
+		{[]string{""}, binnhttp.JSONContentType},
+		{[]string{"*/*"}, binnhttp.JSONContentType},
+		{[]string{"application/*"}, binnhttp.JSONContentType},
+		{[]string{"application/x-binn"}, binnhttp.ContentType},
+		{[]string{"application/json"}, binnhttp.JSONContentType},
+		{[]string{"application/json, application/x-binn"}, binnhttp.ContentType},
+		{[]string{"application/x-binn;q=0.5, application/json"}, binnhttp.JSONContentType},
+		{[]string{"application/json;q=0.5, application/x-binn;q=0.9"}, binnhttp.ContentType},
+		{[]string{"application/x-binn, */*;q=0.1"}, binnhttp.ContentType},
+		{[]string{"text/html", "application/x-binn"}, binnhttp.ContentType},
+		{[]string{"*/*, application/x-binn"}, binnhttp.ContentType},
+		{[]string{"*/*, application/json;q=0"}, binnhttp.ContentType},
+		{[]string{"text/html"}, ""},
+		{[]string{"application/x-binn;q=0, application/json;q=0"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.accept, " | "), func(t *testing.T) {
+			var negotiated string
+			h := binnhttp.Negotiate(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				negotiated = binnhttp.Negotiated(r)
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, a := range test.accept {
+				r.Header.Add("Accept", a)
+			}
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, test.expected, negotiated)
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			if test.expected == "" {
+				assert.Equal(t, http.StatusNotAcceptable, w.Code)
+			}
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	h := binnhttp.Negotiate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v item
+		if err := binnhttp.Bind(r, &v); err != nil {
+			http.Error(w, err.Error(), binnhttp.Status(err))
+			return
+		}
+		v.Count++
+
+		_ = binnhttp.Respond(w, r, http.StatusOK, v)
+	}))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	post := func(accept string, body []byte) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", binnhttp.ContentType)
+		req.Header.Set("Accept", accept)
+
+		resp, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+
+		return resp
+	}
+
+	resp := post(binnhttp.ContentType, binnBody(t, item{"a", 1}))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, binnhttp.ContentType, resp.Header.Get("Content-Type"))
+	var v item
+	require.NoError(t, decode.NewDecoder(resp.Body).Decode(&v))
+	assert.Equal(t, item{"a", 2}, v)
+
+	resp = post(binnhttp.JSONContentType, binnBody(t, item{"b", 5}))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, binnhttp.JSONContentType, resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&v))
+	assert.Equal(t, item{"b", 6}, v)
+
+	resp = post(binnhttp.ContentType, []byte{0xE2})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
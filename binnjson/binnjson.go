@@ -0,0 +1,91 @@
+// Package binnjson converts BINN items to JSON values and back.
+//
+// Both directions are streaming: ToJSON converts a stream of BINN items
+// into JSON values separated by newlines, and FromJSON converts a stream
+// of JSON values into BINN items written back to back. The conversion
+// works on the BINN framing directly, values are never decoded into
+// interface values.
+//
+// The BINN items map to JSON as follows:
+//
+//	null, true, false     null, true, false
+//	integers, floats      numbers, floats always have a fraction or an exponent
+//	string                string
+//	list                  array
+//	object                object, keys starting with $ get another $ prepended
+//	map                   {"$map": {"<int32 key>": value, ...}}
+//	blob                  {"$blob": "<base64>"}
+//	datetime              {"$datetime": "<text>"}
+//	date                  {"$date": "<text>"}
+//	time                  {"$time": "<text>"}
+//	decimal               {"$decimal": "<text>"}
+//	currency string       {"$currencystr": "<text>"}
+//	single string         {"$singlestr": "<text>"}
+//	double string         {"$doublestr": "<text>"}
+//	currency              {"$currency": <int64>}
+//	other types           {"$type": <type>, "$data": "<base64 of the value>"}
+//
+// Numbers keep their values but not their storage types: FromJSON stores
+// integers in the smallest type that holds them, like Marshal does.
+package binnjson
+
+import (
+	"errors"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+var (
+	// ErrInvalidEnvelope is returned by FromJSON for malformed envelope objects.
+	ErrInvalidEnvelope = errors.New("binnjson: invalid envelope")
+	// ErrUnsupportedValue is returned by ToJSON for floats that
+	// can't be represented in JSON, such as NaN and infinities.
+	ErrUnsupportedValue = errors.New("binnjson: unsupported value")
+	// ErrTooDeep is returned when containers are nested deeper than MaxDepth.
+	ErrTooDeep = errors.New("binnjson: exceeded max depth")
+)
+
+// MaxDepth is the maximum nesting depth of containers.
+const MaxDepth = 10000
+
+// Options control FromJSON.
+type Options struct {
+	// Float32 stores the numbers with a fraction or an exponent
+	// as float32 instead of float64.
+	Float32 bool
+
+	// NoEnvelopes reads the envelope objects as plain objects,
+	// and keeps the object keys starting with $ unchanged.
+	NoEnvelopes bool
+}
+
+const (
+	mapEnvelope  = "$map"
+	typeEnvelope = "$type"
+	dataEnvelope = "$data"
+	blobEnvelope = "$blob"
+	currencyKey  = "$currency"
+)
+
+// stringEnvelopes are the envelopes of the string storage types,
+// named after the types.
+var stringEnvelopes = func() map[binn.Type]string {
+	m := map[binn.Type]string{}
+	for _, t := range []binn.Type{
+		binn.DateTimeType, binn.DateType, binn.TimeType, binn.DecimalType,
+		binn.CurrencyStrType, binn.SingleStrType, binn.DoubleStrType,
+	} {
+		m[t] = "$" + t.String()
+	}
+
+	return m
+}()
+
+var envelopeStringTypes = func() map[string]binn.Type {
+	m := make(map[string]binn.Type, len(stringEnvelopes))
+	for t, name := range stringEnvelopes {
+		m[name] = t
+	}
+
+	return m
+}()
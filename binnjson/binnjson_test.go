@@ -0,0 +1,188 @@
+package binnjson_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/binnjson"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toJSON(t *testing.T, b []byte) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	require.NoError(t, binnjson.ToJSON(&out, bytes.NewReader(b)))
+
+	return out.String()
+}
+
+func fromJSON(t *testing.T, s string, opts *binnjson.Options) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	require.NoError(t, binnjson.FromJSON(&out, strings.NewReader(s), opts))
+
+	return out.Bytes()
+}
+
+func TestToJSON(t *testing.T) {
+	type inner struct {
+		Flag bool
+		Nil  *int
+	}
+
+	b, err := encode.Marshal(struct {
+		Name  string
+		Ints  []int
+		Pi    float64
+		One   float32
+		Map   map[int]string
+		Inner inner
+	}{
+		Name:  "line\n\"quoted\"",
+		Ints:  []int{1, -300, 70000},
+		Pi:    3.5,
+		One:   1,
+		Map:   map[int]string{-7: "x"},
+		Inner: inner{Flag: true},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		`{"Name":"line\n\"quoted\"","Ints":[1,-300,70000],"Pi":3.5,"One":1.0,`+
+			`"Map":{"$map":{"-7":"x"}},"Inner":{"Flag":true,"Nil":null}}`+"\n",
+		toJSON(t, b),
+	)
+}
+
+func TestToJSON_Stream(t *testing.T) {
+	b := []byte{
+		binn.Uint8Type, 0x01,
+		binn.ListType, 0x05, 0x01, binn.True, // the size counts the padding byte
+		binn.Null,
+		binn.StringType, 0x00, 0x00,
+	}
+
+	assert.Equal(t, "1\n[true]\n\"\"\n", toJSON(t, b))
+}
+
+func TestToJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+		err  error
+	}{
+		{"truncated", []byte{binn.ListType, 0x05, 0x02, binn.True}, decode.ErrIncompleteRead},
+		{"overrun", []byte{binn.ListType, 0x03, 0x01, binn.True}, decode.ErrInvalidItem},
+		{"not terminated", []byte{binn.StringType, 0x01, 'a', 'b'}, decode.ErrInvalidItem},
+		{"unknown container", []byte{0xE5, 0x03, 0x00}, decode.ErrUnknownType},
+		{"nan", []byte{binn.Float32Type, 0x7F, 0xC0, 0x00, 0x00}, binnjson.ErrUnsupportedValue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := binnjson.ToJSON(&bytes.Buffer{}, bytes.NewReader(test.b))
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	b := fromJSON(t, `{"a": [1, -2, 300, 1.5, 18446744073709551615], "b": null}`, nil)
+
+	assert.Equal(t, []byte{
+		binn.ObjectType, 0x24, 0x02,
+		0x01, 'a',
+		binn.ListType, 0x1C, 0x05,
+		binn.Uint8Type, 0x01,
+		binn.Int8Type, 0xFE,
+		binn.Uint16Type, 0x01, 0x2C,
+		binn.Float64Type, 0x3F, 0xF8, 0, 0, 0, 0, 0, 0,
+		binn.Uint64Type, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x01, 'b',
+		binn.Null,
+	}, b)
+
+	assert.Equal(t,
+		[]byte{binn.Float32Type, 0x3F, 0xC0, 0x00, 0x00},
+		fromJSON(t, `1.5`, &binnjson.Options{Float32: true}),
+	)
+}
+
+func TestFromJSON_LargeContainer(t *testing.T) {
+	s := "[" + strings.Repeat(`"abcdefgh",`, 199) + `"abcdefgh"]`
+
+	var v []string
+	require.NoError(t, decode.Unmarshal(fromJSON(t, s, nil), &v))
+	assert.Len(t, v, 200)
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []string{
+		`{"$blob":"AAEC/w=="}`,
+		`{"$datetime":"2021-08-01 12:00:00"}`,
+		`{"$date":"2021-08-01"}`,
+		`{"$time":"12:00:00"}`,
+		`{"$decimal":"12.345"}`,
+		`{"$currency":-12345}`,
+		`{"$map":{"1":"a","-2147483648":[true,false]}}`,
+		`{"$$key":{"$$$":1,"plain":"text"}}`,
+		`{"$type":3}`,
+		`{"$type":168,"$data":"aGk="}`,
+		`{"$type":34,"$data":"/w=="}`,
+		`{"$type":193,"$data":""}`,
+		`[{"$map":{}},{},[],-9223372036854775808,1e+100]`,
+		`"\u0000\u001f\u2028<>&"`,
+	}
+
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			assert.Equal(t, test+"\n", toJSON(t, fromJSON(t, test, nil)))
+		})
+	}
+}
+
+func TestFromJSON_NoEnvelopes(t *testing.T) {
+	b := fromJSON(t, `{"$blob":"AA=="}`, &binnjson.Options{NoEnvelopes: true})
+
+	var v map[string]string
+	require.NoError(t, decode.Unmarshal(b, &v))
+	assert.Equal(t, map[string]string{"$blob": "AA=="}, v)
+}
+
+func TestFromJSON_Errors(t *testing.T) {
+	tests := []struct {
+		json string
+		err  error
+	}{
+		{`{"$blob":"AA==","extra":1}`, binnjson.ErrInvalidEnvelope},
+		{`{"$blob":1}`, binnjson.ErrInvalidEnvelope},
+		{`{"$map":[]}`, binnjson.ErrInvalidEnvelope},
+		{`{"$map":{"x":1}}`, binnjson.ErrInvalidEnvelope},
+		{`{"$map":{"2147483648":1}}`, binnjson.ErrInvalidEnvelope},
+		{`{"$type":34,"$data":""}`, binnjson.ErrInvalidEnvelope},
+		{`{"$type":16}`, binnjson.ErrInvalidEnvelope},
+		{`{"$type":224}`, binnjson.ErrInvalidEnvelope},
+		{`{"$type":34,"$blob":""}`, binnjson.ErrInvalidEnvelope},
+		{`{"$currency":"1"}`, binnjson.ErrInvalidEnvelope},
+		{`{"$date":1}`, binnjson.ErrInvalidEnvelope},
+		{`{"` + strings.Repeat("k", 256) + `":1}`, encode.ErrKeyTooLong},
+		{`[1,`, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.json, func(t *testing.T) {
+			err := binnjson.FromJSON(&bytes.Buffer{}, strings.NewReader(test.json), nil)
+			assert.Error(t, err)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+}
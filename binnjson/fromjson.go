@@ -0,0 +1,436 @@
+package binnjson
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/encode"
+)
+
+// FromJSON reads JSON values from r until the end of the input and
+// writes each of them to w as a BINN item. A nil opts is the same as
+// the zero Options.
+func FromJSON(w io.Writer, r io.Reader, opts *Options) error {
+	f := &fromJSON{dec: json.NewDecoder(r)}
+	if opts != nil {
+		f.opts = *opts
+	}
+	f.dec.UseNumber()
+
+	for {
+		tok, err := f.dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		f.buf = f.buf[:0]
+		if err := f.value(tok, 0); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(f.buf); err != nil {
+			return err
+		}
+	}
+}
+
+type fromJSON struct {
+	dec  *json.Decoder
+	opts Options
+	buf  []byte
+}
+
+func (f *fromJSON) value(tok json.Token, depth int) error {
+	switch v := tok.(type) {
+	case nil:
+		f.buf = encode.AppendNull(f.buf)
+	case bool:
+		f.buf = encode.AppendBool(f.buf, v)
+	case string:
+		f.buf = encode.AppendString(f.buf, v)
+	case json.Number:
+		return f.number(v)
+	case json.Delim:
+		if depth >= MaxDepth {
+			return ErrTooDeep
+		}
+
+		if v == '[' {
+			return f.list(depth)
+		}
+
+		return f.object(depth)
+	}
+
+	return nil
+}
+
+func (f *fromJSON) number(n json.Number) error {
+	s := string(n)
+
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			f.buf = encode.AppendInt(f.buf, i)
+			return nil
+		}
+
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			f.buf = encode.AppendUint(f.buf, u)
+			return nil
+		}
+	}
+
+	if f.opts.Float32 {
+		v, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return err
+		}
+		f.buf = encode.AppendFloat32(f.buf, float32(v))
+
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	f.buf = encode.AppendFloat64(f.buf, v)
+
+	return nil
+}
+
+// next returns the next token, which must exist.
+func (f *fromJSON) next() (json.Token, error) {
+	tok, err := f.dec.Token()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return tok, err
+}
+
+func (f *fromJSON) list(depth int) error {
+	start := f.begin(binn.ListType)
+
+	count := 0
+	for f.dec.More() {
+		tok, err := f.next()
+		if err != nil {
+			return err
+		}
+
+		if err := f.value(tok, depth+1); err != nil {
+			return err
+		}
+		count++
+	}
+
+	// The closing bracket.
+	if _, err := f.next(); err != nil {
+		return err
+	}
+
+	f.end(start, count)
+
+	return nil
+}
+
+func (f *fromJSON) object(depth int) error {
+	start := -1
+
+	count := 0
+	for f.dec.More() {
+		tok, err := f.next()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+
+		if count == 0 && !f.opts.NoEnvelopes && isEnvelope(key) {
+			return f.envelope(key, depth)
+		}
+
+		if start < 0 {
+			start = f.begin(binn.ObjectType)
+		}
+
+		if !f.opts.NoEnvelopes && strings.HasPrefix(key, "$$") {
+			key = key[1:]
+		}
+
+		if len(key) > binn.MaxKeySize {
+			return fmt.Errorf("binnjson: %w: %.16q...", encode.ErrKeyTooLong, key)
+		}
+
+		f.buf = encode.AppendObjectKey(f.buf, key)
+
+		tok, err = f.next()
+		if err != nil {
+			return err
+		}
+
+		if err := f.value(tok, depth+1); err != nil {
+			return err
+		}
+		count++
+	}
+
+	if _, err := f.next(); err != nil {
+		return err
+	}
+
+	if start < 0 {
+		start = f.begin(binn.ObjectType)
+	}
+	f.end(start, count)
+
+	return nil
+}
+
+func isEnvelope(key string) bool {
+	switch key {
+	case mapEnvelope, typeEnvelope, blobEnvelope, currencyKey:
+		return true
+	}
+
+	_, ok := envelopeStringTypes[key]
+
+	return ok
+}
+
+// envelope reads the value of the envelope object with the key
+// and the closing brace.
+func (f *fromJSON) envelope(key string, depth int) error {
+	var err error
+
+	switch key {
+	case mapEnvelope:
+		err = f.intMap(depth)
+	case typeEnvelope:
+		err = f.typed()
+	case blobEnvelope:
+		var b []byte
+		if b, err = f.base64(); err == nil {
+			f.buf = encode.AppendBlob(f.buf, b)
+		}
+	case currencyKey:
+		var n int64
+		if n, err = f.int(64); err == nil {
+			var b [8]byte
+			binary.BigEndian.PutUint64(b[:], uint64(n))
+			f.buf = append(append(f.buf, binn.CurrencyType), b[:]...)
+		}
+	default:
+		var s string
+		if s, err = f.string(); err == nil {
+			f.buf = appendString(f.buf, envelopeStringTypes[key], []byte(s))
+		}
+	}
+
+	if err != nil {
+		if !errors.Is(err, ErrInvalidEnvelope) {
+			err = fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+		}
+
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	if tok, err := f.next(); err != nil {
+		return err
+	} else if tok != json.Delim('}') {
+		return fmt.Errorf("%w %s: unexpected key %v", ErrInvalidEnvelope, key, tok)
+	}
+
+	return nil
+}
+
+func (f *fromJSON) intMap(depth int) error {
+	if tok, err := f.next(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("%w: expected an object, got %v", ErrInvalidEnvelope, tok)
+	}
+
+	start := f.begin(binn.MapType)
+
+	count := 0
+	for f.dec.More() {
+		tok, err := f.next()
+		if err != nil {
+			return err
+		}
+
+		key, err := strconv.ParseInt(tok.(string), 10, 32)
+		if err != nil {
+			return err
+		}
+
+		f.buf = encode.AppendMapKey(f.buf, key)
+
+		if tok, err = f.next(); err != nil {
+			return err
+		}
+
+		if err := f.value(tok, depth+1); err != nil {
+			return err
+		}
+		count++
+	}
+
+	if _, err := f.next(); err != nil {
+		return err
+	}
+
+	f.end(start, count)
+
+	return nil
+}
+
+// typed reads the type and the data of an item of any type,
+// except for containers.
+func (f *fromJSON) typed() error {
+	n, err := f.int(16)
+	if err != nil {
+		return err
+	}
+
+	btype := binn.Type(n)
+	if !btype.IsValid() {
+		return fmt.Errorf("%w: invalid type %d", ErrInvalidEnvelope, n)
+	}
+
+	var data []byte
+
+	if f.dec.More() {
+		tok, err := f.next()
+		if err != nil {
+			return err
+		}
+		if tok != dataEnvelope {
+			return fmt.Errorf("%w: unexpected key %v", ErrInvalidEnvelope, tok)
+		}
+
+		if data, err = f.base64(); err != nil {
+			return err
+		}
+	}
+
+	switch btype.Storage() {
+	case binn.StorageNoBytes, binn.StorageByte, binn.StorageWord, binn.StorageDWord, binn.StorageQWord:
+		if n, _ := btype.FixedSize(); len(data) != n {
+			return fmt.Errorf("%w: type %s can't have %d bytes of data", ErrInvalidEnvelope, btype, len(data))
+		}
+		f.buf = append(appendType(f.buf, btype), data...)
+	case binn.StorageString:
+		f.buf = appendString(f.buf, btype, data)
+	case binn.StorageBlob:
+		f.buf = appendSize(appendType(f.buf, btype), len(data))
+		f.buf = append(f.buf, data...)
+	default:
+		return fmt.Errorf("%w: containers can't be typed", ErrInvalidEnvelope)
+	}
+
+	return nil
+}
+
+func (f *fromJSON) string() (string, error) {
+	tok, err := f.next()
+	if err != nil {
+		return "", err
+	}
+
+	s, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: expected a string, got %v", ErrInvalidEnvelope, tok)
+	}
+
+	return s, nil
+}
+
+func (f *fromJSON) base64() ([]byte, error) {
+	s, err := f.string()
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(s)
+}
+
+func (f *fromJSON) int(bitSize int) (int64, error) {
+	tok, err := f.next()
+	if err != nil {
+		return 0, err
+	}
+
+	n, ok := tok.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%w: expected a number, got %v", ErrInvalidEnvelope, tok)
+	}
+
+	if bitSize == 16 {
+		u, err := strconv.ParseUint(string(n), 10, 16)
+		return int64(u), err
+	}
+
+	return strconv.ParseInt(string(n), 10, bitSize)
+}
+
+// begin appends the type and a one byte size of a container with
+// a count that isn't known yet.
+func (f *fromJSON) begin(containerType uint8) int {
+	start := len(f.buf)
+	f.buf = append(f.buf, containerType, 0)
+
+	return start
+}
+
+// end inserts the count after the size of the container
+// and writes the size.
+func (f *fromJSON) end(start, count int) {
+	size := appendSize(nil, count)
+	items := start + 2
+
+	f.buf = append(f.buf, size...)
+	copy(f.buf[items+len(size):], f.buf[items:len(f.buf)-len(size)])
+	copy(f.buf[items:], size)
+
+	f.buf = encode.EndContainer(f.buf, start)
+}
+
+func appendString(dst []byte, btype binn.Type, s []byte) []byte {
+	dst = appendSize(appendType(dst, btype), len(s))
+	dst = append(dst, s...)
+
+	return append(dst, 0)
+}
+
+// appendType appends the one or two bytes of the type.
+func appendType(dst []byte, t binn.Type) []byte {
+	if t > 0xFF {
+		return append(dst, byte(t>>8), byte(t))
+	}
+
+	return append(dst, byte(t))
+}
+
+func appendSize(dst []byte, size int) []byte {
+	if size <= math.MaxInt8 {
+		return append(dst, byte(size))
+	}
+
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(size)|0x80000000)
+
+	return append(dst, b[:]...)
+}
@@ -0,0 +1,427 @@
+package binnjson
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+)
+
+// ToJSON reads BINN items from r until the end of the input and writes
+// each of them to w as a JSON value followed by a newline.
+func ToJSON(w io.Writer, r io.Reader) error {
+	t := &toJSON{
+		r: bufio.NewReader(r),
+		w: bufio.NewWriter(w),
+	}
+
+	for {
+		if _, err := t.r.Peek(1); err == io.EOF {
+			break
+		}
+
+		if err := t.item(0); err != nil {
+			return err
+		}
+
+		t.w.WriteByte('\n')
+	}
+
+	return t.w.Flush()
+}
+
+type toJSON struct {
+	r *bufio.Reader
+	w *bufio.Writer
+
+	// off is the number of bytes read so far.
+	off int
+	buf []byte
+}
+
+// read returns the next n bytes of the input, valid until the next read.
+func (t *toJSON) read(n int) ([]byte, error) {
+	if cap(t.buf) < n {
+		t.buf = make([]byte, 0, n)
+	}
+	t.buf = t.buf[:n]
+
+	if _, err := io.ReadFull(t.r, t.buf); err != nil {
+		return nil, incomplete(err)
+	}
+	t.off += n
+
+	return t.buf, nil
+}
+
+func (t *toJSON) readByte() (byte, error) {
+	b, err := t.r.ReadByte()
+	if err != nil {
+		return 0, incomplete(err)
+	}
+	t.off++
+
+	return b, nil
+}
+
+// readType reads a one byte type, or a two bytes type
+// if the first byte has the StorageHasMore bit.
+func (t *toJSON) readType() (binn.Type, error) {
+	b, err := t.readByte()
+	if err != nil || b&binn.StorageHasMore == 0 {
+		return binn.Type(b), err
+	}
+
+	b2, err := t.readByte()
+
+	return binn.Type(b)<<8 | binn.Type(b2), err
+}
+
+func (t *toJSON) readSize() (int, error) {
+	b, err := t.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if b&0x80 == 0 {
+		return int(b), nil
+	}
+
+	rest, err := t.read(3)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(binary.BigEndian.Uint32([]byte{b & 0x7F, rest[0], rest[1], rest[2]})), nil
+}
+
+func incomplete(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return decode.ErrIncompleteRead
+	}
+
+	return err
+}
+
+func (t *toJSON) item(depth int) error {
+	btype, err := t.readType()
+	if err != nil {
+		return err
+	}
+
+	switch btype.Storage() {
+	case binn.StorageNoBytes:
+		switch btype {
+		case binn.Null:
+			t.w.WriteString("null")
+		case binn.True:
+			t.w.WriteString("true")
+		case binn.False:
+			t.w.WriteString("false")
+		default:
+			t.typeEnvelope(btype, nil)
+		}
+	case binn.StorageByte, binn.StorageWord, binn.StorageDWord, binn.StorageQWord:
+		n, _ := btype.FixedSize()
+		b, err := t.read(n)
+		if err != nil {
+			return err
+		}
+
+		return t.number(btype, b)
+	case binn.StorageString:
+		sz, err := t.readSize()
+		if err != nil {
+			return err
+		}
+
+		b, err := t.read(sz + 1)
+		if err != nil {
+			return err
+		}
+		if b[sz] != 0 {
+			return fmt.Errorf("string is not null terminated: %w", decode.ErrInvalidItem)
+		}
+		b = b[:sz]
+
+		switch name, ok := stringEnvelopes[btype]; {
+		case btype == binn.StringType:
+			writeString(t.w, b)
+		case ok:
+			t.w.WriteString(`{"`)
+			t.w.WriteString(name)
+			t.w.WriteString(`":`)
+			writeString(t.w, b)
+			t.w.WriteByte('}')
+		default:
+			t.typeEnvelope(btype, b)
+		}
+	case binn.StorageBlob:
+		sz, err := t.readSize()
+		if err != nil {
+			return err
+		}
+
+		if btype == binn.BlobType {
+			t.w.WriteString(`{"` + blobEnvelope + `":"`)
+		} else {
+			t.w.WriteString(`{"` + typeEnvelope + `":`)
+			t.w.WriteString(strconv.Itoa(int(btype)))
+			t.w.WriteString(`,"` + dataEnvelope + `":"`)
+		}
+
+		enc := base64.NewEncoder(base64.StdEncoding, t.w)
+		n, err := io.CopyN(enc, t.r, int64(sz))
+		t.off += int(n)
+		if err != nil {
+			return incomplete(err)
+		}
+		enc.Close()
+
+		t.w.WriteString(`"}`)
+	default:
+		return t.container(btype, depth)
+	}
+
+	return nil
+}
+
+func (t *toJSON) number(btype binn.Type, b []byte) error {
+	var buf [32]byte
+
+	switch btype {
+	case binn.Uint8Type:
+		t.w.Write(strconv.AppendUint(buf[:0], uint64(b[0]), 10))
+	case binn.Int8Type:
+		t.w.Write(strconv.AppendInt(buf[:0], int64(int8(b[0])), 10))
+	case binn.Uint16Type:
+		t.w.Write(strconv.AppendUint(buf[:0], uint64(binary.BigEndian.Uint16(b)), 10))
+	case binn.Int16Type:
+		t.w.Write(strconv.AppendInt(buf[:0], int64(int16(binary.BigEndian.Uint16(b))), 10))
+	case binn.Uint32Type:
+		t.w.Write(strconv.AppendUint(buf[:0], uint64(binary.BigEndian.Uint32(b)), 10))
+	case binn.Int32Type:
+		t.w.Write(strconv.AppendInt(buf[:0], int64(int32(binary.BigEndian.Uint32(b))), 10))
+	case binn.Uint64Type:
+		t.w.Write(strconv.AppendUint(buf[:0], binary.BigEndian.Uint64(b), 10))
+	case binn.Int64Type:
+		t.w.Write(strconv.AppendInt(buf[:0], int64(binary.BigEndian.Uint64(b)), 10))
+	case binn.Float32Type:
+		return writeFloat(t.w, float64(math.Float32frombits(binary.BigEndian.Uint32(b))), 32)
+	case binn.Float64Type:
+		return writeFloat(t.w, math.Float64frombits(binary.BigEndian.Uint64(b)), 64)
+	case binn.CurrencyType:
+		t.w.WriteString(`{"` + currencyKey + `":`)
+		t.w.Write(strconv.AppendInt(buf[:0], int64(binary.BigEndian.Uint64(b)), 10))
+		t.w.WriteByte('}')
+	default:
+		t.typeEnvelope(btype, b)
+	}
+
+	return nil
+}
+
+func (t *toJSON) typeEnvelope(btype binn.Type, data []byte) {
+	t.w.WriteString(`{"` + typeEnvelope + `":`)
+	t.w.WriteString(strconv.Itoa(int(btype)))
+
+	if data != nil {
+		t.w.WriteString(`,"` + dataEnvelope + `":"`)
+		enc := base64.NewEncoder(base64.StdEncoding, t.w)
+		enc.Write(data)
+		enc.Close()
+		t.w.WriteByte('"')
+	}
+
+	t.w.WriteByte('}')
+}
+
+func (t *toJSON) container(btype binn.Type, depth int) error {
+	if btype != binn.ListType && btype != binn.MapType && btype != binn.ObjectType {
+		return fmt.Errorf("%w %s", decode.ErrUnknownType, btype)
+	}
+
+	if depth >= MaxDepth {
+		return ErrTooDeep
+	}
+
+	start := t.off - 1
+
+	size, err := t.readSize()
+	if err != nil {
+		return err
+	}
+
+	count, err := t.readSize()
+	if err != nil {
+		return err
+	}
+
+	switch btype {
+	case binn.ListType:
+		t.w.WriteByte('[')
+	case binn.MapType:
+		t.w.WriteString(`{"` + mapEnvelope + `":{`)
+	default:
+		t.w.WriteByte('{')
+	}
+
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			t.w.WriteByte(',')
+		}
+
+		switch btype {
+		case binn.MapType:
+			b, err := t.read(4)
+			if err != nil {
+				return err
+			}
+
+			t.w.WriteByte('"')
+			t.w.WriteString(strconv.Itoa(int(int32(binary.BigEndian.Uint32(b)))))
+			t.w.WriteString(`":`)
+		case binn.ObjectType:
+			n, err := t.readByte()
+			if err != nil {
+				return err
+			}
+
+			key, err := t.read(int(n))
+			if err != nil {
+				return err
+			}
+
+			if len(key) > 0 && key[0] == '$' {
+				// Escaped to tell the keys apart from the envelopes.
+				writeString(t.w, append([]byte{'$'}, key...))
+			} else {
+				writeString(t.w, key)
+			}
+			t.w.WriteByte(':')
+		}
+
+		if err := t.item(depth + 1); err != nil {
+			return err
+		}
+	}
+
+	switch btype {
+	case binn.ListType:
+		t.w.WriteByte(']')
+	case binn.MapType:
+		t.w.WriteString("}}")
+	default:
+		t.w.WriteByte('}')
+	}
+
+	read := t.off - start
+	if read > size {
+		return fmt.Errorf("container items exceed its size: %w", decode.ErrInvalidItem)
+	}
+
+	// Skip the padding after the items, the same way the decoder does.
+	n, err := io.CopyN(io.Discard, t.r, int64(size-read))
+	t.off += int(n)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
+}
+
+// writeFloat writes f with a fraction or an exponent,
+// so that FromJSON reads it back as a float.
+func writeFloat(w *bufio.Writer, f float64, bits int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("%w: %v", ErrUnsupportedValue, f)
+	}
+
+	var buf [32]byte
+	b := strconv.AppendFloat(buf[:0], f, 'g', -1, bits)
+
+	for _, c := range b {
+		if c == '.' || c == 'e' {
+			w.Write(b)
+			return nil
+		}
+	}
+
+	w.Write(b)
+	w.WriteString(".0")
+
+	return nil
+}
+
+const hex = "0123456789abcdef"
+
+// writeString writes s as a JSON string. Invalid UTF-8 is replaced
+// with the Unicode replacement character.
+func writeString(w *bufio.Writer, s []byte) {
+	w.WriteByte('"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+
+			w.Write(s[start:i])
+			switch c {
+			case '"', '\\':
+				w.WriteByte('\\')
+				w.WriteByte(c)
+			case '\n':
+				w.WriteString(`\n`)
+			case '\r':
+				w.WriteString(`\r`)
+			case '\t':
+				w.WriteString(`\t`)
+			default:
+				w.WriteString(`\u00`)
+				w.WriteByte(hex[c>>4])
+				w.WriteByte(hex[c&0xF])
+			}
+			i++
+			start = i
+
+			continue
+		}
+
+		r, size := utf8.DecodeRune(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			w.Write(s[start:i])
+			w.WriteString(`\ufffd`)
+			i += size
+			start = i
+
+			continue
+		}
+
+		// U+2028 and U+2029 are escaped for JavaScript, like encoding/json does.
+		if r == '\u2028' || r == '\u2029' {
+			w.Write(s[start:i])
+			w.WriteString(`\u202`)
+			w.WriteByte(hex[r&0xF])
+			i += size
+			start = i
+
+			continue
+		}
+
+		i += size
+	}
+
+	w.Write(s[start:])
+	w.WriteByte('"')
+}
@@ -0,0 +1,161 @@
+package binngo
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+)
+
+// CanonicalOptions are the options of Canonicalize.
+type CanonicalOptions struct {
+	// MinimalInts stores the integers in the smallest types holding
+	// them, the non-negative ones as unsigned, as Marshal does.
+	MinimalInts bool
+}
+
+// Canonicalize returns the BINN document in data in its normal form:
+// the sizes take one byte where they fit into it, as with Marshal, and
+// the object keys are sorted bytewise and the map keys numerically.
+// Documents equal by Equal have the same normal form, so it can be
+// hashed or signed regardless of the producer of the document. With
+// MinimalInts, so do the documents equal with IgnoreIntWidth.
+//
+// The items of containers of user types are kept as they are. Containers
+// nested deeper than decode.MaxDepth return decode.ErrTooDeep.
+func Canonicalize(data []byte, opts *CanonicalOptions) ([]byte, error) {
+	c := canonicalizer{}
+	if opts != nil {
+		c.opts = *opts
+	}
+
+	dst, rest, err := c.appendItem(make([]byte, 0, len(data)), data, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("binngo: %w: trailing data", decode.ErrInvalidItem)
+	}
+
+	return dst, nil
+}
+
+type canonicalizer struct {
+	opts CanonicalOptions
+}
+
+// appendItem appends the normal form of the item at the start of b
+// to dst, and returns the bytes after the item. The depth is the number
+// of the containers holding the item.
+func (c *canonicalizer) appendItem(dst, b []byte, depth int) ([]byte, []byte, error) {
+	raw, rest, err := decode.ReadItemBytes(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	btype, _ := decode.ReadTypeBytes(raw)
+
+	if isContainer(btype) && depth >= decode.MaxDepth {
+		return nil, nil, decode.ErrTooDeep
+	}
+
+	switch {
+	case btype == binn.ListType:
+		dst, err = c.appendList(dst, raw, depth)
+	case isContainer(btype):
+		dst, err = c.appendEntries(dst, btype, raw, depth)
+	case btype.IsContainer():
+		dst = append(dst, raw...)
+	case c.opts.MinimalInts && isInt(btype):
+		n := intValue(raw)
+		if n.negative {
+			dst = encode.AppendInt(dst, int64(n.bits))
+		} else {
+			dst = encode.AppendUint(dst, n.bits)
+		}
+	default:
+		dst, err = appendValue(dst, raw)
+	}
+
+	return dst, rest, err
+}
+
+func appendValue(dst, raw []byte) ([]byte, error) {
+	btype, v, _, err := decode.ReadValueBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// The type takes the same bytes in any encoding.
+	if btype > 0xFF {
+		dst = append(dst, raw[:2]...)
+	} else {
+		dst = append(dst, raw[0])
+	}
+
+	switch btype.Storage() {
+	case binn.StorageString:
+		dst = append(dst, encode.Size(len(v), false)...)
+		dst = append(append(dst, v...), 0x00)
+	case binn.StorageBlob:
+		dst = append(dst, encode.Size(len(v), false)...)
+		dst = append(dst, v...)
+	default:
+		dst = append(dst, v...)
+	}
+
+	return dst, nil
+}
+
+func (c *canonicalizer) appendList(dst, raw []byte, depth int) ([]byte, error) {
+	count, items, _, err := decode.ReadListBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	dst, start := encode.BeginContainer(dst, binn.ListType, count)
+
+	for i := 0; i < count; i++ {
+		dst, items, err = c.appendItem(dst, items, depth+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return encode.EndContainer(dst, start), nil
+}
+
+func (c *canonicalizer) appendEntries(dst []byte, btype binn.Type, raw []byte, depth int) ([]byte, error) {
+	entries, err := readEntries(btype, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if btype == binn.MapType {
+			return entries[i].mapKey < entries[j].mapKey
+		}
+
+		return entries[i].key < entries[j].key
+	})
+
+	dst, start := encode.BeginContainer(dst, uint8(btype), len(entries))
+
+	for _, e := range entries {
+		if btype == binn.MapType {
+			dst = encode.AppendMapKey(dst, int64(e.mapKey))
+		} else {
+			dst = encode.AppendObjectKey(dst, e.key)
+		}
+
+		dst, _, err = c.appendItem(dst, e.item, depth+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return encode.EndContainer(dst, start), nil
+}
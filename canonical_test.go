@@ -0,0 +1,178 @@
+package binngo_test
+
+import (
+	"testing"
+
+	"github.com/et-nik/binngo"
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		opts *binngo.CanonicalOptions
+		want []byte
+	}{
+		{
+			"4 byte sizes",
+			[]byte{
+				binn.ListType, 0x80, 0x00, 0x00, 0x17, 0x80, 0x00, 0x00, 0x02,
+				binn.StringType, 0x80, 0x00, 0x00, 0x02, 'h', 'i', 0x00,
+				binn.BlobType, 0x80, 0x00, 0x00, 0x01, 0xFF,
+			},
+			nil,
+			[]byte{
+				binn.ListType, 0x0B, 0x02,
+				binn.StringType, 0x02, 'h', 'i', 0x00,
+				binn.BlobType, 0x01, 0xFF,
+			},
+		},
+		{
+			"object keys",
+			[]byte{
+				binn.ObjectType, 0x0D, 0x03,
+				0x01, 'b', binn.True,
+				0x02, 'a', 'b', binn.True,
+				0x01, 'a', binn.False,
+			},
+			nil,
+			[]byte{
+				binn.ObjectType, 0x0D, 0x03,
+				0x01, 'a', binn.False,
+				0x02, 'a', 'b', binn.True,
+				0x01, 'b', binn.True,
+			},
+		},
+		{
+			"map keys",
+			[]byte{
+				binn.MapType, 0x12, 0x03,
+				0x00, 0x00, 0x00, 0x02, binn.True,
+				0xFF, 0xFF, 0xFF, 0xFF, binn.True,
+				0x00, 0x00, 0x00, 0x01, binn.False,
+			},
+			nil,
+			[]byte{
+				binn.MapType, 0x12, 0x03,
+				0xFF, 0xFF, 0xFF, 0xFF, binn.True,
+				0x00, 0x00, 0x00, 0x01, binn.False,
+				0x00, 0x00, 0x00, 0x02, binn.True,
+			},
+		},
+		{
+			"integer widths",
+			[]byte{binn.ListType, 0x0D, 0x02, binn.Int32Type, 0x00, 0x00, 0x01, 0x2C, binn.Int32Type, 0xFF, 0xFF, 0xFF, 0xFF},
+			nil,
+			[]byte{binn.ListType, 0x0D, 0x02, binn.Int32Type, 0x00, 0x00, 0x01, 0x2C, binn.Int32Type, 0xFF, 0xFF, 0xFF, 0xFF},
+		},
+		{
+			"minimal integer widths",
+			[]byte{binn.ListType, 0x0D, 0x02, binn.Int32Type, 0x00, 0x00, 0x01, 0x2C, binn.Int32Type, 0xFF, 0xFF, 0xFF, 0xFF},
+			&binngo.CanonicalOptions{MinimalInts: true},
+			[]byte{binn.ListType, 0x08, 0x02, binn.Uint16Type, 0x01, 0x2C, binn.Int8Type, 0xFF},
+		},
+		{
+			"user types",
+			[]byte{
+				binn.ListType, 0x12, 0x02,
+				0xB0, 0x01, 0x80, 0x00, 0x00, 0x01, 'x', 0x00,
+				0xF0, 0x01, 0x80, 0x00, 0x00, 0x07, 0x00,
+			},
+			nil,
+			[]byte{
+				binn.ListType, 0x0F, 0x02,
+				0xB0, 0x01, 0x01, 'x', 0x00,
+				0xF0, 0x01, 0x80, 0x00, 0x00, 0x07, 0x00,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := binngo.Canonicalize(test.data, test.opts)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.True(t, binngo.Equal(test.data, got, &binngo.EqualOptions{IgnoreIntWidth: true}))
+		})
+	}
+}
+
+func TestCanonicalizeLargeContainers(t *testing.T) {
+	m := make(map[string][]string)
+	for i := 0; i < 50; i++ {
+		m[string(rune('a'+i%26))+string(rune('a'+i/26))] = []string{"value", "other value"}
+	}
+
+	a, err := binngo.Marshal(m)
+	require.NoError(t, err)
+	b, err := binngo.Marshal(m)
+	require.NoError(t, err)
+
+	ca, err := binngo.Canonicalize(a, nil)
+	require.NoError(t, err)
+	cb, err := binngo.Canonicalize(b, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, ca, cb)
+	assert.Len(t, ca, len(a))
+
+	again, err := binngo.Canonicalize(ca, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ca, again)
+}
+
+func TestCanonicalizeMalformed(t *testing.T) {
+	_, err := binngo.Canonicalize([]byte{binn.True, binn.True}, nil)
+	assert.ErrorIs(t, err, decode.ErrInvalidItem)
+
+	_, err = binngo.Canonicalize([]byte{binn.ListType, 0x04, 0x01}, nil)
+	assert.ErrorIs(t, err, decode.ErrInvalidItem)
+}
+
+// nestedLists returns the encoding of n lists nested in each other.
+func nestedLists(n int) []byte {
+	sizes := make([]int, n)
+	for i := range sizes {
+		size := 3
+		if i > 0 {
+			size = sizes[i-1] + 3
+		}
+		if size > 127 {
+			size += 3
+		}
+		sizes[i] = size
+	}
+
+	b := make([]byte, 0, sizes[n-1])
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, binn.ListType)
+		if sizes[i] > 127 {
+			b = append(b, byte(sizes[i]>>24)|0x80, byte(sizes[i]>>16), byte(sizes[i]>>8), byte(sizes[i]))
+		} else {
+			b = append(b, byte(sizes[i]))
+		}
+		if i > 0 {
+			b = append(b, 1)
+		} else {
+			b = append(b, 0)
+		}
+	}
+
+	return b
+}
+
+func TestCanonicalizeMaxDepth(t *testing.T) {
+	b := nestedLists(decode.MaxDepth)
+
+	c, err := binngo.Canonicalize(b, nil)
+	require.NoError(t, err)
+	assert.Equal(t, b, c)
+
+	_, err = binngo.Canonicalize(nestedLists(decode.MaxDepth+1), nil)
+	assert.ErrorIs(t, err, decode.ErrTooDeep)
+}
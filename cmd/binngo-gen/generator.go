@@ -0,0 +1,538 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type generator struct {
+	pkg *pkg
+	buf bytes.Buffer
+	tmp int
+
+	// Packages and variables used by the function being generated.
+	usesBinn    bool
+	usesStrconv bool
+	usesErr     bool
+	usesOK      bool
+}
+
+func (g *generator) p(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+func (g *generator) name(prefix string) string {
+	g.tmp++
+	return prefix + strconv.Itoa(g.tmp)
+}
+
+func (g *generator) typeString(t *typeInfo) string {
+	return g.pkg.typeString(t.expr)
+}
+
+// generate returns the source of the methods of the structs.
+func generate(p *pkg, structs []structType) ([]byte, error) {
+	g := &generator{pkg: p}
+
+	var body bytes.Buffer
+	for _, st := range structs {
+		g.structMethods(st)
+		body.Write(g.buf.Bytes())
+		g.buf.Reset()
+	}
+
+	imports := []string{
+		`"fmt"`,
+		`"github.com/et-nik/binngo/decode"`,
+		`"github.com/et-nik/binngo/encode"`,
+	}
+	if g.usesBinn {
+		imports = append(imports, `"github.com/et-nik/binngo/binn"`)
+	}
+	if g.usesStrconv {
+		imports = append(imports, `"strconv"`)
+	}
+	imports = append(imports, p.importSpecs()...)
+
+	return formatFile(p.name, imports, body.Bytes())
+}
+
+// formatFile returns the formatted source of the file with the standard
+// library imports grouped before the others.
+func formatFile(pkgName string, imports []string, body []byte) ([]byte, error) {
+	var std, other []string
+	for _, imp := range imports {
+		path := imp[strings.Index(imp, `"`)+1:]
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			other = append(other, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	sort.Slice(std, func(i, j int) bool { return importPath(std[i]) < importPath(std[j]) })
+	sort.Slice(other, func(i, j int) bool { return importPath(other[i]) < importPath(other[j]) })
+
+	var src bytes.Buffer
+
+	fmt.Fprintf(&src, "%s\n\npackage %s\n\nimport (\n", generatedHeader, pkgName)
+	fmt.Fprintf(&src, "%s\n\n%s\n)\n\n", strings.Join(std, "\n"), strings.Join(other, "\n"))
+	src.Write(body)
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the generated code: %w\n%s", err, src.Bytes())
+	}
+
+	return out, nil
+}
+
+func (g *generator) structMethods(st structType) {
+	g.p("// MarshalBINN implements encode.Marshaler.")
+	g.p("func (v %s) MarshalBINN() ([]byte, error) {", st.name)
+	g.p("return v.AppendBINN(nil)")
+	g.p("}")
+	g.p("")
+
+	g.p("// AppendBINN implements encode.Appender.")
+	g.p("func (v %s) AppendBINN(dst []byte) ([]byte, error) {", st.name)
+	g.appendBody(st)
+	g.p("}")
+	g.p("")
+
+	g.p("// UnmarshalBINN implements decode.Unmarshaler.")
+	g.p("func (v *%s) UnmarshalBINN(data []byte) error {", st.name)
+	g.unmarshalBody(st)
+	g.p("}")
+	g.p("")
+}
+
+// appendBody appends the fields in the order the reflective
+// struct encoder does.
+func (g *generator) appendBody(st structType) {
+	outer := g.buf
+	g.buf = bytes.Buffer{}
+	g.tmp = 0
+	g.usesErr = false
+
+	g.usesBinn = true
+	if st.mapKeys {
+		g.p("dst, start := encode.BeginContainer(dst, binn.MapType, %d)", len(st.fields))
+	} else {
+		g.p("dst, start := encode.BeginContainer(dst, binn.ObjectType, %d)", len(st.fields))
+	}
+	for _, f := range st.fields {
+		if st.mapKeys {
+			g.p("dst = encode.AppendMapKey(dst, %d)", f.mapKey)
+		} else {
+			g.p("dst = encode.AppendObjectKey(dst, %q)", f.key)
+		}
+		g.appendValue("v."+f.name, f.typ, true)
+	}
+	g.p("return encode.EndContainer(dst, start), nil")
+
+	body := g.buf
+	g.buf = outer
+	if g.usesErr {
+		g.p("var err error")
+	}
+	g.buf.Write(body.Bytes())
+}
+
+// appendValue appends the encoding of x. The addressable values are
+// passed to the reflective encoder by pointer, so it finds the methods
+// with pointer receivers like it does for the struct fields.
+func (g *generator) appendValue(x string, t *typeInfo, addressable bool) {
+	switch t.kind {
+	case kindBool:
+		g.p("dst = encode.AppendBool(dst, bool(%s))", x)
+	case kindInt:
+		g.p("dst = encode.AppendInt(dst, int64(%s))", x)
+	case kindUint:
+		g.p("dst = encode.AppendUint(dst, uint64(%s))", x)
+	case kindFloat32:
+		g.p("dst = encode.AppendFloat32(dst, float32(%s))", x)
+	case kindFloat64:
+		g.p("dst = encode.AppendFloat64(dst, float64(%s))", x)
+	case kindString:
+		g.p("dst = encode.AppendString(dst, string(%s))", x)
+	case kindSlice, kindArray:
+		start, i := g.name("start"), g.name("i")
+		g.usesBinn = true
+		g.p("{")
+		g.p("var %s int", start)
+		g.p("dst, %s = encode.BeginContainer(dst, binn.ListType, len(%s))", start, x)
+		g.p("for %s := range %s {", i, x)
+		g.appendValue(fmt.Sprintf("%s[%s]", x, i), t.elem, addressable || t.kind == kindSlice)
+		g.p("}")
+		g.p("dst = encode.EndContainer(dst, %s)", start)
+		g.p("}")
+	case kindObjectMap, kindIntMap:
+		start, k, e := g.name("start"), g.name("k"), g.name("e")
+		g.usesBinn = true
+		g.p("{")
+		g.p("var %s int", start)
+		if t.kind == kindObjectMap {
+			g.p("dst, %s = encode.BeginContainer(dst, binn.ObjectType, len(%s))", start, x)
+			g.p("for %s, %s := range %s {", k, e, x)
+			g.p("if len(%s) > binn.MaxKeySize {", k)
+			g.p("return nil, encode.ErrKeyTooLong")
+			g.p("}")
+			g.p("dst = encode.AppendObjectKey(dst, string(%s))", k)
+		} else {
+			g.p("dst, %s = encode.BeginContainer(dst, binn.MapType, len(%s))", start, x)
+			g.p("for %s, %s := range %s {", k, e, x)
+			g.checkMapKey(k, t.key)
+			g.p("dst = encode.AppendMapKey(dst, int64(%s))", k)
+		}
+		g.appendValue(e, t.elem, false)
+		g.p("}")
+		g.p("dst = encode.EndContainer(dst, %s)", start)
+		g.p("}")
+	case kindPtr:
+		g.p("if %s == nil {", x)
+		g.p("dst = encode.AppendNull(dst)")
+		g.p("} else {")
+		g.appendValue("(*"+x+")", t.elem, true)
+		g.p("}")
+	case kindStruct:
+		g.usesErr = true
+		g.p("if dst, err = %s.AppendBINN(dst); err != nil {", x)
+		g.p("return nil, err")
+		g.p("}")
+	default:
+		raw := g.name("raw")
+		g.usesErr = true
+		if addressable {
+			x = "&" + x
+		}
+		g.p("{")
+		g.p("var %s []byte", raw)
+		g.p("if %s, err = encode.Marshal(%s); err != nil {", raw, x)
+		g.p("return nil, err")
+		g.p("}")
+		g.p("dst = append(dst, %s...)", raw)
+		g.p("}")
+	}
+}
+
+// checkMapKey checks that the map key k fits into int32,
+// unless its type is narrower.
+func (g *generator) checkMapKey(k string, t *typeInfo) {
+	switch {
+	case t.kind == kindInt && t.bits != 0 && t.bits <= 32:
+		return
+	case t.kind == kindUint && t.bits != 0 && t.bits < 32:
+		return
+	}
+
+	g.usesErr = true
+	if t.kind == kindInt {
+		g.p("if err = encode.CheckIntMapKey(int64(%s)); err != nil {", k)
+	} else {
+		g.p("if err = encode.CheckUintMapKey(uint64(%s)); err != nil {", k)
+	}
+	g.p("return nil, err")
+	g.p("}")
+}
+
+// unmarshalBody decodes an object into the fields matching the object
+// keys by the field names or by the binn tags, like the reflective
+// struct decoder does. The structs tagged with map keys are decoded
+// from maps too.
+func (g *generator) unmarshalBody(st structType) {
+	outer := g.buf
+	g.buf = bytes.Buffer{}
+	g.tmp = 0
+	g.usesOK = false
+
+	g.p("b, isNull := decode.ReadNullBytes(data)")
+	g.p("if isNull {")
+	g.p("return nil")
+	g.p("}")
+	if st.mapKeys {
+		g.unmarshalMap(st)
+	}
+	g.p("n, b, _, err := decode.ReadObjectBytes(b)")
+	g.p("if err != nil {")
+	g.p("return err")
+	g.p("}")
+	g.p("for i := 0; i < n; i++ {")
+	g.p("var key []byte")
+	g.p("if key, b, err = decode.ReadObjectKeyBytes(b); err != nil {")
+	g.p("return err")
+	g.p("}")
+	g.p("switch string(key) {")
+
+	for i, f := range st.fields {
+		g.p("case %s:", strings.Join(matchingKeys(st, i), ", "))
+		g.decodeValue("v."+f.name, f.typ, "b")
+	}
+
+	g.p("default:")
+	g.p(`return fmt.Errorf("failed to find field name by tag: %%w", decode.ErrItemNotFound)`)
+	g.p("}")
+	g.p("}")
+	g.p("return nil")
+
+	body := g.buf
+	g.buf = outer
+	if g.usesOK {
+		g.p("var ok bool")
+	}
+	g.buf.Write(body.Bytes())
+}
+
+// unmarshalMap decodes a map into the fields tagged with its keys.
+// Objects are still decoded by the field names.
+func (g *generator) unmarshalMap(st structType) {
+	g.usesBinn = true
+	g.p("if btype, _ := decode.ReadTypeBytes(b); btype == binn.MapType {")
+	g.p("n, b, _, err := decode.ReadMapBytes(b)")
+	g.p("if err != nil {")
+	g.p("return err")
+	g.p("}")
+	g.p("for i := 0; i < n; i++ {")
+	g.p("var key int32")
+	g.p("if key, b, err = decode.ReadMapKeyBytes(b); err != nil {")
+	g.p("return err")
+	g.p("}")
+	g.p("switch key {")
+
+	for i, f := range st.fields {
+		if lastMapKey(st, f.mapKey) != i {
+			continue
+		}
+		g.p("case %d:", f.mapKey)
+		g.decodeValue("v."+f.name, f.typ, "b")
+	}
+
+	g.p("default:")
+	g.p(`return fmt.Errorf("failed to find field by map key %%d: %%w", key, decode.ErrItemNotFound)`)
+	g.p("}")
+	g.p("}")
+	g.p("return nil")
+	g.p("}")
+}
+
+// lastMapKey returns the index of the last field tagged with the map key,
+// which the key is decoded into.
+func lastMapKey(st structType, k int32) int {
+	last := -1
+	for i, f := range st.fields {
+		if f.mapKey == k {
+			last = i
+		}
+	}
+
+	return last
+}
+
+// matchingKeys returns the quoted object keys decoded into the field i.
+// The field names take precedence over the tags.
+func matchingKeys(st structType, i int) []string {
+	fields := map[string]int{}
+	candidates := []string{st.fields[i].name}
+	if !st.mapKeys {
+		for j, f := range st.fields {
+			fields[f.key] = j
+		}
+		candidates = append(candidates, st.fields[i].key)
+	}
+	for j, f := range st.fields {
+		fields[f.name] = j
+	}
+
+	var keys []string
+	for _, k := range candidates {
+		if fields[k] == i && !contains(keys, strconv.Quote(k)) {
+			keys = append(keys, strconv.Quote(k))
+		}
+	}
+
+	if len(keys) == 0 {
+		// The field can't be matched, keep the case unreachable.
+		keys = append(keys, strconv.Quote("\x00"+st.fields[i].name))
+	}
+
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeValue decodes the item at the start of the buffer buf into
+// the addressable x and moves the buffer past the item.
+//
+//nolint:funlen
+func (g *generator) decodeValue(x string, t *typeInfo, buf string) {
+	switch t.kind {
+	case kindBool, kindInt, kindUint, kindFloat32, kindFloat64, kindString:
+		g.decodeScalar(x, t, buf)
+	case kindSlice:
+		n, items, i := g.name("n"), g.name("items"), g.name("i")
+		g.usesOK = true
+		g.p("if %s, ok = decode.ReadNullBytes(%s); ok {", buf, buf)
+		g.p("%s = nil", x)
+		g.p("} else {")
+		g.p("var %s int", n)
+		g.p("var %s []byte", items)
+		g.p("if %s, %s, %s, err = decode.ReadListBytes(%s); err != nil {", n, items, buf, buf)
+		g.p("return err")
+		g.p("}")
+		g.p("if %s == nil || cap(%s) < %s {", x, x, n)
+		g.p("%s = make(%s, 0, %s)", x, g.typeString(t), n)
+		g.p("} else {")
+		g.p("%s = %s[:0]", x, x)
+		g.p("}")
+		g.p("for %s := 0; %s < %s; %s++ {", i, i, n, i)
+		g.p("%s = %s[:%s+1]", x, x, i)
+		g.decodeValue(fmt.Sprintf("%s[%s]", x, i), t.elem, items)
+		g.p("}")
+		g.p("}")
+	case kindArray:
+		n, items, i := g.name("n"), g.name("items"), g.name("i")
+		g.usesOK = true
+		g.p("if %s, ok = decode.ReadNullBytes(%s); !ok {", buf, buf)
+		g.p("var %s int", n)
+		g.p("var %s []byte", items)
+		g.p("if %s, %s, %s, err = decode.ReadListBytes(%s); err != nil {", n, items, buf, buf)
+		g.p("return err")
+		g.p("}")
+		g.p("for %s := 0; %s < %s; %s++ {", i, i, n, i)
+		g.p("if %s >= len(%s) {", i, x)
+		g.p("if %s, err = decode.SkipBytes(%s); err != nil {", items, items)
+		g.p("return err")
+		g.p("}")
+		g.p("continue")
+		g.p("}")
+		g.decodeValue(fmt.Sprintf("%s[%s]", x, i), t.elem, items)
+		g.p("}")
+		g.p("for %s := %s; %s < len(%s); %s++ {", i, n, i, x, i)
+		g.p("var zero %s", g.typeString(t.elem))
+		g.p("%s[%s] = zero", x, i)
+		g.p("}")
+		g.p("}")
+	case kindObjectMap, kindIntMap:
+		n, items, i, k, e := g.name("n"), g.name("items"), g.name("i"), g.name("k"), g.name("e")
+		g.usesOK = true
+		g.p("if %s, ok = decode.ReadNullBytes(%s); ok {", buf, buf)
+		g.p("%s = nil", x)
+		g.p("} else {")
+		g.p("var %s int", n)
+		g.p("var %s []byte", items)
+		if t.kind == kindObjectMap {
+			g.p("if %s, %s, %s, err = decode.ReadObjectBytes(%s); err != nil {", n, items, buf, buf)
+		} else {
+			g.p("if %s, %s, %s, err = decode.ReadMapBytes(%s); err != nil {", n, items, buf, buf)
+		}
+		g.p("return err")
+		g.p("}")
+		g.p("if %s == nil {", x)
+		g.p("%s = make(%s, %s)", x, g.typeString(t), n)
+		g.p("}")
+		g.p("for %s := 0; %s < %s; %s++ {", i, i, n, i)
+		if t.kind == kindObjectMap {
+			g.p("var %s []byte", k)
+			g.p("if %s, %s, err = decode.ReadObjectKeyBytes(%s); err != nil {", k, items, items)
+		} else if t.key.kind == kindInt {
+			g.p("var %s int64", k)
+			g.p("if %s, %s, err = decode.ReadIntMapKeyBytes(%s, %s); err != nil {", k, items, items, g.bitSize(t.key))
+		} else {
+			g.p("var %s uint64", k)
+			g.p("if %s, %s, err = decode.ReadUintMapKeyBytes(%s, %s); err != nil {", k, items, items, g.bitSize(t.key))
+		}
+		g.p("return err")
+		g.p("}")
+		g.p("var %s %s", e, g.typeString(t.elem))
+		g.decodeValue(e, t.elem, items)
+		g.p("%s[%s(%s)] = %s", x, g.typeString(t.key), k, e)
+		g.p("}")
+		g.p("}")
+	case kindPtr:
+		g.usesOK = true
+		g.p("if %s, ok = decode.ReadNullBytes(%s); ok {", buf, buf)
+		g.p("%s = nil", x)
+		g.p("} else {")
+		g.p("if %s == nil {", x)
+		g.p("%s = new(%s)", x, g.typeString(t.elem))
+		g.p("}")
+		g.decodeValue("(*"+x+")", t.elem, buf)
+		g.p("}")
+	default:
+		raw := g.name("raw")
+		g.p("{")
+		g.p("var %s []byte", raw)
+		g.p("if %s, %s, err = decode.ReadItemBytes(%s); err != nil {", raw, buf, buf)
+		g.p("return err")
+		g.p("}")
+		if t.kind == kindStruct {
+			g.p("if err = %s.UnmarshalBINN(%s); err != nil {", x, raw)
+		} else {
+			g.p("if err = decode.Unmarshal(%s, &%s); err != nil {", raw, x)
+		}
+		g.p("return err")
+		g.p("}")
+		g.p("}")
+	}
+}
+
+// decodeScalar leaves x unchanged for null items like the reflective
+// decoder does.
+func (g *generator) decodeScalar(x string, t *typeInfo, buf string) {
+	tmp := g.name("x")
+
+	g.usesOK = true
+	g.p("if %s, ok = decode.ReadNullBytes(%s); !ok {", buf, buf)
+
+	switch t.kind {
+	case kindBool:
+		g.p("var %s bool", tmp)
+		g.p("if %s, %s, err = decode.ReadBoolBytes(%s); err != nil {", tmp, buf, buf)
+	case kindInt:
+		g.p("var %s int64", tmp)
+		g.p("if %s, %s, err = decode.ReadIntBytes(%s, %s); err != nil {", tmp, buf, buf, g.bitSize(t))
+	case kindUint:
+		g.p("var %s uint64", tmp)
+		g.p("if %s, %s, err = decode.ReadUintBytes(%s, %s); err != nil {", tmp, buf, buf, g.bitSize(t))
+	case kindFloat32, kindFloat64:
+		bits := 64
+		if t.kind == kindFloat32 {
+			bits = 32
+		}
+		g.p("var %s float64", tmp)
+		g.p("if %s, %s, err = decode.ReadFloatBytes(%s, %d); err != nil {", tmp, buf, buf, bits)
+	case kindString:
+		g.p("var %s string", tmp)
+		g.p("if %s, %s, err = decode.ReadStringBytes(%s); err != nil {", tmp, buf, buf)
+	}
+
+	g.p("return err")
+	g.p("}")
+	g.p("%s = %s(%s)", x, g.typeString(t), tmp)
+	g.p("}")
+}
+
+func (g *generator) bitSize(t *typeInfo) string {
+	if t.bits == 0 {
+		g.usesStrconv = true
+		return "strconv.IntSize"
+	}
+
+	return strconv.Itoa(t.bits)
+}
+
+func importPath(spec string) string {
+	return spec[strings.Index(spec, `"`):]
+}
@@ -0,0 +1,70 @@
+// Package example holds the structs used to test the code
+// generated by binngo-gen.
+package example
+
+import (
+	"net"
+	"time"
+)
+
+//go:generate go run github.com/et-nik/binngo/cmd/binngo-gen example.go
+
+// Color is a named scalar encoded as its underlying type.
+type Color uint8
+
+// Level has text marshaling methods, so it's encoded by reflection.
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte{'L', byte('0' + l)}, nil
+}
+
+func (l *Level) UnmarshalText(b []byte) error {
+	*l = Level(b[1] - '0')
+	return nil
+}
+
+//binngo:gen
+type Document struct {
+	ID       int64             `binn:"id"`
+	Title    string            `binn:"title"`
+	Draft    bool              `binn:"draft,omitempty"`
+	Score    float64           `binn:"score"`
+	Ratio    float32           `binn:"ratio"`
+	Small    int8              `binn:"small"`
+	Medium   uint16            `binn:"medium"`
+	Big      uint64            `binn:"big"`
+	Count    int               `binn:"count"`
+	Color    Color             `binn:"color"`
+	Level    Level             `binn:"level"`
+	Tags     []string          `binn:"tags"`
+	Bytes    []byte            `binn:"bytes"`
+	Matrix   [2][3]int16       `binn:"matrix"`
+	Attrs    map[string]string `binn:"attrs"`
+	Indexed  map[int]Item      `binn:"indexed"`
+	Codes    map[uint16]string `binn:"codes"`
+	Author   *Item             `binn:"author"`
+	Items    []Item            `binn:"items"`
+	Parent   *Document         `binn:"parent"`
+	Note     *string           `binn:"note"`
+	Created  time.Time         `binn:"created"`
+	Addr     net.IP            `binn:"addr"`
+	Extra    interface{}       `binn:"extra"`
+	Untagged uint
+	private  int32
+}
+
+//binngo:gen
+type Item struct {
+	Name  string `binn:"name"`
+	Value int
+}
+
+// Record is encoded into a map, like the maps of numeric IDs of C peers.
+//
+//binngo:gen
+type Record struct {
+	ID    int64          `binn:"#1"`
+	Name  string         `binn:"#2"`
+	Flags map[int64]bool `binn:"#-3"`
+}
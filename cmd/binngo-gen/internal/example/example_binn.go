@@ -0,0 +1,816 @@
+// Code generated by binngo-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+)
+
+// MarshalBINN implements encode.Marshaler.
+func (v Document) MarshalBINN() ([]byte, error) {
+	return v.AppendBINN(nil)
+}
+
+// AppendBINN implements encode.Appender.
+func (v Document) AppendBINN(dst []byte) ([]byte, error) {
+	var err error
+	dst, start := encode.BeginContainer(dst, binn.ObjectType, 26)
+	dst = encode.AppendObjectKey(dst, "id")
+	dst = encode.AppendInt(dst, int64(v.ID))
+	dst = encode.AppendObjectKey(dst, "title")
+	dst = encode.AppendString(dst, string(v.Title))
+	dst = encode.AppendObjectKey(dst, "draft")
+	dst = encode.AppendBool(dst, bool(v.Draft))
+	dst = encode.AppendObjectKey(dst, "score")
+	dst = encode.AppendFloat64(dst, float64(v.Score))
+	dst = encode.AppendObjectKey(dst, "ratio")
+	dst = encode.AppendFloat32(dst, float32(v.Ratio))
+	dst = encode.AppendObjectKey(dst, "small")
+	dst = encode.AppendInt(dst, int64(v.Small))
+	dst = encode.AppendObjectKey(dst, "medium")
+	dst = encode.AppendUint(dst, uint64(v.Medium))
+	dst = encode.AppendObjectKey(dst, "big")
+	dst = encode.AppendUint(dst, uint64(v.Big))
+	dst = encode.AppendObjectKey(dst, "count")
+	dst = encode.AppendInt(dst, int64(v.Count))
+	dst = encode.AppendObjectKey(dst, "color")
+	dst = encode.AppendUint(dst, uint64(v.Color))
+	dst = encode.AppendObjectKey(dst, "level")
+	{
+		var raw1 []byte
+		if raw1, err = encode.Marshal(&v.Level); err != nil {
+			return nil, err
+		}
+		dst = append(dst, raw1...)
+	}
+	dst = encode.AppendObjectKey(dst, "tags")
+	{
+		var start2 int
+		dst, start2 = encode.BeginContainer(dst, binn.ListType, len(v.Tags))
+		for i3 := range v.Tags {
+			dst = encode.AppendString(dst, string(v.Tags[i3]))
+		}
+		dst = encode.EndContainer(dst, start2)
+	}
+	dst = encode.AppendObjectKey(dst, "bytes")
+	{
+		var start4 int
+		dst, start4 = encode.BeginContainer(dst, binn.ListType, len(v.Bytes))
+		for i5 := range v.Bytes {
+			dst = encode.AppendUint(dst, uint64(v.Bytes[i5]))
+		}
+		dst = encode.EndContainer(dst, start4)
+	}
+	dst = encode.AppendObjectKey(dst, "matrix")
+	{
+		var start6 int
+		dst, start6 = encode.BeginContainer(dst, binn.ListType, len(v.Matrix))
+		for i7 := range v.Matrix {
+			{
+				var start8 int
+				dst, start8 = encode.BeginContainer(dst, binn.ListType, len(v.Matrix[i7]))
+				for i9 := range v.Matrix[i7] {
+					dst = encode.AppendInt(dst, int64(v.Matrix[i7][i9]))
+				}
+				dst = encode.EndContainer(dst, start8)
+			}
+		}
+		dst = encode.EndContainer(dst, start6)
+	}
+	dst = encode.AppendObjectKey(dst, "attrs")
+	{
+		var start10 int
+		dst, start10 = encode.BeginContainer(dst, binn.ObjectType, len(v.Attrs))
+		for k11, e12 := range v.Attrs {
+			if len(k11) > binn.MaxKeySize {
+				return nil, encode.ErrKeyTooLong
+			}
+			dst = encode.AppendObjectKey(dst, string(k11))
+			dst = encode.AppendString(dst, string(e12))
+		}
+		dst = encode.EndContainer(dst, start10)
+	}
+	dst = encode.AppendObjectKey(dst, "indexed")
+	{
+		var start13 int
+		dst, start13 = encode.BeginContainer(dst, binn.MapType, len(v.Indexed))
+		for k14, e15 := range v.Indexed {
+			if err = encode.CheckIntMapKey(int64(k14)); err != nil {
+				return nil, err
+			}
+			dst = encode.AppendMapKey(dst, int64(k14))
+			if dst, err = e15.AppendBINN(dst); err != nil {
+				return nil, err
+			}
+		}
+		dst = encode.EndContainer(dst, start13)
+	}
+	dst = encode.AppendObjectKey(dst, "codes")
+	{
+		var start16 int
+		dst, start16 = encode.BeginContainer(dst, binn.MapType, len(v.Codes))
+		for k17, e18 := range v.Codes {
+			dst = encode.AppendMapKey(dst, int64(k17))
+			dst = encode.AppendString(dst, string(e18))
+		}
+		dst = encode.EndContainer(dst, start16)
+	}
+	dst = encode.AppendObjectKey(dst, "author")
+	if v.Author == nil {
+		dst = encode.AppendNull(dst)
+	} else {
+		if dst, err = (*v.Author).AppendBINN(dst); err != nil {
+			return nil, err
+		}
+	}
+	dst = encode.AppendObjectKey(dst, "items")
+	{
+		var start19 int
+		dst, start19 = encode.BeginContainer(dst, binn.ListType, len(v.Items))
+		for i20 := range v.Items {
+			if dst, err = v.Items[i20].AppendBINN(dst); err != nil {
+				return nil, err
+			}
+		}
+		dst = encode.EndContainer(dst, start19)
+	}
+	dst = encode.AppendObjectKey(dst, "parent")
+	if v.Parent == nil {
+		dst = encode.AppendNull(dst)
+	} else {
+		if dst, err = (*v.Parent).AppendBINN(dst); err != nil {
+			return nil, err
+		}
+	}
+	dst = encode.AppendObjectKey(dst, "note")
+	if v.Note == nil {
+		dst = encode.AppendNull(dst)
+	} else {
+		dst = encode.AppendString(dst, string((*v.Note)))
+	}
+	dst = encode.AppendObjectKey(dst, "created")
+	{
+		var raw21 []byte
+		if raw21, err = encode.Marshal(&v.Created); err != nil {
+			return nil, err
+		}
+		dst = append(dst, raw21...)
+	}
+	dst = encode.AppendObjectKey(dst, "addr")
+	{
+		var raw22 []byte
+		if raw22, err = encode.Marshal(&v.Addr); err != nil {
+			return nil, err
+		}
+		dst = append(dst, raw22...)
+	}
+	dst = encode.AppendObjectKey(dst, "extra")
+	{
+		var raw23 []byte
+		if raw23, err = encode.Marshal(&v.Extra); err != nil {
+			return nil, err
+		}
+		dst = append(dst, raw23...)
+	}
+	dst = encode.AppendObjectKey(dst, "Untagged")
+	dst = encode.AppendUint(dst, uint64(v.Untagged))
+	dst = encode.AppendObjectKey(dst, "private")
+	dst = encode.AppendInt(dst, int64(v.private))
+	return encode.EndContainer(dst, start), nil
+}
+
+// UnmarshalBINN implements decode.Unmarshaler.
+func (v *Document) UnmarshalBINN(data []byte) error {
+	var ok bool
+	b, isNull := decode.ReadNullBytes(data)
+	if isNull {
+		return nil
+	}
+	n, b, _, err := decode.ReadObjectBytes(b)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		var key []byte
+		if key, b, err = decode.ReadObjectKeyBytes(b); err != nil {
+			return err
+		}
+		switch string(key) {
+		case "ID", "id":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x1 int64
+				if x1, b, err = decode.ReadIntBytes(b, 64); err != nil {
+					return err
+				}
+				v.ID = int64(x1)
+			}
+		case "Title", "title":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x2 string
+				if x2, b, err = decode.ReadStringBytes(b); err != nil {
+					return err
+				}
+				v.Title = string(x2)
+			}
+		case "Draft", "draft":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x3 bool
+				if x3, b, err = decode.ReadBoolBytes(b); err != nil {
+					return err
+				}
+				v.Draft = bool(x3)
+			}
+		case "Score", "score":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x4 float64
+				if x4, b, err = decode.ReadFloatBytes(b, 64); err != nil {
+					return err
+				}
+				v.Score = float64(x4)
+			}
+		case "Ratio", "ratio":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x5 float64
+				if x5, b, err = decode.ReadFloatBytes(b, 32); err != nil {
+					return err
+				}
+				v.Ratio = float32(x5)
+			}
+		case "Small", "small":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x6 int64
+				if x6, b, err = decode.ReadIntBytes(b, 8); err != nil {
+					return err
+				}
+				v.Small = int8(x6)
+			}
+		case "Medium", "medium":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x7 uint64
+				if x7, b, err = decode.ReadUintBytes(b, 16); err != nil {
+					return err
+				}
+				v.Medium = uint16(x7)
+			}
+		case "Big", "big":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x8 uint64
+				if x8, b, err = decode.ReadUintBytes(b, 64); err != nil {
+					return err
+				}
+				v.Big = uint64(x8)
+			}
+		case "Count", "count":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x9 int64
+				if x9, b, err = decode.ReadIntBytes(b, strconv.IntSize); err != nil {
+					return err
+				}
+				v.Count = int(x9)
+			}
+		case "Color", "color":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x10 uint64
+				if x10, b, err = decode.ReadUintBytes(b, 8); err != nil {
+					return err
+				}
+				v.Color = Color(x10)
+			}
+		case "Level", "level":
+			{
+				var raw11 []byte
+				if raw11, b, err = decode.ReadItemBytes(b); err != nil {
+					return err
+				}
+				if err = decode.Unmarshal(raw11, &v.Level); err != nil {
+					return err
+				}
+			}
+		case "Tags", "tags":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Tags = nil
+			} else {
+				var n12 int
+				var items13 []byte
+				if n12, items13, b, err = decode.ReadListBytes(b); err != nil {
+					return err
+				}
+				if v.Tags == nil || cap(v.Tags) < n12 {
+					v.Tags = make([]string, 0, n12)
+				} else {
+					v.Tags = v.Tags[:0]
+				}
+				for i14 := 0; i14 < n12; i14++ {
+					v.Tags = v.Tags[:i14+1]
+					if items13, ok = decode.ReadNullBytes(items13); !ok {
+						var x15 string
+						if x15, items13, err = decode.ReadStringBytes(items13); err != nil {
+							return err
+						}
+						v.Tags[i14] = string(x15)
+					}
+				}
+			}
+		case "Bytes", "bytes":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Bytes = nil
+			} else {
+				var n16 int
+				var items17 []byte
+				if n16, items17, b, err = decode.ReadListBytes(b); err != nil {
+					return err
+				}
+				if v.Bytes == nil || cap(v.Bytes) < n16 {
+					v.Bytes = make([]byte, 0, n16)
+				} else {
+					v.Bytes = v.Bytes[:0]
+				}
+				for i18 := 0; i18 < n16; i18++ {
+					v.Bytes = v.Bytes[:i18+1]
+					if items17, ok = decode.ReadNullBytes(items17); !ok {
+						var x19 uint64
+						if x19, items17, err = decode.ReadUintBytes(items17, 8); err != nil {
+							return err
+						}
+						v.Bytes[i18] = byte(x19)
+					}
+				}
+			}
+		case "Matrix", "matrix":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var n20 int
+				var items21 []byte
+				if n20, items21, b, err = decode.ReadListBytes(b); err != nil {
+					return err
+				}
+				for i22 := 0; i22 < n20; i22++ {
+					if i22 >= len(v.Matrix) {
+						if items21, err = decode.SkipBytes(items21); err != nil {
+							return err
+						}
+						continue
+					}
+					if items21, ok = decode.ReadNullBytes(items21); !ok {
+						var n23 int
+						var items24 []byte
+						if n23, items24, items21, err = decode.ReadListBytes(items21); err != nil {
+							return err
+						}
+						for i25 := 0; i25 < n23; i25++ {
+							if i25 >= len(v.Matrix[i22]) {
+								if items24, err = decode.SkipBytes(items24); err != nil {
+									return err
+								}
+								continue
+							}
+							if items24, ok = decode.ReadNullBytes(items24); !ok {
+								var x26 int64
+								if x26, items24, err = decode.ReadIntBytes(items24, 16); err != nil {
+									return err
+								}
+								v.Matrix[i22][i25] = int16(x26)
+							}
+						}
+						for i25 := n23; i25 < len(v.Matrix[i22]); i25++ {
+							var zero int16
+							v.Matrix[i22][i25] = zero
+						}
+					}
+				}
+				for i22 := n20; i22 < len(v.Matrix); i22++ {
+					var zero [3]int16
+					v.Matrix[i22] = zero
+				}
+			}
+		case "Attrs", "attrs":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Attrs = nil
+			} else {
+				var n27 int
+				var items28 []byte
+				if n27, items28, b, err = decode.ReadObjectBytes(b); err != nil {
+					return err
+				}
+				if v.Attrs == nil {
+					v.Attrs = make(map[string]string, n27)
+				}
+				for i29 := 0; i29 < n27; i29++ {
+					var k30 []byte
+					if k30, items28, err = decode.ReadObjectKeyBytes(items28); err != nil {
+						return err
+					}
+					var e31 string
+					if items28, ok = decode.ReadNullBytes(items28); !ok {
+						var x32 string
+						if x32, items28, err = decode.ReadStringBytes(items28); err != nil {
+							return err
+						}
+						e31 = string(x32)
+					}
+					v.Attrs[string(k30)] = e31
+				}
+			}
+		case "Indexed", "indexed":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Indexed = nil
+			} else {
+				var n33 int
+				var items34 []byte
+				if n33, items34, b, err = decode.ReadMapBytes(b); err != nil {
+					return err
+				}
+				if v.Indexed == nil {
+					v.Indexed = make(map[int]Item, n33)
+				}
+				for i35 := 0; i35 < n33; i35++ {
+					var k36 int64
+					if k36, items34, err = decode.ReadIntMapKeyBytes(items34, strconv.IntSize); err != nil {
+						return err
+					}
+					var e37 Item
+					{
+						var raw38 []byte
+						if raw38, items34, err = decode.ReadItemBytes(items34); err != nil {
+							return err
+						}
+						if err = e37.UnmarshalBINN(raw38); err != nil {
+							return err
+						}
+					}
+					v.Indexed[int(k36)] = e37
+				}
+			}
+		case "Codes", "codes":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Codes = nil
+			} else {
+				var n39 int
+				var items40 []byte
+				if n39, items40, b, err = decode.ReadMapBytes(b); err != nil {
+					return err
+				}
+				if v.Codes == nil {
+					v.Codes = make(map[uint16]string, n39)
+				}
+				for i41 := 0; i41 < n39; i41++ {
+					var k42 uint64
+					if k42, items40, err = decode.ReadUintMapKeyBytes(items40, 16); err != nil {
+						return err
+					}
+					var e43 string
+					if items40, ok = decode.ReadNullBytes(items40); !ok {
+						var x44 string
+						if x44, items40, err = decode.ReadStringBytes(items40); err != nil {
+							return err
+						}
+						e43 = string(x44)
+					}
+					v.Codes[uint16(k42)] = e43
+				}
+			}
+		case "Author", "author":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Author = nil
+			} else {
+				if v.Author == nil {
+					v.Author = new(Item)
+				}
+				{
+					var raw45 []byte
+					if raw45, b, err = decode.ReadItemBytes(b); err != nil {
+						return err
+					}
+					if err = (*v.Author).UnmarshalBINN(raw45); err != nil {
+						return err
+					}
+				}
+			}
+		case "Items", "items":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Items = nil
+			} else {
+				var n46 int
+				var items47 []byte
+				if n46, items47, b, err = decode.ReadListBytes(b); err != nil {
+					return err
+				}
+				if v.Items == nil || cap(v.Items) < n46 {
+					v.Items = make([]Item, 0, n46)
+				} else {
+					v.Items = v.Items[:0]
+				}
+				for i48 := 0; i48 < n46; i48++ {
+					v.Items = v.Items[:i48+1]
+					{
+						var raw49 []byte
+						if raw49, items47, err = decode.ReadItemBytes(items47); err != nil {
+							return err
+						}
+						if err = v.Items[i48].UnmarshalBINN(raw49); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		case "Parent", "parent":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Parent = nil
+			} else {
+				if v.Parent == nil {
+					v.Parent = new(Document)
+				}
+				{
+					var raw50 []byte
+					if raw50, b, err = decode.ReadItemBytes(b); err != nil {
+						return err
+					}
+					if err = (*v.Parent).UnmarshalBINN(raw50); err != nil {
+						return err
+					}
+				}
+			}
+		case "Note", "note":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Note = nil
+			} else {
+				if v.Note == nil {
+					v.Note = new(string)
+				}
+				if b, ok = decode.ReadNullBytes(b); !ok {
+					var x51 string
+					if x51, b, err = decode.ReadStringBytes(b); err != nil {
+						return err
+					}
+					(*v.Note) = string(x51)
+				}
+			}
+		case "Created", "created":
+			{
+				var raw52 []byte
+				if raw52, b, err = decode.ReadItemBytes(b); err != nil {
+					return err
+				}
+				if err = decode.Unmarshal(raw52, &v.Created); err != nil {
+					return err
+				}
+			}
+		case "Addr", "addr":
+			{
+				var raw53 []byte
+				if raw53, b, err = decode.ReadItemBytes(b); err != nil {
+					return err
+				}
+				if err = decode.Unmarshal(raw53, &v.Addr); err != nil {
+					return err
+				}
+			}
+		case "Extra", "extra":
+			{
+				var raw54 []byte
+				if raw54, b, err = decode.ReadItemBytes(b); err != nil {
+					return err
+				}
+				if err = decode.Unmarshal(raw54, &v.Extra); err != nil {
+					return err
+				}
+			}
+		case "Untagged":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x55 uint64
+				if x55, b, err = decode.ReadUintBytes(b, strconv.IntSize); err != nil {
+					return err
+				}
+				v.Untagged = uint(x55)
+			}
+		case "private":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x56 int64
+				if x56, b, err = decode.ReadIntBytes(b, 32); err != nil {
+					return err
+				}
+				v.private = int32(x56)
+			}
+		default:
+			return fmt.Errorf("failed to find field name by tag: %w", decode.ErrItemNotFound)
+		}
+	}
+	return nil
+}
+
+// MarshalBINN implements encode.Marshaler.
+func (v Item) MarshalBINN() ([]byte, error) {
+	return v.AppendBINN(nil)
+}
+
+// AppendBINN implements encode.Appender.
+func (v Item) AppendBINN(dst []byte) ([]byte, error) {
+	dst, start := encode.BeginContainer(dst, binn.ObjectType, 2)
+	dst = encode.AppendObjectKey(dst, "name")
+	dst = encode.AppendString(dst, string(v.Name))
+	dst = encode.AppendObjectKey(dst, "Value")
+	dst = encode.AppendInt(dst, int64(v.Value))
+	return encode.EndContainer(dst, start), nil
+}
+
+// UnmarshalBINN implements decode.Unmarshaler.
+func (v *Item) UnmarshalBINN(data []byte) error {
+	var ok bool
+	b, isNull := decode.ReadNullBytes(data)
+	if isNull {
+		return nil
+	}
+	n, b, _, err := decode.ReadObjectBytes(b)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		var key []byte
+		if key, b, err = decode.ReadObjectKeyBytes(b); err != nil {
+			return err
+		}
+		switch string(key) {
+		case "Name", "name":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x1 string
+				if x1, b, err = decode.ReadStringBytes(b); err != nil {
+					return err
+				}
+				v.Name = string(x1)
+			}
+		case "Value":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x2 int64
+				if x2, b, err = decode.ReadIntBytes(b, strconv.IntSize); err != nil {
+					return err
+				}
+				v.Value = int(x2)
+			}
+		default:
+			return fmt.Errorf("failed to find field name by tag: %w", decode.ErrItemNotFound)
+		}
+	}
+	return nil
+}
+
+// MarshalBINN implements encode.Marshaler.
+func (v Record) MarshalBINN() ([]byte, error) {
+	return v.AppendBINN(nil)
+}
+
+// AppendBINN implements encode.Appender.
+func (v Record) AppendBINN(dst []byte) ([]byte, error) {
+	var err error
+	dst, start := encode.BeginContainer(dst, binn.MapType, 3)
+	dst = encode.AppendMapKey(dst, 1)
+	dst = encode.AppendInt(dst, int64(v.ID))
+	dst = encode.AppendMapKey(dst, 2)
+	dst = encode.AppendString(dst, string(v.Name))
+	dst = encode.AppendMapKey(dst, -3)
+	{
+		var start1 int
+		dst, start1 = encode.BeginContainer(dst, binn.MapType, len(v.Flags))
+		for k2, e3 := range v.Flags {
+			if err = encode.CheckIntMapKey(int64(k2)); err != nil {
+				return nil, err
+			}
+			dst = encode.AppendMapKey(dst, int64(k2))
+			dst = encode.AppendBool(dst, bool(e3))
+		}
+		dst = encode.EndContainer(dst, start1)
+	}
+	return encode.EndContainer(dst, start), nil
+}
+
+// UnmarshalBINN implements decode.Unmarshaler.
+func (v *Record) UnmarshalBINN(data []byte) error {
+	var ok bool
+	b, isNull := decode.ReadNullBytes(data)
+	if isNull {
+		return nil
+	}
+	if btype, _ := decode.ReadTypeBytes(b); btype == binn.MapType {
+		n, b, _, err := decode.ReadMapBytes(b)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			var key int32
+			if key, b, err = decode.ReadMapKeyBytes(b); err != nil {
+				return err
+			}
+			switch key {
+			case 1:
+				if b, ok = decode.ReadNullBytes(b); !ok {
+					var x1 int64
+					if x1, b, err = decode.ReadIntBytes(b, 64); err != nil {
+						return err
+					}
+					v.ID = int64(x1)
+				}
+			case 2:
+				if b, ok = decode.ReadNullBytes(b); !ok {
+					var x2 string
+					if x2, b, err = decode.ReadStringBytes(b); err != nil {
+						return err
+					}
+					v.Name = string(x2)
+				}
+			case -3:
+				if b, ok = decode.ReadNullBytes(b); ok {
+					v.Flags = nil
+				} else {
+					var n3 int
+					var items4 []byte
+					if n3, items4, b, err = decode.ReadMapBytes(b); err != nil {
+						return err
+					}
+					if v.Flags == nil {
+						v.Flags = make(map[int64]bool, n3)
+					}
+					for i5 := 0; i5 < n3; i5++ {
+						var k6 int64
+						if k6, items4, err = decode.ReadIntMapKeyBytes(items4, 64); err != nil {
+							return err
+						}
+						var e7 bool
+						if items4, ok = decode.ReadNullBytes(items4); !ok {
+							var x8 bool
+							if x8, items4, err = decode.ReadBoolBytes(items4); err != nil {
+								return err
+							}
+							e7 = bool(x8)
+						}
+						v.Flags[int64(k6)] = e7
+					}
+				}
+			default:
+				return fmt.Errorf("failed to find field by map key %d: %w", key, decode.ErrItemNotFound)
+			}
+		}
+		return nil
+	}
+	n, b, _, err := decode.ReadObjectBytes(b)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		var key []byte
+		if key, b, err = decode.ReadObjectKeyBytes(b); err != nil {
+			return err
+		}
+		switch string(key) {
+		case "ID":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x9 int64
+				if x9, b, err = decode.ReadIntBytes(b, 64); err != nil {
+					return err
+				}
+				v.ID = int64(x9)
+			}
+		case "Name":
+			if b, ok = decode.ReadNullBytes(b); !ok {
+				var x10 string
+				if x10, b, err = decode.ReadStringBytes(b); err != nil {
+					return err
+				}
+				v.Name = string(x10)
+			}
+		case "Flags":
+			if b, ok = decode.ReadNullBytes(b); ok {
+				v.Flags = nil
+			} else {
+				var n11 int
+				var items12 []byte
+				if n11, items12, b, err = decode.ReadMapBytes(b); err != nil {
+					return err
+				}
+				if v.Flags == nil {
+					v.Flags = make(map[int64]bool, n11)
+				}
+				for i13 := 0; i13 < n11; i13++ {
+					var k14 int64
+					if k14, items12, err = decode.ReadIntMapKeyBytes(items12, 64); err != nil {
+						return err
+					}
+					var e15 bool
+					if items12, ok = decode.ReadNullBytes(items12); !ok {
+						var x16 bool
+						if x16, items12, err = decode.ReadBoolBytes(items12); err != nil {
+							return err
+						}
+						e15 = bool(x16)
+					}
+					v.Flags[int64(k14)] = e15
+				}
+			}
+		default:
+			return fmt.Errorf("failed to find field name by tag: %w", decode.ErrItemNotFound)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+// Code generated by binngo-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"bytes"
+	"encoding"
+	"reflect"
+	"testing"
+
+	"github.com/et-nik/binngo/encode"
+)
+
+// binnPlainDocument has the fields of Document without its methods.
+type binnPlainDocument Document
+
+func TestDocumentBINN(t *testing.T) {
+	for _, v := range []Document{{}, binnSampleDocument(3)} {
+		got, err := v.MarshalBINN()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := encode.Marshal((*binnPlainDocument)(&v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("MarshalBINN() = % x, want % x", got, want)
+		}
+
+		var u Document
+		if err := u.UnmarshalBINN(got); err != nil {
+			t.Fatal(err)
+		}
+		again, err := u.MarshalBINN()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again, got) {
+			t.Fatalf("round trip = % x, want % x", again, got)
+		}
+	}
+}
+
+func binnSampleDocument(depth int) Document {
+	if depth == 0 {
+		return Document{}
+	}
+
+	v := Document{
+		ID:       int64(1 << 40),
+		Title:    string("binn2"),
+		Draft:    bool(true),
+		Score:    float64(-1.5),
+		Ratio:    float32(1.5),
+		Small:    int8(-100),
+		Medium:   uint16(300),
+		Big:      uint64(1 << 40),
+		Count:    int(70000),
+		Color:    Color(200),
+		Tags:     []string{string("binn13"), string("binn13")},
+		Bytes:    []byte{byte(200), byte(200)},
+		Matrix:   [2][3]int16{[3]int16{int16(-300), int16(-300)}, [3]int16{int16(-300), int16(-300)}},
+		Attrs:    map[string]string{string("binn20"): string("binn21")},
+		Indexed:  map[int]Item{int(100): binnSampleItem(depth - 1)},
+		Codes:    map[uint16]string{uint16(100): string("binn27")},
+		Author:   func() *Item { v := binnSampleItem(depth - 1); return &v }(),
+		Items:    []Item{binnSampleItem(depth - 1), binnSampleItem(depth - 1)},
+		Parent:   func() *Document { v := binnSampleDocument(depth - 1); return &v }(),
+		Note:     func() *string { v := string("binn35"); return &v }(),
+		Untagged: uint(70000),
+		private:  int32(-70000),
+	}
+	binnFill(reflect.ValueOf(&v.Level).Elem(), depth)
+	binnFill(reflect.ValueOf(&v.Created).Elem(), depth)
+	binnFill(reflect.ValueOf(&v.Addr).Elem(), depth)
+	binnFill(reflect.ValueOf(&v.Extra).Elem(), depth)
+
+	return v
+}
+
+// binnPlainItem has the fields of Item without its methods.
+type binnPlainItem Item
+
+func TestItemBINN(t *testing.T) {
+	for _, v := range []Item{{}, binnSampleItem(3)} {
+		got, err := v.MarshalBINN()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := encode.Marshal((*binnPlainItem)(&v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("MarshalBINN() = % x, want % x", got, want)
+		}
+
+		var u Item
+		if err := u.UnmarshalBINN(got); err != nil {
+			t.Fatal(err)
+		}
+		again, err := u.MarshalBINN()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again, got) {
+			t.Fatalf("round trip = % x, want % x", again, got)
+		}
+	}
+}
+
+func binnSampleItem(depth int) Item {
+	if depth == 0 {
+		return Item{}
+	}
+
+	v := Item{
+		Name:  string("binn41"),
+		Value: int(-70000),
+	}
+
+	return v
+}
+
+// binnPlainRecord has the fields of Record without its methods.
+type binnPlainRecord Record
+
+func TestRecordBINN(t *testing.T) {
+	for _, v := range []Record{{}, binnSampleRecord(3)} {
+		got, err := v.MarshalBINN()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := encode.Marshal((*binnPlainRecord)(&v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("MarshalBINN() = % x, want % x", got, want)
+		}
+
+		var u Record
+		if err := u.UnmarshalBINN(got); err != nil {
+			t.Fatal(err)
+		}
+		again, err := u.MarshalBINN()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again, got) {
+			t.Fatalf("round trip = % x, want % x", again, got)
+		}
+	}
+}
+
+func binnSampleRecord(depth int) Record {
+	if depth == 0 {
+		return Record{}
+	}
+
+	v := Record{
+		ID:    int64(1 << 40),
+		Name:  string("binn44"),
+		Flags: map[int64]bool{int64(100): bool(true)},
+	}
+
+	return v
+}
+
+// binnFill sets v to a sample value of its type, down to the depth.
+func binnFill(v reflect.Value, depth int) {
+	if depth == 0 || !v.CanSet() {
+		return
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		for _, text := range []string{"2021-08-01T12:00:00Z", "192.0.2.1", "1"} {
+			if u.UnmarshalText([]byte(text)) == nil && !v.IsZero() {
+				return
+			}
+		}
+		v.Set(reflect.Zero(v.Type()))
+
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(-1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.String:
+		v.SetString("binn")
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		binnFill(v.Index(0), depth-1)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			binnFill(v.Index(i), depth-1)
+		}
+	case reflect.Map:
+		key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		binnFill(key, depth-1)
+		binnFill(elem, depth-1)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(key, elem)
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		binnFill(v.Elem(), depth-1)
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf("binn"))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			binnFill(v.Field(i), depth-1)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Command binngo-gen generates reflection-free MarshalBINN, AppendBINN
+// and UnmarshalBINN methods for Go structs.
+//
+// Usage:
+//
+//	binngo-gen [-all] [-tests=false] file.go
+//
+// The methods are generated for the structs of the file annotated with
+// a //binngo:gen comment, or for all of its structs with -all. They are
+// written to file_binn.go, and tests checking them against the reflective
+// encoder are written to file_binn_test.go.
+//
+// The generated code honours the binn struct tags and produces exactly
+// what encode.Marshal produces. Fields of types declared in other
+// packages, interfaces and named types with marshaling methods are
+// encoded and decoded by reflection. Unlike encode.Marshal, the generated
+// code doesn't detect cycles.
+//
+// It is typically run by go generate:
+//
+//	//go:generate binngo-gen types.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	all := flag.Bool("all", false, "generate the methods for all the structs of the file")
+	tests := flag.Bool("tests", true, "generate the tests of the methods")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: binngo-gen [flags] file.go\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *all, *tests); err != nil {
+		fmt.Fprintf(os.Stderr, "binngo-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(filename string, all, tests bool) error {
+	p, err := loadPackage(filename, all)
+	if err != nil {
+		return err
+	}
+
+	structs, err := p.structs()
+	if err != nil {
+		return err
+	}
+
+	if len(structs) == 0 {
+		return fmt.Errorf("%s: no structs to generate the methods for", filename)
+	}
+
+	base := strings.TrimSuffix(filename, ".go")
+
+	src, err := generate(p, structs)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(base+"_binn.go", src, 0o644); err != nil { //nolint:gosec
+		return err
+	}
+
+	if !tests {
+		return nil
+	}
+
+	src, err = generateTests(p, structs)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(base+"_binn_test.go", src, 0o644) //nolint:gosec
+}
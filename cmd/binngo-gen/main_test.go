@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateExample checks that the generated files of the example
+// package are up to date. The generated tests of the example package
+// check the generated methods against the reflective encoder.
+func TestGenerateExample(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("internal", "example", "example.go"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "example.go")
+	require.NoError(t, os.WriteFile(filename, src, 0o600))
+
+	err = run(filename, false, true)
+
+	require.NoError(t, err)
+	for _, name := range []string{"example_binn.go", "example_binn_test.go"} {
+		want, err := os.ReadFile(filepath.Join("internal", "example", name))
+		require.NoError(t, err)
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), name)
+	}
+}
+
+func TestGenerateWithoutStructs(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "empty.go")
+	require.NoError(t, os.WriteFile(filename, []byte("package empty\n\ntype T struct{}\n"), 0o600))
+
+	err := run(filename, false, true)
+
+	assert.Error(t, err)
+}
+
+func TestGenerateBlankField(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "blank.go")
+	require.NoError(t, os.WriteFile(filename, []byte("package blank\n\ntype T struct{ _ int }\n"), 0o600))
+
+	err := run(filename, true, true)
+
+	assert.Error(t, err)
+}
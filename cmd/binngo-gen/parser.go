@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+const (
+	generatedHeader = "// Code generated by binngo-gen. DO NOT EDIT."
+	annotation      = "//binngo:gen"
+)
+
+// methods that make the encoding of a type differ from its kind.
+var marshalMethods = map[string]bool{
+	"MarshalBINN":     true,
+	"AppendBINN":      true,
+	"UnmarshalBINN":   true,
+	"MarshalBinary":   true,
+	"UnmarshalBinary": true,
+	"MarshalText":     true,
+	"UnmarshalText":   true,
+}
+
+type typeKind int
+
+const (
+	// kindFallback types are encoded and decoded by reflection.
+	kindFallback typeKind = iota
+	kindBool
+	kindInt
+	kindUint
+	kindFloat32
+	kindFloat64
+	kindString
+	kindSlice
+	kindArray
+	kindPtr
+	// kindObjectMap is a map with string keys, kindIntMap with integer keys.
+	kindObjectMap
+	kindIntMap
+	// kindStruct is a struct the methods are generated for.
+	kindStruct
+)
+
+// typeInfo describes how a type is encoded.
+type typeInfo struct {
+	kind typeKind
+	expr ast.Expr
+
+	// bits is the size of integers, zero for int and uint.
+	bits int
+
+	key  *typeInfo
+	elem *typeInfo
+}
+
+type field struct {
+	name   string
+	key    string
+	mapKey int32
+	typ    *typeInfo
+}
+
+type structType struct {
+	name   string
+	fields []field
+	// mapKeys is set for the structs encoded into maps,
+	// with the fields tagged with map keys.
+	mapKeys bool
+}
+
+// pkg is a parsed package with the types to generate the methods for.
+type pkg struct {
+	fset    *token.FileSet
+	name    string
+	file    *ast.File
+	types   map[string]*ast.TypeSpec
+	methods map[string]bool
+	gen     map[string]bool
+
+	// imports are the packages used by the type expressions
+	// printed in the generated code.
+	imports map[string]bool
+}
+
+// loadPackage parses the package of the file and finds the structs
+// annotated with binngo:gen, or all the structs of the file if all is set.
+// Files generated by binngo-gen are skipped.
+func loadPackage(filename string, all bool) (*pkg, error) {
+	p := &pkg{
+		fset:    token.NewFileSet(),
+		types:   map[string]*ast.TypeSpec{},
+		methods: map[string]bool{},
+		gen:     map[string]bool{},
+		imports: map[string]bool{},
+	}
+
+	absFile, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(absFile), "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Contains(src, []byte(generatedHeader)) {
+			continue
+		}
+
+		f, err := parser.ParseFile(p.fset, path, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		if path == absFile {
+			p.file = f
+			p.name = f.Name.Name
+		}
+
+		p.collect(f, path == absFile, all)
+	}
+
+	if p.file == nil {
+		return nil, fmt.Errorf("%s is not a Go source file of the package", filename)
+	}
+
+	return p, nil
+}
+
+func (p *pkg) collect(f *ast.File, target, all bool) {
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv != nil && marshalMethods[decl.Name.Name] {
+				p.methods[receiverName(decl.Recv.List[0].Type)] = true
+			}
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range decl.Specs {
+				ts := spec.(*ast.TypeSpec)
+				p.types[ts.Name.Name] = ts
+
+				if _, ok := ts.Type.(*ast.StructType); !ok || !target || ts.Assign.IsValid() {
+					continue
+				}
+
+				if all || isAnnotated(decl.Doc) || isAnnotated(ts.Doc) {
+					p.gen[ts.Name.Name] = true
+				}
+			}
+		}
+	}
+}
+
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	if id, ok := expr.(*ast.Ident); ok {
+		return id.Name
+	}
+
+	return ""
+}
+
+func isAnnotated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == annotation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// structs returns the structs to generate the methods for,
+// in the order of their names.
+func (p *pkg) structs() ([]structType, error) {
+	names := make([]string, 0, len(p.gen))
+	for name := range p.gen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	structs := make([]structType, 0, len(names))
+
+	for _, name := range names {
+		st, err := p.structType(name)
+		if err != nil {
+			return nil, err
+		}
+
+		structs = append(structs, st)
+	}
+
+	return structs, nil
+}
+
+// structType resolves the fields of the struct the same way
+// the reflective struct encoder does.
+func (p *pkg) structType(name string) (structType, error) {
+	st := structType{name: name}
+
+	for _, f := range p.types[name].Type.(*ast.StructType).Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return st, err
+			}
+			tag = reflect.StructTag(s)
+		}
+
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		if len(names) == 0 {
+			names = append(names, embeddedName(f.Type))
+		}
+
+		typ := p.resolve(f.Type, map[string]bool{})
+
+		for _, n := range names {
+			if n == "_" || n == "" {
+				return st, fmt.Errorf("%s: blank and unnamed fields are not supported", name)
+			}
+
+			key := strings.Split(tag.Get("binn"), ",")[0]
+			if key == "" {
+				key = n
+			}
+
+			if len(key) > binn.MaxKeySize {
+				return st, fmt.Errorf("%s.%s: key is longer than %d bytes", name, n, binn.MaxKeySize)
+			}
+
+			f := field{name: n, key: key, typ: typ}
+
+			isMapKey := strings.HasPrefix(key, "#")
+			if isMapKey {
+				k, err := strconv.ParseInt(key[1:], 10, 32)
+				if err != nil {
+					return st, fmt.Errorf("%s.%s: map key %q is not an int32", name, n, key)
+				}
+				f.mapKey = int32(k)
+			}
+
+			if len(st.fields) == 0 {
+				st.mapKeys = isMapKey
+			} else if isMapKey != st.mapKeys {
+				return st, fmt.Errorf("%s.%s: struct mixes map key tags and object keys", name, n)
+			}
+
+			st.fields = append(st.fields, f)
+		}
+	}
+
+	return st, nil
+}
+
+func embeddedName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	}
+
+	return ""
+}
+
+var basicKinds = map[string]typeInfo{
+	"bool":    {kind: kindBool},
+	"int":     {kind: kindInt},
+	"int8":    {kind: kindInt, bits: 8},
+	"int16":   {kind: kindInt, bits: 16},
+	"int32":   {kind: kindInt, bits: 32},
+	"rune":    {kind: kindInt, bits: 32},
+	"int64":   {kind: kindInt, bits: 64},
+	"uint":    {kind: kindUint},
+	"uint8":   {kind: kindUint, bits: 8},
+	"byte":    {kind: kindUint, bits: 8},
+	"uint16":  {kind: kindUint, bits: 16},
+	"uint32":  {kind: kindUint, bits: 32},
+	"uint64":  {kind: kindUint, bits: 64},
+	"uintptr": {kind: kindUint, bits: 64},
+	"float32": {kind: kindFloat32},
+	"float64": {kind: kindFloat64},
+	"string":  {kind: kindString},
+}
+
+// resolve describes the type expression. Types declared in other
+// packages, interfaces and named types with marshaling methods
+// fall back to reflection.
+func (p *pkg) resolve(expr ast.Expr, seen map[string]bool) *typeInfo {
+	ti := &typeInfo{expr: expr}
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if p.gen[e.Name] {
+			ti.kind = kindStruct
+			return ti
+		}
+
+		ts, ok := p.types[e.Name]
+		if !ok {
+			if basic, ok := basicKinds[e.Name]; ok {
+				ti.kind, ti.bits = basic.kind, basic.bits
+			}
+			return ti
+		}
+
+		if p.methods[e.Name] || seen[e.Name] {
+			return ti
+		}
+		seen[e.Name] = true
+
+		underlying := p.resolve(ts.Type, seen)
+		if ts.Assign.IsValid() {
+			underlying.expr = expr
+			return underlying
+		}
+
+		// Named scalars are converted to and from their underlying type.
+		switch underlying.kind {
+		case kindBool, kindInt, kindUint, kindFloat32, kindFloat64, kindString:
+			ti.kind, ti.bits = underlying.kind, underlying.bits
+		}
+	case *ast.ParenExpr:
+		return p.resolve(e.X, seen)
+	case *ast.StarExpr:
+		ti.kind = kindPtr
+		ti.elem = p.resolve(e.X, seen)
+	case *ast.ArrayType:
+		ti.kind = kindSlice
+		if e.Len != nil {
+			ti.kind = kindArray
+		}
+		ti.elem = p.resolve(e.Elt, seen)
+	case *ast.MapType:
+		key := p.resolve(e.Key, seen)
+
+		switch {
+		case key.kind == kindString:
+			ti.kind = kindObjectMap
+		case key.kind == kindInt, key.kind == kindUint:
+			ti.kind = kindIntMap
+		default:
+			return ti
+		}
+
+		ti.key = key
+		ti.elem = p.resolve(e.Value, seen)
+	}
+
+	return ti
+}
+
+// typeString prints the type expression and records
+// the packages it refers to.
+func (p *pkg) typeString(expr ast.Expr) string {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				p.imports[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, p.fset, expr)
+
+	return buf.String()
+}
+
+// importSpecs returns the import declarations of the target file
+// for the packages used by the printed type expressions.
+func (p *pkg) importSpecs() []string {
+	var specs []string
+
+	for _, imp := range p.file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+
+		name := importName(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+
+		if p.imports[name] {
+			specs = append(specs, fmt.Sprintf("%s %q", name, path))
+		}
+	}
+
+	return specs
+}
+
+// importName guesses the package name from the import path.
+func importName(path string) string {
+	name := path[strings.LastIndex(path, "/")+1:]
+
+	if i := strings.Index(name, ".v"); i > 0 {
+		if _, err := strconv.Atoi(name[i+2:]); err == nil {
+			name = name[:i]
+		}
+	}
+
+	name = strings.TrimPrefix(name, "go-")
+
+	return strings.ReplaceAll(name, "-", "_")
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// generateTests returns the source of the tests checking that the methods
+// of the structs produce exactly what the reflective encoder produces,
+// and that the encoding survives a round trip through UnmarshalBINN.
+func generateTests(p *pkg, structs []structType) ([]byte, error) {
+	g := &generator{pkg: p}
+
+	usesFill := false
+	for _, st := range structs {
+		g.structTest(st)
+		if g.sampleFunc(st) {
+			usesFill = true
+		}
+	}
+
+	imports := []string{
+		`"bytes"`,
+		`"testing"`,
+		`"github.com/et-nik/binngo/encode"`,
+	}
+
+	if usesFill {
+		g.fillFunc()
+		imports = append(imports, `"encoding"`, `"reflect"`)
+	}
+
+	return formatFile(p.name, imports, g.buf.Bytes())
+}
+
+func (g *generator) structTest(st structType) {
+	plain := "binnPlain" + st.name
+
+	// The methods of embedded structs are promoted to the plain type,
+	// so it isn't encoded by reflection only.
+	compare := true
+	for _, f := range st.fields {
+		if f.typ.kind == kindStruct && f.name == embeddedName(f.typ.expr) {
+			compare = false
+		}
+	}
+
+	if compare {
+		g.p("// %s has the fields of %s without its methods.", plain, st.name)
+		g.p("type %s %s", plain, st.name)
+		g.p("")
+	}
+
+	g.p("func Test%sBINN(t *testing.T) {", st.name)
+	g.p("for _, v := range []%s{{}, binnSample%s(3)} {", st.name, st.name)
+	g.p("got, err := v.MarshalBINN()")
+	g.p("if err != nil {")
+	g.p("t.Fatal(err)")
+	g.p("}")
+	if compare {
+		g.p("want, err := encode.Marshal((*%s)(&v))", plain)
+		g.p("if err != nil {")
+		g.p("t.Fatal(err)")
+		g.p("}")
+		g.p("if !bytes.Equal(got, want) {")
+		g.p("t.Fatalf(%q, got, want)", "MarshalBINN() = % x, want % x")
+		g.p("}")
+	} else {
+		g.p("if _, err := encode.Marshal(v); err != nil {")
+		g.p("t.Fatal(err)")
+		g.p("}")
+	}
+	g.p("")
+	g.p("var u %s", st.name)
+	g.p("if err := u.UnmarshalBINN(got); err != nil {")
+	g.p("t.Fatal(err)")
+	g.p("}")
+	g.p("again, err := u.MarshalBINN()")
+	g.p("if err != nil {")
+	g.p("t.Fatal(err)")
+	g.p("}")
+	g.p("if !bytes.Equal(again, got) {")
+	g.p("t.Fatalf(%q, again, got)", "round trip = % x, want % x")
+	g.p("}")
+	g.p("}")
+	g.p("}")
+	g.p("")
+}
+
+// sampleFunc generates a function returning a value with the fields
+// of the struct set. Maps have a single entry, so the encoding
+// doesn't depend on the map iteration order. The generated structs
+// nested into the value are samples down to the depth, so that
+// recursive types don't recurse forever, and the fields of the types
+// encoded by reflection are set by binnFill. It reports whether
+// binnFill is used.
+func (g *generator) sampleFunc(st structType) bool {
+	var fills []string
+
+	g.p("func binnSample%s(depth int) %s {", st.name, st.name)
+	g.p("if depth == 0 {")
+	g.p("return %s{}", st.name)
+	g.p("}")
+	g.p("")
+	g.p("v := %s{", st.name)
+	for _, f := range st.fields {
+		if s, ok := g.sample(f.typ); ok {
+			g.p("%s: %s,", f.name, s)
+		} else {
+			fills = append(fills, f.name)
+		}
+	}
+	g.p("}")
+	for _, name := range fills {
+		g.p("binnFill(reflect.ValueOf(&v.%s).Elem(), depth)", name)
+	}
+	g.p("")
+	g.p("return v")
+	g.p("}")
+	g.p("")
+
+	return len(fills) > 0
+}
+
+// sample returns a literal of the type, or false for the types encoded
+// by reflection.
+//
+//nolint:gocyclo
+func (g *generator) sample(t *typeInfo) (string, bool) {
+	g.tmp++
+	sign := ""
+	if g.tmp%2 == 0 {
+		sign = "-"
+	}
+
+	switch t.kind {
+	case kindBool:
+		return g.convert(t, "true"), true
+	case kindInt:
+		values := map[int]string{0: "70000", 8: "100", 16: "300", 32: "70000", 64: "1 << 40"}
+		return g.convert(t, sign+values[t.bits]), true
+	case kindUint:
+		values := map[int]string{0: "70000", 8: "200", 16: "300", 32: "1 << 31", 64: "1 << 40"}
+		return g.convert(t, values[t.bits]), true
+	case kindFloat32, kindFloat64:
+		return g.convert(t, sign+"1.5"), true
+	case kindString:
+		return g.convert(t, strconv.Quote("binn"+strconv.Itoa(g.tmp))), true
+	case kindSlice, kindArray:
+		elem, ok := g.sample(t.elem)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%s{%s, %s}", g.typeString(t), elem, elem), true
+	case kindObjectMap, kindIntMap:
+		key, ok := g.sample(t.key)
+		if !ok {
+			return "", false
+		}
+		if t.kind == kindIntMap {
+			// Map keys have to fit into int32.
+			key = g.convert(t.key, "100")
+		}
+		elem, ok := g.sample(t.elem)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%s{%s: %s}", g.typeString(t), key, elem), true
+	case kindPtr:
+		elem, ok := g.sample(t.elem)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("func() *%s { v := %s; return &v }()", g.typeString(t.elem), elem), true
+	case kindStruct:
+		return "binnSample" + g.typeString(t) + "(depth - 1)", true
+	}
+
+	return "", false
+}
+
+// fillFunc generates binnFill, which sets the values of the types
+// encoded by reflection by their kinds. The types with text unmarshaling
+// methods, such as time.Time and net.IP, are set from the first of a few
+// texts they accept as a non-zero value.
+func (g *generator) fillFunc() {
+	g.p("// binnFill sets v to a sample value of its type, down to the depth.")
+	g.p("func binnFill(v reflect.Value, depth int) {")
+	g.p("if depth == 0 || !v.CanSet() {")
+	g.p("return")
+	g.p("}")
+	g.p("")
+	g.p("if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {")
+	g.p("for _, text := range []string{%q, %q, %q} {", "2021-08-01T12:00:00Z", "192.0.2.1", "1")
+	g.p("if u.UnmarshalText([]byte(text)) == nil && !v.IsZero() {")
+	g.p("return")
+	g.p("}")
+	g.p("}")
+	g.p("v.Set(reflect.Zero(v.Type()))")
+	g.p("")
+	g.p("return")
+	g.p("}")
+	g.p("")
+	g.p("switch v.Kind() {")
+	g.p("case reflect.Bool:")
+	g.p("v.SetBool(true)")
+	g.p("case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:")
+	g.p("v.SetInt(-1)")
+	g.p("case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:")
+	g.p("v.SetUint(1)")
+	g.p("case reflect.Float32, reflect.Float64:")
+	g.p("v.SetFloat(1.5)")
+	g.p("case reflect.String:")
+	g.p("v.SetString(%q)", "binn")
+	g.p("case reflect.Slice:")
+	g.p("v.Set(reflect.MakeSlice(v.Type(), 1, 1))")
+	g.p("binnFill(v.Index(0), depth-1)")
+	g.p("case reflect.Array:")
+	g.p("for i := 0; i < v.Len(); i++ {")
+	g.p("binnFill(v.Index(i), depth-1)")
+	g.p("}")
+	g.p("case reflect.Map:")
+	g.p("key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()")
+	g.p("binnFill(key, depth-1)")
+	g.p("binnFill(elem, depth-1)")
+	g.p("v.Set(reflect.MakeMap(v.Type()))")
+	g.p("v.SetMapIndex(key, elem)")
+	g.p("case reflect.Ptr:")
+	g.p("v.Set(reflect.New(v.Type().Elem()))")
+	g.p("binnFill(v.Elem(), depth-1)")
+	g.p("case reflect.Interface:")
+	g.p("if v.NumMethod() == 0 {")
+	g.p("v.Set(reflect.ValueOf(%q))", "binn")
+	g.p("}")
+	g.p("case reflect.Struct:")
+	g.p("for i := 0; i < v.NumField(); i++ {")
+	g.p("binnFill(v.Field(i), depth-1)")
+	g.p("}")
+	g.p("}")
+	g.p("}")
+	g.p("")
+}
+
+func (g *generator) convert(t *typeInfo, literal string) string {
+	typ := g.typeString(t)
+	if strings.ContainsAny(typ, " .") {
+		typ = "(" + typ + ")"
+	}
+
+	return typ + "(" + literal + ")"
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+const (
+	// maxDumpBytes is the number of bytes printed in the hex column.
+	maxDumpBytes = 10
+	// maxDumpString is the number of string bytes printed as the value.
+	maxDumpString = 48
+)
+
+// dump prints a line per item: the offset, the header and value bytes,
+// the key, the type, the storage class and the value.
+//
+//	00000000  e0 80 00 01 01 03                list (container) size=257 count=3
+//	00000006  20 64                              uint8 (byte) 100
+func dump(w io.Writer, b []byte) error {
+	return walk(b, func(it *item) {
+		var desc strings.Builder
+
+		desc.WriteString(strings.Repeat("  ", it.depth))
+
+		switch it.parent {
+		case binn.ObjectType:
+			desc.WriteString(strconv.Quote(string(it.key[1:])))
+			desc.WriteString(": ")
+		case binn.MapType:
+			desc.WriteString(strconv.Itoa(int(int32(binary.BigEndian.Uint32(it.key)))))
+			desc.WriteString(": ")
+		}
+
+		fmt.Fprintf(&desc, "%s (%s)", it.typ, it.storage())
+
+		switch it.storage() {
+		case binn.StorageContainer:
+			fmt.Fprintf(&desc, " size=%d count=%d", it.size, it.count)
+		case binn.StorageString, binn.StorageBlob:
+			fmt.Fprintf(&desc, " size=%d", it.size)
+		}
+
+		if v := value(it); v != "" {
+			desc.WriteByte(' ')
+			desc.WriteString(v)
+		}
+
+		fmt.Fprintf(w, "%08x  %-32s %s\n", it.off, hexBytes(it), desc.String())
+	})
+}
+
+func hexBytes(it *item) string {
+	b := make([]byte, 0, maxDumpBytes+len(it.header))
+	b = append(b, it.header...)
+
+	data := it.data
+	if len(data) > maxDumpBytes {
+		data = data[:maxDumpBytes]
+	}
+	b = append(b, data...)
+
+	more := len(it.header)+len(it.data) > maxDumpBytes
+	if more {
+		b = b[:maxDumpBytes]
+	}
+
+	var s strings.Builder
+	for i, c := range b {
+		if i > 0 {
+			s.WriteByte(' ')
+		}
+		fmt.Fprintf(&s, "%02x", c)
+	}
+
+	if more {
+		s.WriteString(" ..")
+	}
+
+	return s.String()
+}
+
+// value formats the value of the scalar items.
+func value(it *item) string {
+	d := it.data
+
+	switch it.typ {
+	case binn.Uint8Type:
+		return strconv.FormatUint(uint64(d[0]), 10)
+	case binn.Int8Type:
+		return strconv.Itoa(int(int8(d[0])))
+	case binn.Uint16Type:
+		return strconv.FormatUint(uint64(binary.BigEndian.Uint16(d)), 10)
+	case binn.Int16Type:
+		return strconv.Itoa(int(int16(binary.BigEndian.Uint16(d))))
+	case binn.Uint32Type:
+		return strconv.FormatUint(uint64(binary.BigEndian.Uint32(d)), 10)
+	case binn.Int32Type:
+		return strconv.Itoa(int(int32(binary.BigEndian.Uint32(d))))
+	case binn.Uint64Type:
+		return strconv.FormatUint(binary.BigEndian.Uint64(d), 10)
+	case binn.Int64Type, binn.CurrencyType:
+		return strconv.FormatInt(int64(binary.BigEndian.Uint64(d)), 10)
+	case binn.Float32Type:
+		return strconv.FormatFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(d))), 'g', -1, 32)
+	case binn.Float64Type:
+		return strconv.FormatFloat(math.Float64frombits(binary.BigEndian.Uint64(d)), 'g', -1, 64)
+	}
+
+	if it.storage() == binn.StorageString {
+		s := d[:len(d)-1]
+		if len(s) > maxDumpString {
+			return strconv.Quote(string(s[:maxDumpString])) + ".."
+		}
+
+		return strconv.Quote(string(s))
+	}
+
+	return ""
+}
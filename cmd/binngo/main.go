@@ -0,0 +1,166 @@
+// Command binngo inspects and converts BINN data.
+//
+// Usage:
+//
+//	binngo dump [file ...]
+//	binngo tojson [file ...]
+//	binngo fromjson [-float32] [-no-envelopes] [file ...]
+//	binngo validate [file ...]
+//	binngo stats [file ...]
+//
+// The commands read the files, or the standard input if there are none
+// or a file is "-". The input may hold several items back to back.
+//
+// Dump prints each item on its own line, with its offset, its header
+// and value bytes, its type, storage class, size and value. Tojson and
+// fromjson convert the items to JSON values and back, using the shapes
+// described in the binnjson package. Validate checks that the input
+// consists of complete, well-formed items. Stats prints the number of
+// items of each type and how the bytes split between headers, keys and
+// values.
+//
+// Binngo exits with status 1 if the input is malformed and 2 on
+// usage errors.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/et-nik/binngo/binnjson"
+)
+
+const usage = `usage: binngo <command> [flags] [file ...]
+
+commands:
+  dump      print the items with their offsets, types and sizes
+  tojson    convert BINN to JSON
+  fromjson  convert JSON to BINN
+  validate  check that the input is well-formed
+  stats     print type histograms and size breakdowns
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+var errUsage = errors.New("usage")
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	err := command(args[0], args[1:], stdin, stdout, stderr)
+
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprint(stderr, usage)
+		return 2
+	case errors.Is(err, flag.ErrHelp):
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "binngo %s: %v\n", args[0], err)
+		return 1
+	}
+
+	return 0
+}
+
+func command(name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("binngo "+name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var opts binnjson.Options
+	if name == "fromjson" {
+		flags.BoolVar(&opts.Float32, "float32", false, "store the fractional numbers as float32")
+		flags.BoolVar(&opts.NoEnvelopes, "no-envelopes", false, "read the envelope objects as plain objects")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var process func(r io.Reader) error
+
+	switch name {
+	case "dump":
+		process = func(r io.Reader) error {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+
+			return dump(stdout, b)
+		}
+	case "tojson":
+		process = func(r io.Reader) error {
+			return binnjson.ToJSON(stdout, r)
+		}
+	case "fromjson":
+		process = func(r io.Reader) error {
+			return binnjson.FromJSON(stdout, r, &opts)
+		}
+	case "validate":
+		process = func(r io.Reader) error {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+
+			return walk(b, func(*item) {})
+		}
+	case "stats":
+		process = func(r io.Reader) error {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+
+			s, err := collectStats(b)
+			if err != nil {
+				return err
+			}
+
+			printStats(stdout, s)
+
+			return nil
+		}
+	default:
+		return errUsage
+	}
+
+	return forEachInput(flags.Args(), stdin, process)
+}
+
+// forEachInput calls process with the files, or with stdin if there are none.
+func forEachInput(files []string, stdin io.Reader, process func(r io.Reader) error) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, file := range files {
+		if file == "-" {
+			if err := process(stdin); err != nil {
+				return fmt.Errorf("<stdin>: %w", err)
+			}
+			continue
+		}
+
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		if err := process(bytes.NewReader(b)); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return nil
+}
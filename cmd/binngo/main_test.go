@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var readDir = filepath.Join("..", "..", "test", "binary", "read-dir.bin")
+
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestDump(t *testing.T) {
+	code, out, _ := runCommand(t, "", "dump", readDir)
+
+	require.Equal(t, 0, code)
+	lines := strings.Split(out, "\n")
+	assert.Equal(t, "00000000  e0 80 00 01 01 03                list (container) size=257 count=3", lines[0])
+	assert.Equal(t, `00000014  a0 09 64 69 72 65 63 74 6f 72 ..       string (string) size=9 "directory"`, lines[5])
+}
+
+func TestDumpKeys(t *testing.T) {
+	code, out, _ := runCommand(t, "\xe2\x0d\x01\x01a\xe1\x08\x01\x00\x00\x00\x07\x01", "dump")
+
+	require.Equal(t, 0, code)
+	assert.Equal(t, "00000000  e2 0d 01                         object (container) size=13 count=1\n"+
+		"00000005  e1 08 01                           \"a\": map (container) size=8 count=1\n"+
+		"0000000c  01                                   7: true (nobytes)\n", out)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  int
+	}{
+		{"valid", "\x20\x01\xa0\x01a\x00", 0},
+		{"empty", "", 0},
+		{"truncated", "\xe0\x05\x02\x01", 1},
+		{"size mismatch", "\xe2\x08\x01\x01a\x20\x01\x00", 1},
+		{"unterminated string", "\xa0\x01ab", 1},
+		{"unknown container", "\xe5\x03\x00", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, _, _ := runCommand(t, test.input, "validate")
+			assert.Equal(t, test.code, code)
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	code, out, _ := runCommand(t, "", "stats", readDir)
+
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "items      52\n")
+	assert.Contains(t, out, "string       string            9        118\n")
+}
+
+func TestJSON(t *testing.T) {
+	code, out, _ := runCommand(t, `{"a":[1,{"$blob":"AAE="}]}`, "fromjson")
+	require.Equal(t, 0, code)
+
+	code, out, _ = runCommand(t, out, "tojson")
+	require.Equal(t, 0, code)
+	assert.Equal(t, `{"a":[1,{"$blob":"AAE="}]}`+"\n", out)
+
+	code, out, _ = runCommand(t, "1.5", "fromjson", "-float32")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "\x62\x3f\xc0\x00\x00", out)
+}
+
+func TestErrors(t *testing.T) {
+	code, _, _ := runCommand(t, "")
+	assert.Equal(t, 2, code)
+
+	code, _, _ = runCommand(t, "", "unknown")
+	assert.Equal(t, 2, code)
+
+	code, _, stderr := runCommand(t, "", "dump", "missing.bin")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "missing.bin")
+
+	code, _, _ = runCommand(t, "[1,", "fromjson")
+	assert.Equal(t, 1, code)
+
+	code, _, _ = runCommand(t, "\xe0\x05\x02\x01", "tojson")
+	assert.Equal(t, 1, code)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+// typeStats are the number of items of a type and the bytes they take,
+// not counting the items of containers.
+type typeStats struct {
+	typ   binn.Type
+	items int
+	bytes int
+}
+
+type stats struct {
+	items    int
+	maxDepth int
+
+	total  int
+	header int
+	keys   int
+	data   int
+
+	types map[binn.Type]*typeStats
+}
+
+func collectStats(b []byte) (*stats, error) {
+	s := &stats{total: len(b), types: map[binn.Type]*typeStats{}}
+
+	err := walk(b, func(it *item) {
+		s.items++
+		if it.depth > s.maxDepth {
+			s.maxDepth = it.depth
+		}
+
+		s.header += len(it.header)
+		s.keys += len(it.key)
+		s.data += len(it.data)
+
+		ts, ok := s.types[it.typ]
+		if !ok {
+			ts = &typeStats{typ: it.typ}
+			s.types[it.typ] = ts
+		}
+		ts.items++
+		ts.bytes += len(it.header) + len(it.data)
+	})
+
+	return s, err
+}
+
+// printStats prints the totals followed by a histogram of the types,
+// the types with the most items first.
+func printStats(w io.Writer, s *stats) {
+	fmt.Fprintf(w, "items      %d\n", s.items)
+	fmt.Fprintf(w, "max depth  %d\n", s.maxDepth)
+	fmt.Fprintf(w, "bytes      %d\n", s.total)
+	fmt.Fprintf(w, "  headers  %d (%s)\n", s.header, percent(s.header, s.total))
+	fmt.Fprintf(w, "  keys     %d (%s)\n", s.keys, percent(s.keys, s.total))
+	fmt.Fprintf(w, "  values   %d (%s)\n", s.data, percent(s.data, s.total))
+
+	types := make([]*typeStats, 0, len(s.types))
+	for _, ts := range s.types {
+		types = append(types, ts)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].items != types[j].items {
+			return types[i].items > types[j].items
+		}
+		return types[i].typ < types[j].typ
+	})
+
+	fmt.Fprintf(w, "\n%-12s %-10s %8s %10s\n", "type", "storage", "items", "bytes")
+	for _, ts := range types {
+		fmt.Fprintf(w, "%-12s %-10s %8d %10d\n",
+			ts.typ, ts.typ.Storage(), ts.items, ts.bytes)
+	}
+}
+
+func percent(n, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+var errMalformed = errors.New("malformed input")
+
+// item is an item found by walk. The byte slices refer to the input.
+type item struct {
+	off   int
+	depth int
+	typ   binn.Type
+
+	// parent is the type of the container holding the item,
+	// or -1 at the top level.
+	parent binn.Type
+	// key is the encoded object or map key preceding the item, if any.
+	key []byte
+	// header holds the type, the size and the count bytes.
+	header []byte
+	// data is the value, including the null terminator of strings.
+	// It's empty for containers.
+	data []byte
+
+	// size is the value of the size header of strings, blobs
+	// and containers, and count is the items count of containers.
+	size  int
+	count int
+}
+
+// storage returns the storage class of the item.
+func (it *item) storage() binn.Storage {
+	return it.typ.Storage()
+}
+
+// walk visits the items of b, which may hold several items back
+// to back, parents before their children. Unlike the decoder,
+// walk requires container sizes to match their items exactly.
+func walk(b []byte, visit func(it *item)) error {
+	for off := 0; off < len(b); {
+		n, err := walkItem(b, off, 0, -1, nil, visit)
+		if err != nil {
+			return err
+		}
+		off += n
+	}
+
+	return nil
+}
+
+//nolint:funlen
+func walkItem(b []byte, off, depth int, parent binn.Type, key []byte, visit func(it *item)) (int, error) {
+	it := &item{off: off, depth: depth, parent: parent, key: key}
+
+	p := off
+	if p >= len(b) {
+		return 0, fmt.Errorf("%w: missing type at offset %#x", errMalformed, p)
+	}
+
+	it.typ = binn.Type(b[p])
+	p++
+
+	if it.typ&binn.StorageHasMore != 0 {
+		if p >= len(b) {
+			return 0, fmt.Errorf("%w: truncated type at offset %#x", errMalformed, off)
+		}
+		it.typ = it.typ<<8 | binn.Type(b[p])
+		p++
+	}
+
+	var dataLen int
+
+	switch int(b[off]) & binn.StorageMask {
+	case binn.StorageNoBytes:
+	case binn.StorageByte:
+		dataLen = 1
+	case binn.StorageWord:
+		dataLen = 2
+	case binn.StorageDWord:
+		dataLen = 4
+	case binn.StorageQWord:
+		dataLen = 8
+	case binn.StorageString, binn.StorageBlob:
+		sz, n, err := sizeAt(b, p)
+		if err != nil {
+			return 0, err
+		}
+		p += n
+		it.size, dataLen = sz, sz
+
+		if int(b[off])&binn.StorageMask == binn.StorageString {
+			dataLen++
+			if p+sz < len(b) && b[p+sz] != 0 {
+				return 0, fmt.Errorf("%w: unterminated string at offset %#x", errMalformed, off)
+			}
+		}
+	case binn.StorageContainer:
+		return walkContainer(b, it, p, visit)
+	}
+
+	if p+dataLen > len(b) {
+		return 0, fmt.Errorf("%w: truncated value at offset %#x", errMalformed, off)
+	}
+
+	it.header = b[off:p]
+	it.data = b[p : p+dataLen]
+	visit(it)
+
+	return p + dataLen - off, nil
+}
+
+func walkContainer(b []byte, it *item, p int, visit func(it *item)) (int, error) {
+	off := it.off
+
+	switch it.typ {
+	case binn.ListType, binn.MapType, binn.ObjectType:
+	default:
+		return 0, fmt.Errorf("%w: unknown container type %s at offset %#x", errMalformed, it.typ, off)
+	}
+
+	size, n, err := sizeAt(b, p)
+	if err != nil {
+		return 0, err
+	}
+	p += n
+
+	end := off + size
+	if end > len(b) {
+		return 0, fmt.Errorf("%w: container at offset %#x exceeds the input", errMalformed, off)
+	}
+
+	count, n, err := sizeAt(b[:end], p)
+	if err != nil {
+		return 0, err
+	}
+	p += n
+
+	it.header = b[off:p]
+	it.size, it.count = size, count
+	visit(it)
+
+	for i := 0; i < count; i++ {
+		keyStart := p
+
+		switch it.typ {
+		case binn.MapType:
+			p += 4
+		case binn.ObjectType:
+			if p < end {
+				p += int(b[p]) + 1
+			} else {
+				p++
+			}
+		}
+
+		if p > end {
+			return 0, fmt.Errorf("%w: truncated key at offset %#x", errMalformed, keyStart)
+		}
+
+		n, err := walkItem(b[:end], p, it.depth+1, it.typ, b[keyStart:p], visit)
+		if err != nil {
+			return 0, err
+		}
+		p += n
+	}
+
+	if p != end {
+		return 0, fmt.Errorf("%w: container at offset %#x has size %d, its items end at %d",
+			errMalformed, off, size, p-off)
+	}
+
+	return size, nil
+}
+
+// sizeAt reads a 1 or 4 bytes size at the offset off.
+func sizeAt(b []byte, off int) (int, int, error) {
+	if off >= len(b) {
+		return 0, 0, fmt.Errorf("%w: missing size at offset %#x", errMalformed, off)
+	}
+
+	if b[off]&0x80 == 0 {
+		return int(b[off]), 1, nil
+	}
+
+	if off+4 > len(b) {
+		return 0, 0, fmt.Errorf("%w: truncated size at offset %#x", errMalformed, off)
+	}
+
+	return int(binary.BigEndian.Uint32(b[off:]) & 0x7FFFFFFF), 4, nil
+}
@@ -0,0 +1,85 @@
+package binngo
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+)
+
+// ErrUnsupportedScan is returned by Column.Scan and ColumnOf.Scan for
+// the column values other than bytes and strings.
+var ErrUnsupportedScan = errors.New("binngo: unsupported scan")
+
+// Column stores a value as BINN in a database column, such as a BLOB.
+// It implements sql.Scanner and driver.Valuer:
+//
+//	db.Exec("INSERT INTO settings (data) VALUES (?)", binngo.Column{V: settings})
+//	db.QueryRow("SELECT data FROM settings").Scan(&binngo.Column{V: &settings})
+type Column struct {
+	// V is the value to store. To scan a column, V has to point to the
+	// value to decode the column into, or be nil to decode the column
+	// into a new value with the types of Unmarshal into interface{}.
+	V interface{}
+}
+
+// Value returns the BINN encoding of V, or nil, the SQL NULL,
+// if V is nil or a nil pointer.
+func (c Column) Value() (driver.Value, error) {
+	if isNil(c.V) {
+		return nil, nil
+	}
+
+	return encode.Marshal(c.V)
+}
+
+// Scan decodes the BINN encoding of a []byte or a string into V.
+// A NULL sets the value V points to to its zero value, or V to nil
+// if it's not a pointer.
+func (c *Column) Scan(src interface{}) error {
+	if src == nil {
+		if rv := reflect.ValueOf(c.V); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+		} else {
+			c.V = nil
+		}
+
+		return nil
+	}
+
+	data, err := columnBytes(src)
+	if err != nil {
+		return err
+	}
+
+	if c.V == nil {
+		return decode.Unmarshal(data, &c.V)
+	}
+
+	return decode.Unmarshal(data, c.V)
+}
+
+// columnBytes returns the bytes of a column value scanned from a database.
+func columnBytes(src interface{}) ([]byte, error) {
+	switch src := src.(type) {
+	case []byte:
+		return src, nil
+	case string:
+		return []byte(src), nil
+	}
+
+	return nil, fmt.Errorf("%w: can't scan %T into a column", ErrUnsupportedScan, src)
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
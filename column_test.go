@@ -0,0 +1,174 @@
+package binngo_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/et-nik/binngo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDB is a database of a single BLOB column. It supports the queries
+// "INSERT" with a value, "SELECT" returning the stored values as they were
+// inserted and "SELECT TEXT" returning the []byte values as strings.
+type fakeDB struct {
+	mu     sync.Mutex
+	values []driver.Value
+}
+
+func openFakeDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db := sql.OpenDB(&fakeDB{})
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error { return nil }
+
+func (s fakeStmt) NumInput() int {
+	if s.query == "INSERT" {
+		return 1
+	}
+	return 0
+}
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	v := args[0]
+	if b, ok := v.([]byte); ok {
+		v = append([]byte(nil), b...)
+	}
+	s.db.values = append(s.db.values, v)
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return &fakeRows{values: append([]driver.Value(nil), s.db.values...), text: s.query == "SELECT TEXT"}, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+	text   bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"data"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	dest[0] = r.values[0]
+	if b, ok := dest[0].([]byte); ok && r.text {
+		dest[0] = string(b)
+	}
+	r.values = r.values[1:]
+
+	return nil
+}
+
+type settings struct {
+	Theme string   `binn:"theme"`
+	Size  int      `binn:"size"`
+	Tags  []string `binn:"tags"`
+}
+
+func scanAll(t *testing.T, db *sql.DB, query string, newDest func() interface{}) []interface{} {
+	t.Helper()
+
+	rows, err := db.Query(query)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var result []interface{}
+	for rows.Next() {
+		dest := newDest()
+		require.NoError(t, rows.Scan(dest))
+		result = append(result, dest)
+	}
+	require.NoError(t, rows.Err())
+
+	return result
+}
+
+func TestColumn(t *testing.T) {
+	db := openFakeDB(t)
+	s := settings{"dark", 12, []string{"a", "b"}}
+
+	_, err := db.Exec("INSERT", binngo.Column{V: s})
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT", binngo.Column{V: nil})
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT", binngo.Column{V: (*settings)(nil)})
+	require.NoError(t, err)
+
+	for _, query := range []string{"SELECT", "SELECT TEXT"} {
+		t.Run(query, func(t *testing.T) {
+			rows := scanAll(t, db, query, func() interface{} {
+				return &binngo.Column{V: &settings{Theme: "light"}}
+			})
+
+			require.Len(t, rows, 3)
+			assert.Equal(t, &s, rows[0].(*binngo.Column).V)
+			assert.Equal(t, &settings{}, rows[1].(*binngo.Column).V)
+			assert.Equal(t, &settings{}, rows[2].(*binngo.Column).V)
+		})
+	}
+}
+
+func TestColumnInterface(t *testing.T) {
+	db := openFakeDB(t)
+
+	_, err := db.Exec("INSERT", binngo.Column{V: map[string]int{"a": 1}})
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT", nil)
+	require.NoError(t, err)
+
+	rows := scanAll(t, db, "SELECT", func() interface{} {
+		return &binngo.Column{}
+	})
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, map[string]interface{}{"a": uint8(1)}, rows[0].(*binngo.Column).V)
+	assert.Nil(t, rows[1].(*binngo.Column).V)
+}
+
+func TestColumnScanErrors(t *testing.T) {
+	var s settings
+
+	err := (&binngo.Column{V: &s}).Scan(int64(1))
+	assert.ErrorIs(t, err, binngo.ErrUnsupportedScan)
+	assert.EqualError(t, err, "binngo: unsupported scan: can't scan int64 into a column")
+
+	err = (&binngo.Column{V: &s}).Scan([]byte{0xE2, 0x05})
+	assert.Error(t, err)
+}
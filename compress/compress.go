@@ -0,0 +1,151 @@
+// Package compress implements compressed BINN streams.
+//
+// A compressed stream starts with a header: the magic bytes 0xFF 'B' 'N'
+// 'Z' and the algorithm byte, followed by the compressed items. The magic
+// bytes read as the header of a container of the user type 0xFF42 with
+// the size 78 and 90 items, which no well-formed item has, as every item
+// takes at least one byte. So a Reader tells the compressed streams from
+// the raw ones and reads both transparently.
+//
+// Gzip, Flate and Zlib are registered by default, and other algorithms
+// can be added with Register.
+package compress
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// magic starts the header of a compressed stream.
+const magic = "\xffBNZ"
+
+// headerSize is the size of the header: the magic bytes and the algorithm.
+const headerSize = len(magic) + 1
+
+// Algorithm identifies a compression algorithm in the stream header.
+type Algorithm byte
+
+// The algorithms registered by default. The values up to 127 are reserved
+// for this package, the other algorithms have to use the values from 128.
+const (
+	Gzip  Algorithm = 1
+	Flate Algorithm = 2
+	Zlib  Algorithm = 3
+)
+
+// minCustomAlgorithm is the lowest algorithm Register accepts.
+const minCustomAlgorithm Algorithm = 128
+
+// ErrUnknownAlgorithm is returned for algorithms that aren't registered.
+var ErrUnknownAlgorithm = errors.New("compress: unknown algorithm")
+
+// A Compressor is a writer of compressed data. Flush writes the data
+// written so far to the underlying writer, and Close finishes the stream
+// without closing the underlying writer.
+type Compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// NewCompressor returns a Compressor writing to w.
+type NewCompressor func(w io.Writer) (Compressor, error)
+
+// NewDecompressor returns a reader decompressing the data of r. It has to
+// stop reading at the end of the compressed stream, so a reader of
+// concatenated streams can read the header of the next one. The r passed
+// by this package implements io.ByteReader for this purpose.
+type NewDecompressor func(r io.Reader) (io.Reader, error)
+
+type algorithm struct {
+	name            string
+	newCompressor   NewCompressor
+	newDecompressor NewDecompressor
+}
+
+var (
+	algorithmsMu sync.RWMutex
+	algorithms   = map[Algorithm]algorithm{
+		Gzip:  {"gzip", newGzipWriter, newGzipReader},
+		Flate: {"flate", newFlateWriter, newFlateReader},
+		Zlib:  {"zlib", newZlibWriter, newZlibReader},
+	}
+)
+
+func newGzipWriter(w io.Writer) (Compressor, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func newGzipReader(r io.Reader) (io.Reader, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	zr.Multistream(false)
+
+	return zr, nil
+}
+
+func newFlateWriter(w io.Writer) (Compressor, error) {
+	return flate.NewWriter(w, flate.DefaultCompression)
+}
+
+func newFlateReader(r io.Reader) (io.Reader, error) {
+	return flate.NewReader(r), nil
+}
+
+func newZlibWriter(w io.Writer) (Compressor, error) {
+	return zlib.NewWriter(w), nil
+}
+
+func newZlibReader(r io.Reader) (io.Reader, error) {
+	return zlib.NewReader(r)
+}
+
+// Register makes a compression algorithm available to NewWriter and
+// Reader. If the algorithm is reserved, lower than 128, if Register is
+// called twice with the same algorithm, or if a function is nil,
+// it panics.
+func Register(alg Algorithm, name string, c NewCompressor, d NewDecompressor) {
+	algorithmsMu.Lock()
+	defer algorithmsMu.Unlock()
+
+	if c == nil || d == nil {
+		panic("compress: Register function is nil")
+	}
+	if alg < minCustomAlgorithm {
+		panic("compress: Register called with the reserved algorithm " + strconv.Itoa(int(alg)))
+	}
+	if _, dup := algorithms[alg]; dup {
+		panic("compress: Register called twice for algorithm " + name)
+	}
+
+	algorithms[alg] = algorithm{name, c, d}
+}
+
+func lookup(alg Algorithm) (algorithm, error) {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+
+	a, ok := algorithms[alg]
+	if !ok {
+		return a, fmt.Errorf("%w %d", ErrUnknownAlgorithm, alg)
+	}
+
+	return a, nil
+}
+
+// String returns the name the algorithm is registered with.
+func (alg Algorithm) String() string {
+	a, err := lookup(alg)
+	if err != nil {
+		return fmt.Sprintf("Algorithm(%d)", byte(alg))
+	}
+
+	return a.name
+}
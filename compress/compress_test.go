@@ -0,0 +1,262 @@
+package compress_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/et-nik/binngo"
+	"github.com/et-nik/binngo/compress"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID   int    `binn:"id"`
+	Text string `binn:"text"`
+}
+
+func records(n int) []record {
+	r := make([]record, n)
+	for i := range r {
+		r[i] = record{i, strings.Repeat("repetitive ", 10)}
+	}
+
+	return r
+}
+
+func encodeRecords(t *testing.T, w io.Writer, recs []record) {
+	t.Helper()
+
+	enc := encode.NewEncoder(w)
+	for _, r := range recs {
+		require.NoError(t, enc.Encode(r))
+	}
+}
+
+func decodeRecords(t *testing.T, dec *decode.Decoder) ([]record, error) {
+	t.Helper()
+
+	var recs []record
+	for {
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			return recs, err
+		}
+		recs = append(recs, r)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	recs := records(100)
+
+	var raw bytes.Buffer
+	encodeRecords(t, &raw, recs)
+
+	for _, alg := range []compress.Algorithm{compress.Gzip, compress.Flate, compress.Zlib} {
+		t.Run(alg.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := compress.NewWriter(&buf, alg)
+			require.NoError(t, err)
+			encodeRecords(t, w, recs)
+			require.NoError(t, w.Close())
+
+			assert.Less(t, buf.Len(), raw.Len()/5)
+			assert.Equal(t, append([]byte("\xffBNZ"), byte(alg)), buf.Bytes()[:5])
+
+			got, err := decodeRecords(t, binngo.NewDecoder(&buf))
+
+			assert.Equal(t, io.EOF, err)
+			assert.Equal(t, recs, got)
+		})
+	}
+}
+
+func TestRaw(t *testing.T) {
+	recs := records(3)
+	var buf bytes.Buffer
+	encodeRecords(t, &buf, recs)
+
+	got, err := decodeRecords(t, binngo.NewDecoder(&buf))
+
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, recs, got)
+}
+
+func TestEmpty(t *testing.T) {
+	var v interface{}
+
+	err := binngo.NewDecoder(bytes.NewReader(nil)).Decode(&v)
+
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestShortRaw(t *testing.T) {
+	var v interface{}
+
+	err := binngo.NewDecoder(bytes.NewReader([]byte{0x20, 0x01})).Decode(&v)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), v)
+}
+
+func TestRawStreamIsNotBuffered(t *testing.T) {
+	var buf bytes.Buffer
+	enc := encode.NewEncoder(&buf)
+	require.NoError(t, enc.Encode(uint8(1)))
+	require.NoError(t, enc.Encode(uint8(2)))
+	require.NoError(t, enc.Encode(uint8(3)))
+	r := bytes.NewReader(buf.Bytes())
+
+	var v uint8
+	dec := binngo.NewDecoder(r)
+	require.NoError(t, dec.Decode(&v))
+
+	assert.Equal(t, uint8(1), v)
+	assert.Equal(t, int64(2), dec.InputOffset())
+	// The items after the first one are left in r.
+	assert.Equal(t, 4, r.Len())
+}
+
+func TestConcatenatedStreams(t *testing.T) {
+	recs := records(6)
+	var buf bytes.Buffer
+
+	for i, alg := range []compress.Algorithm{compress.Gzip, compress.Flate, compress.Zlib} {
+		w, err := compress.NewWriter(&buf, alg)
+		require.NoError(t, err)
+		encodeRecords(t, w, recs[i*2:i*2+2])
+		require.NoError(t, w.Close())
+	}
+
+	got, err := decodeRecords(t, binngo.NewDecoder(&buf))
+
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, recs, got)
+}
+
+func TestFlushPerRecord(t *testing.T) {
+	pr, pw := io.Pipe()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		w, err := compress.NewWriter(pw, compress.Gzip)
+		if err != nil {
+			return
+		}
+		w.SetAutoFlush(true)
+		enc := encode.NewEncoder(w)
+		for _, r := range records(3) {
+			if enc.Encode(r) != nil {
+				return
+			}
+		}
+		// The writer stays open, like a log being written.
+		<-done
+	}()
+
+	dec := binngo.NewDecoder(pr)
+	for i := 0; i < 3; i++ {
+		var r record
+		require.NoError(t, dec.Decode(&r))
+		assert.Equal(t, i, r.ID)
+	}
+}
+
+func TestAutoFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := compress.NewWriter(&buf, compress.Flate)
+	require.NoError(t, err)
+	w.SetAutoFlush(true)
+
+	for i, r := range records(3) {
+		encodeRecords(t, w, []record{r})
+
+		got, _ := decodeRecords(t, binngo.NewDecoder(bytes.NewReader(buf.Bytes())))
+		assert.Len(t, got, i+1)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := compress.NewWriter(&buf, compress.Flate)
+	require.NoError(t, err)
+	encodeRecords(t, w, records(2))
+	require.NoError(t, w.Flush())
+
+	got, err := decodeRecords(t, binngo.NewDecoder(&buf))
+
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Len(t, got, 2)
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	_, err := compress.NewWriter(io.Discard, 250)
+	assert.ErrorIs(t, err, compress.ErrUnknownAlgorithm)
+
+	var v interface{}
+	err = binngo.NewDecoder(strings.NewReader("\xffBNZ\xfa")).Decode(&v)
+	assert.ErrorIs(t, err, compress.ErrUnknownAlgorithm)
+}
+
+// xorCompressor is a toy algorithm flipping the bits of the data.
+// Its streams run to the end of the input.
+type xorCompressor struct {
+	w io.Writer
+}
+
+func (c xorCompressor) Write(p []byte) (int, error) {
+	b := make([]byte, len(p))
+	for i := range p {
+		b[i] = ^p[i]
+	}
+	return c.w.Write(b)
+}
+
+func (c xorCompressor) Flush() error { return nil }
+func (c xorCompressor) Close() error { return nil }
+
+func TestRegister(t *testing.T) {
+	const xor compress.Algorithm = 200
+	compress.Register(xor, "xor", func(w io.Writer) (compress.Compressor, error) {
+		return xorCompressor{w}, nil
+	}, func(r io.Reader) (io.Reader, error) {
+		b, err := io.ReadAll(r)
+		for i := range b {
+			b[i] = ^b[i]
+		}
+		return bytes.NewReader(b), err
+	})
+
+	assert.Equal(t, "xor", xor.String())
+	assert.Panics(t, func() {
+		compress.Register(xor, "xor", nil, nil)
+	})
+	assert.Panics(t, func() {
+		compress.Register(xor, "xor", func(w io.Writer) (compress.Compressor, error) {
+			return xorCompressor{w}, nil
+		}, func(r io.Reader) (io.Reader, error) { return r, nil })
+	})
+	assert.Panics(t, func() {
+		compress.Register(127, "reserved", func(w io.Writer) (compress.Compressor, error) {
+			return xorCompressor{w}, nil
+		}, func(r io.Reader) (io.Reader, error) { return r, nil })
+	})
+	assert.Equal(t, "gzip", compress.Gzip.String())
+
+	recs := records(3)
+	var buf bytes.Buffer
+	w, err := compress.NewWriter(&buf, xor)
+	require.NoError(t, err)
+	encodeRecords(t, w, recs)
+	require.NoError(t, w.Close())
+
+	got, err := decodeRecords(t, binngo.NewDecoder(&buf))
+
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, recs, got)
+}
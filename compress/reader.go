@@ -0,0 +1,138 @@
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// A Reader reads the BINN items of a stream, decompressing the compressed
+// parts. It reads raw streams unchanged, and compressed streams written
+// back to back, such as a file appended to by several Writers.
+//
+// A raw stream is read without buffering: the bytes read to detect it
+// are replayed, and the rest is read from the underlying reader as
+// requested. The decompressors read ahead, so a compressed stream and
+// everything after it are read through a bufio.Reader, which reads past
+// the items returned so far.
+type Reader struct {
+	r io.Reader
+	// br buffers the underlying reader once a compressed stream is found.
+	br  *bufio.Reader
+	cur io.Reader
+	raw bool
+}
+
+// NewReader returns a Reader reading from r. The stream is detected
+// on the first Read.
+func NewReader(r io.Reader) *Reader {
+	br, _ := r.(*bufio.Reader)
+
+	return &Reader{r: r, br: br}
+}
+
+// Read reads the decompressed data.
+func (r *Reader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if err := r.next(); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := r.cur.Read(p)
+		if err == io.EOF && !r.raw {
+			// The compressed stream is over, another one may follow.
+			r.cur = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+// next detects the stream at the current position.
+func (r *Reader) next() error {
+	if r.br == nil {
+		return r.first()
+	}
+
+	header, err := r.br.Peek(headerSize)
+	if len(header) == 0 {
+		return err
+	}
+
+	if len(header) < headerSize || !bytes.HasPrefix(header, []byte(magic)) {
+		r.cur, r.raw = r.br, true
+		return nil
+	}
+
+	if _, err := r.br.Discard(headerSize); err != nil {
+		return err
+	}
+
+	return r.decompress(Algorithm(header[len(magic)]))
+}
+
+// first detects the first stream of an unbuffered reader. It reads
+// the header a byte at a time while it matches the magic, and replays
+// the bytes read if the stream is raw. Every item starting like
+// the magic is longer than the bytes matching it, so the bytes read
+// from a raw stream never go past its first item.
+func (r *Reader) first() error {
+	header := make([]byte, 0, headerSize)
+
+	var b [1]byte
+	for len(header) < headerSize {
+		if _, err := io.ReadFull(r.r, b[:]); err != nil {
+			if err == io.EOF && len(header) > 0 {
+				break
+			}
+
+			return err
+		}
+		header = append(header, b[0])
+
+		if len(header) <= len(magic) && b[0] != magic[len(header)-1] {
+			break
+		}
+	}
+
+	if len(header) < headerSize || !bytes.HasPrefix(header, []byte(magic)) {
+		r.cur, r.raw = io.MultiReader(bytes.NewReader(header), r.r), true
+		return nil
+	}
+
+	r.br = bufio.NewReader(r.r)
+
+	return r.decompress(Algorithm(header[len(magic)]))
+}
+
+// decompress starts reading the compressed stream after the header.
+func (r *Reader) decompress(alg Algorithm) error {
+	a, err := lookup(alg)
+	if err != nil {
+		return err
+	}
+
+	d, err := a.newDecompressor(r.br)
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	r.cur = d
+
+	return nil
+}
+
+// unexpectedEOF reports the end of the input in the header
+// of the compressed data as io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,95 @@
+package compress
+
+import (
+	"errors"
+	"io"
+)
+
+var errClosed = errors.New("compress: write to a closed Writer")
+
+// A Writer writes a compressed stream:
+//
+//	w, err := compress.NewWriter(f, compress.Gzip)
+//	enc := encode.NewEncoder(w)
+//	...
+//	err = w.Close()
+type Writer struct {
+	c         Compressor
+	autoFlush bool
+	err       error
+}
+
+// NewWriter writes the header of a stream compressed with alg to w
+// and returns a Writer writing the stream.
+func NewWriter(w io.Writer, alg Algorithm) (*Writer, error) {
+	a, err := lookup(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(append([]byte(magic), byte(alg))); err != nil {
+		return nil, err
+	}
+
+	c, err := a.newCompressor(w)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{c: c}, nil
+}
+
+// SetAutoFlush sets whether the Writer flushes the compressed data
+// after every Write. The encode.Encoder writes every item with a single
+// Write, so with auto flush every item is readable as soon as Encode
+// returns, as the readers tailing a log need, at the cost of a worse
+// compression of small items.
+func (w *Writer) SetAutoFlush(autoFlush bool) {
+	w.autoFlush = autoFlush
+}
+
+// Write compresses p, and flushes it to the underlying writer
+// with auto flush on.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.c.Write(p)
+	if err == nil && w.autoFlush {
+		err = w.c.Flush()
+	}
+	w.err = err
+
+	return n, err
+}
+
+// Flush writes the data compressed so far to the underlying writer.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.err = w.c.Flush()
+
+	return w.err
+}
+
+// Close finishes the compressed stream. It doesn't close the underlying
+// writer, more streams can be written after it.
+func (w *Writer) Close() error {
+	if w.err == errClosed {
+		return nil
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	if err := w.c.Close(); err != nil {
+		w.err = err
+		return err
+	}
+	w.err = errClosed
+
+	return nil
+}
@@ -1,85 +1,179 @@
 package decode
 
 import (
-	"bytes"
-	"io"
+	"fmt"
 	"reflect"
 	"sync"
 
-	"github.com/cstockton/go-conv"
 	"github.com/et-nik/binngo/binn"
-	"github.com/et-nik/binngo/encode"
 )
 
 const (
 	maxOneByteSize = 127
 )
 
-type decodeFunc func(reader io.Reader, v interface{}) error
+// decoderFunc decodes the item at the current offset of the decodeState
+// into v. The value v is always settable.
+type decoderFunc func(d *decodeState, v reflect.Value) error
 
-var decoderCache sync.Map // map[binn.Type]decodeFunc
+var decoderCache sync.Map // map[reflect.Type]decoderFunc
 
 type readLen int
 
-func decode(reader io.Reader, v interface{}) error {
-	rv := reflect.ValueOf(v)
-	rt := reflect.TypeOf(v)
-	if rv.Kind() != reflect.Ptr || rv.IsNil() {
-		return &InvalidUnmarshalError{rt}
+func unmarshal(data []byte, rv reflect.Value) error {
+	d := decodeStatePool.Get().(*decodeState)
+	err := d.init(data).value(rv.Elem())
+	d.release()
+
+	return err
+}
+
+func (d *decodeState) value(v reflect.Value) error {
+	return loadDecoderFunc(v.Type())(d, v)
+}
+
+func loadDecoderFunc(t reflect.Type) decoderFunc {
+	if fi, ok := decoderCache.Load(t); ok {
+		return fi.(decoderFunc)
 	}
 
-	containerType, _, err := readType(reader)
-	if err != nil {
-		return err
+	var (
+		wg sync.WaitGroup
+		f  decoderFunc
+	)
+	wg.Add(1)
+	fi, loaded := decoderCache.LoadOrStore(t, decoderFunc(func(d *decodeState, v reflect.Value) error {
+		wg.Wait()
+		return f(d, v)
+	}))
+	if loaded {
+		return fi.(decoderFunc)
 	}
 
-	if rt.Implements(unmarshalerType) && isStorageContainer(containerType) {
-		return decodeUnmarshalerStorage(containerType, reader, v)
+	f = newTypeDecoder(t)
+	wg.Done()
+	decoderCache.Store(t, f)
+	return f
+}
+
+func newTypeDecoder(t reflect.Type) decoderFunc {
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+		pt := reflect.PtrTo(t)
+
+		if pt.Implements(unmarshalerType) {
+			return unmarshalerDecoder
+		}
+		if pt.Implements(binaryUnmarshalerType) || pt.Implements(textUnmarshalerType) {
+			return newTextOrBinaryDecoder(t)
+		}
 	}
 
-	return decodeStorage(containerType, reader, v)
+	return newKindDecoder(t)
 }
 
-func decodeUnmarshalerStorage(containerType binn.Type, reader io.Reader, v interface{}) error {
-	size, ln, err := readSize(reader)
-	if err != nil {
-		return err
+// newKindDecoder returns a decoder for the kind of t,
+// ignoring the unmarshaler methods of t.
+//
+//nolint:gocyclo
+func newKindDecoder(t reflect.Type) decoderFunc {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return newPtrDecoder(t)
+	case reflect.Interface:
+		return interfaceDecoder
+	case reflect.Bool:
+		return boolDecoder
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intDecoder
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uintDecoder
+	case reflect.Float32, reflect.Float64:
+		return floatDecoder
+	case reflect.String:
+		return stringDecoder
+	case reflect.Slice:
+		return newSliceDecoder(t)
+	case reflect.Array:
+		return newArrayDecoder(t)
+	case reflect.Map:
+		return newMapDecoder(t)
+	case reflect.Struct:
+		return newStructDecoder(t)
+	}
+
+	return func(d *decodeState, v reflect.Value) error {
+		btype, err := d.peekType()
+		if err != nil {
+			return err
+		}
+
+		return &UnknownValueError{itemKind(btype), v.Kind()}
 	}
+}
+
+type ptrDecoder struct {
+	elemDec decoderFunc
+}
 
-	typeSize := len(encode.Int(int(containerType)))
+func newPtrDecoder(t reflect.Type) decoderFunc {
+	pd := ptrDecoder{loadDecoderFunc(t.Elem())}
+	return pd.decode
+}
 
-	buf := make([]byte, size-int(ln)-typeSize)
-	n, err := reader.Read(buf)
+func (pd ptrDecoder) decode(d *decodeState, v reflect.Value) error {
+	btype, err := d.peekType()
 	if err != nil {
 		return err
 	}
 
-	if n != len(buf) {
-		return ErrIncompleteRead
+	if btype == binn.Null {
+		d.off++
+		v.Set(reflect.Zero(v.Type()))
+
+		return nil
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.New(v.Type().Elem()))
 	}
 
-	data := make([]byte, 0, size)
-	data = append(data, encode.Uint8(uint8(containerType))...)
-	data = append(data, encode.Size(size, false)...)
-	data = append(data, buf...)
+	return pd.elemDec(d, v.Elem())
+}
+
+// interfaceDecoder decodes items into empty interfaces using
+// the Go type matching the item type.
+func interfaceDecoder(d *decodeState, v reflect.Value) error {
+	if v.NumMethod() != 0 {
+		btype, err := d.peekType()
+		if err != nil {
+			return err
+		}
+
+		if btype != binn.Null {
+			return &UnknownValueError{itemKind(btype), v.Kind()}
+		}
+	}
 
-	err = decodeUnmarshaler(data, v)
+	val, err := d.interfaceValue()
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
+	if val == nil {
+		v.Set(reflect.Zero(v.Type()))
+	} else {
+		v.Set(reflect.ValueOf(val))
+	}
 
-func decodeStorage(containerType binn.Type, reader io.Reader, v interface{}) error {
-	decoder := loadDecodeFunc(containerType)
-	return decoder(reader, v)
+	return nil
 }
 
-//nolint:funlen
-func decodeItem(rt reflect.Type, btype binn.Type, bval []byte) (interface{}, error) {
-	var v interface{}
-	var err error
+//nolint:funlen,gocyclo
+func (d *decodeState) interfaceValue() (interface{}, error) {
+	btype, err := d.readType()
+	if err != nil {
+		return nil, err
+	}
 
 	switch btype {
 	case binn.Null:
@@ -88,174 +182,93 @@ func decodeItem(rt reflect.Type, btype binn.Type, bval []byte) (interface{}, err
 		return true, nil
 	case binn.False:
 		return false, nil
+	case binn.ListType:
+		return d.interfaceList()
+	case binn.MapType:
+		return d.interfaceMap()
+	case binn.ObjectType:
+		return d.interfaceObject()
+	}
+
+	if isStorageContainer(btype) {
+		return nil, fmt.Errorf("%w %s", ErrUnknownType, btype)
+	}
+
+	bval, err := d.readValue(btype)
+	if err != nil {
+		return nil, err
+	}
+
+	switch btype {
 	case binn.Uint8Type:
-		v = Uint8(bval)
+		return Uint8(bval), nil
 	case binn.Uint16Type:
-		v = Uint16(bval)
+		return Uint16(bval), nil
 	case binn.Uint32Type:
-		v = Uint32(bval)
+		return Uint32(bval), nil
 	case binn.Uint64Type:
-		v = Uint64(bval)
+		return Uint64(bval), nil
 	case binn.Int8Type:
-		v = Int8(bval)
+		return Int8(bval), nil
 	case binn.Int16Type:
-		v = Int16(bval)
+		return Int16(bval), nil
 	case binn.Int32Type:
-		v = Int32(bval)
+		return Int32(bval), nil
 	case binn.Int64Type:
-		v = Int64(bval)
+		return Int64(bval), nil
 	case binn.Float32Type:
-		v = Float32(bval)
+		return Float32(bval), nil
 	case binn.Float64Type:
-		v = Float64(bval)
-	case binn.StringType:
-		v = String(bval[:len(bval)-1])
-	case binn.BlobType:
-		v = bval
-	case binn.ListType:
-		var l []interface{}
-		br := bytes.NewReader(bval)
-		_, wasReadLen, _ := readSize(br)
-		cnt, wasReadCnt, _ := readSize(br)
-		wasRead := wasReadLen + wasReadCnt
-
-		err = decodeListItems(br, &l, len(bval)-int(wasRead), wasRead, cnt)
-		if err != nil {
-			return nil, err
-		}
-
-		return l, nil
-	case binn.MapType:
-		br := bytes.NewReader(bval)
-		sz, rlsize, _ := readSize(br)
-		cnt, rlcnt, _ := readSize(br)
-
-		mapType := reflect.MapOf(reflect.TypeOf(int(0)), rt.Elem())
-		ptr := reflect.New(mapType)
-		ptr.Elem().Set(reflect.MakeMap(mapType))
-		m := ptr.Interface()
-
-		err = decodeMapItems(br, m, sz, rlsize+rlcnt, cnt)
-		if err != nil {
-			return nil, err
-		}
-
-		return reflect.ValueOf(m).Elem().Interface(), nil
-	case binn.ObjectType:
-		var obj interface{}
-		if rt.Kind() == reflect.Interface {
-			obj = map[string]interface{}{}
-		} else {
-			ptr := reflect.New(rt)
-			obj = ptr.Interface()
-		}
-
-		br := bytes.NewReader(bval)
-
-		err = decodeStorage(btype, br, &obj)
-
-		if err != nil {
-			return nil, err
-		}
-
-		return obj, nil
+		return Float64(bval), nil
 	}
 
-	if rt.Kind() == reflect.Interface {
-		v, err = convertToKind(kindMapper[btype], v)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		v, err = convertToType(rt, v)
-		if err != nil {
-			return nil, err
-		}
+	switch btype.Storage() {
+	case binn.StorageString:
+		return String(bval), nil
+	case binn.StorageBlob:
+		return append([]byte{}, bval...), nil
 	}
 
-	return v, nil
+	return nil, nil
 }
 
-func loadDecodeFunc(bt binn.Type) decodeFunc {
-	if fi, ok := decoderCache.Load(bt); ok {
-		return fi.(decodeFunc)
-	}
-
-	var (
-		wg sync.WaitGroup
-		f  decodeFunc
-	)
-	wg.Add(1)
-	fi, loaded := decoderCache.LoadOrStore(bt, decodeFunc(func(reader io.Reader, v interface{}) error {
-		wg.Wait()
-		return f(reader, v)
-	}))
-	if loaded {
-		return fi.(decodeFunc)
-	}
-
-	f = newTypeDecoder(bt)
-	wg.Done()
-	decoderCache.Store(bt, f)
-	return f
-}
-
-func newTypeDecoder(bt binn.Type) decodeFunc {
-	switch bt {
-	case binn.ListType:
-		return decodeList
-	case binn.MapType:
-		return decodeMap
-	case binn.ObjectType:
-		return decodeObject
+// itemKind returns the kind of the Go value the item type decodes into.
+// It is used to report type mismatches.
+func itemKind(btype binn.Type) reflect.Kind {
+	switch btype {
 	case binn.Null:
-		return func(_ io.Reader, _ interface{}) error {
-			return nil
-		}
-	}
-
-	decoder := newValueDecoder(bt)
-	return decoder.DecodeValue
-}
-
-func convertToType(rt reflect.Type, val interface{}) (interface{}, error) {
-	switch rt.Kind() {
-	case reflect.Interface:
-		return val, nil
-	case reflect.Ptr:
-		return convertToType(rt.Elem(), val)
-	default:
-		return convertToKind(rt.Kind(), val)
+		return reflect.Invalid
+	case binn.True, binn.False:
+		return reflect.Bool
+	case binn.Uint8Type:
+		return reflect.Uint8
+	case binn.Uint16Type:
+		return reflect.Uint16
+	case binn.Uint32Type:
+		return reflect.Uint32
+	case binn.Uint64Type:
+		return reflect.Uint64
+	case binn.Int8Type:
+		return reflect.Int8
+	case binn.Int16Type:
+		return reflect.Int16
+	case binn.Int32Type:
+		return reflect.Int32
+	case binn.Int64Type:
+		return reflect.Int64
+	case binn.Float32Type:
+		return reflect.Float32
+	case binn.Float64Type:
+		return reflect.Float64
+	case binn.ListType, binn.BlobType:
+		return reflect.Slice
+	case binn.MapType, binn.ObjectType:
+		return reflect.Map
 	}
-}
 
-func convertToKind(rk reflect.Kind, v interface{}) (interface{}, error) {
-	switch rk {
-	case reflect.Int:
-		return conv.Int(v)
-	case reflect.Int8:
-		return conv.Int8(v)
-	case reflect.Int16:
-		return conv.Int16(v)
-	case reflect.Int32:
-		return conv.Int32(v)
-	case reflect.Int64:
-		return conv.Int64(v)
-	case reflect.Uint:
-		return conv.Uint(v)
-	case reflect.Uint8:
-		return conv.Uint8(v)
-	case reflect.Uint16:
-		return conv.Uint16(v)
-	case reflect.Uint32:
-		return conv.Uint32(v)
-	case reflect.Uint64:
-		return conv.Uint64(v)
-	case reflect.Bool:
-		return conv.Bool(v)
-	case reflect.String:
-		return conv.String(v)
+	if btype.Storage() == binn.StorageString {
+		return reflect.String
 	}
 
-	return v, nil
+	return reflect.Invalid
 }
@@ -1,44 +1,150 @@
 package decode
 
-import "io"
+import (
+	"reflect"
 
-func decodeList(reader io.Reader, v interface{}) error {
-	sz, rSize, err := readSize(reader)
+	"github.com/et-nik/binngo/binn"
+)
+
+type sliceDecoder struct {
+	elemDec decoderFunc
+	isBytes bool
+}
+
+func newSliceDecoder(t reflect.Type) decoderFunc {
+	sd := sliceDecoder{
+		elemDec: loadDecoderFunc(t.Elem()),
+		isBytes: t.Elem().Kind() == reflect.Uint8,
+	}
+	return sd.decode
+}
+
+// decode decodes a list into a slice. The slice is truncated first, and
+// its backing array is reused when it's large enough for the list items.
+// Blobs, including the blobs of user types, are copied into byte slices.
+func (sd *sliceDecoder) decode(d *decodeState, v reflect.Value) error {
+	btype, err := d.readType()
 	if err != nil {
 		return err
 	}
 
-	cnt, rCount, err := readSize(reader)
+	switch {
+	case btype == binn.Null:
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case btype.Storage() == binn.StorageBlob && sd.isBytes:
+		bval, err := d.readValue(btype)
+		if err != nil {
+			return err
+		}
+
+		dst := v.Slice(0, 0)
+		if v.IsNil() {
+			dst = reflect.MakeSlice(v.Type(), 0, len(bval))
+		}
+		v.Set(reflect.AppendSlice(dst, reflect.ValueOf(bval).Convert(v.Type())))
+
+		return nil
+	case btype != binn.ListType:
+		d.off -= typeLen(btype)
+		return d.unexpected(v)
+	}
+
+	end, cnt, err := d.beginContainer(btype)
 	if err != nil {
 		return err
 	}
 
-	return decodeListItems(reader, v, sz, rSize+rCount, cnt)
-}
+	if n := sizeHint(cnt, end-d.off); v.IsNil() || v.Cap() < n {
+		v.Set(reflect.MakeSlice(v.Type(), 0, n))
+	} else {
+		v.SetLen(0)
+	}
 
-func decodeListItems(reader io.Reader, v interface{}, size int, wasRead readLen, items int) error {
-	rItems := 0
-	readPosition := wasRead
+	for i := 0; i < cnt; i++ {
+		if i == v.Cap() {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		} else {
+			v.SetLen(i + 1)
+		}
 
-	for rItems < items && readPosition < readLen(size) {
-		btype, rlen, err := readType(reader)
-		if err != nil {
+		if err := sd.elemDec(d, v.Index(i)); err != nil {
+			v.SetLen(i)
 			return err
 		}
+	}
+
+	return d.endContainer(end)
+}
+
+type arrayDecoder struct {
+	elemDec  decoderFunc
+	elemType reflect.Type
+}
+
+func newArrayDecoder(t reflect.Type) decoderFunc {
+	ad := arrayDecoder{loadDecoderFunc(t.Elem()), t.Elem()}
+	return ad.decode
+}
+
+// decode decodes a list into an array. Items beyond the length
+// of the array are dropped, and the array elements missing
+// from a shorter list are zeroed.
+func (ad *arrayDecoder) decode(d *decodeState, v reflect.Value) error {
+	btype, err := d.readType()
+	if err != nil {
+		return err
+	}
+
+	if btype == binn.Null {
+		return nil
+	}
+
+	if btype != binn.ListType {
+		d.off -= typeLen(btype)
+		return d.unexpected(v)
+	}
+
+	end, cnt, err := d.beginContainer(btype)
+	if err != nil {
+		return err
+	}
 
-		bval, err := readValue(btype, reader)
+	i := 0
+	for ; i < cnt; i++ {
+		if i >= v.Len() {
+			err = d.skip()
+		} else {
+			err = ad.elemDec(d, v.Index(i))
+		}
 		if err != nil {
 			return err
 		}
+	}
+
+	for ; i < v.Len(); i++ {
+		v.Index(i).Set(reflect.Zero(ad.elemType))
+	}
+
+	return d.endContainer(end)
+}
+
+func (d *decodeState) interfaceList() (interface{}, error) {
+	end, cnt, err := d.beginContainer(binn.ListType)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make([]interface{}, 0, sizeHint(cnt, end-d.off))
 
-		err = addSliceItem(btype, bval, v)
+	for i := 0; i < cnt; i++ {
+		item, err := d.interfaceValue()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		rItems++
-		readPosition += readLen(len(bval)) + rlen
+		l = append(l, item)
 	}
 
-	return nil
+	return l, d.endContainer(end)
 }
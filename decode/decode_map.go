@@ -1,65 +1,141 @@
 package decode
 
-import "io"
+import (
+	"reflect"
+	"strconv"
 
-func decodeMap(reader io.Reader, v interface{}) error {
-	sz, rSize, err := readSize(reader)
+	"github.com/et-nik/binngo/binn"
+)
+
+type mapDecoder struct {
+	keyType  reflect.Type
+	elemType reflect.Type
+	elemDec  decoderFunc
+}
+
+func newMapDecoder(t reflect.Type) decoderFunc {
+	md := mapDecoder{t.Key(), t.Elem(), loadDecoderFunc(t.Elem())}
+	return md.decode
+}
+
+// decode decodes objects into maps with string or encoding.TextUnmarshaler
+// keys, and maps into maps with integer keys.
+func (md *mapDecoder) decode(d *decodeState, v reflect.Value) error {
+	btype, err := d.readType()
 	if err != nil {
 		return err
 	}
 
-	cnt, rCount, err := readSize(reader)
+	switch btype {
+	case binn.Null:
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case binn.MapType:
+		if !isIntKind(md.keyType.Kind()) {
+			d.off -= typeLen(btype)
+			return d.unexpected(v)
+		}
+	case binn.ObjectType:
+	default:
+		d.off -= typeLen(btype)
+		return d.unexpected(v)
+	}
+
+	end, cnt, err := d.beginContainer(btype)
 	if err != nil {
 		return err
 	}
 
-	return decodeMapItems(reader, v, sz, rSize+rCount, cnt)
-}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
 
-func decodeMapItems(reader io.Reader, v interface{}, size int, wasRead readLen, items int) error {
-	readItems := 0
-	readPosition := wasRead
+	key := reflect.New(md.keyType).Elem()
+	elem := reflect.New(md.elemType).Elem()
+	zero := reflect.Zero(md.elemType)
 
-	for readItems < items && readPosition < readLen(size) {
-		key, read, err := readMapKey(reader)
-		if err != nil {
-			return err
+	for i := 0; i < cnt; i++ {
+		if btype == binn.MapType {
+			err = d.decodeMapKey(key)
+		} else {
+			err = d.decodeObjectKey(key)
 		}
-		readPosition += read
-
-		t, read, err := readType(reader)
 		if err != nil {
 			return err
 		}
-		readPosition += read
 
-		val, err := readValue(t, reader)
-		if err != nil {
+		elem.Set(zero)
+
+		if err := md.elemDec(d, elem); err != nil {
 			return err
 		}
-		readPosition += readLen(len(val))
 
-		err = addMapItem(key, t, val, v)
-		if err != nil {
-			return err
+		v.SetMapIndex(key, elem)
+	}
+
+	return d.endContainer(end)
+}
+
+func (d *decodeState) decodeMapKey(key reflect.Value) error {
+	k, err := d.readMapKey()
+	if err != nil {
+		return err
+	}
+
+	switch key.Kind() {
+	case reflect.Interface:
+		key.Set(reflect.ValueOf(int(k)))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if k < 0 || key.OverflowUint(uint64(k)) {
+			return &OverflowError{strconv.Itoa(int(k)), key.Type()}
 		}
 
-		readItems++
+		key.SetUint(uint64(k))
+
+		return nil
+	}
+
+	if key.OverflowInt(int64(k)) {
+		return &OverflowError{strconv.Itoa(int(k)), key.Type()}
 	}
 
+	key.SetInt(int64(k))
+
 	return nil
 }
 
-func readMapKey(reader io.Reader) (int, readLen, error) {
-	var bk = make([]byte, 4)
-	n, err := reader.Read(bk)
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Interface,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+
+	return false
+}
+
+func (d *decodeState) interfaceMap() (interface{}, error) {
+	end, cnt, err := d.beginContainer(binn.MapType)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 
-	if n != 4 {
-		return 0, readLen(n), ErrIncompleteRead
+	m := make(map[int]interface{}, sizeHint(cnt, end-d.off))
+
+	for i := 0; i < cnt; i++ {
+		k, err := d.readMapKey()
+		if err != nil {
+			return nil, err
+		}
+
+		item, err := d.interfaceValue()
+		if err != nil {
+			return nil, err
+		}
+
+		m[int(k)] = item
 	}
 
-	return int(Int32(bk)), 4, nil
+	return m, d.endContainer(end)
 }
@@ -1,78 +1,267 @@
 package decode
 
-import "io"
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
-func decodeObject(reader io.Reader, v interface{}) error {
-	sz, rSize, err := readSize(reader)
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/naming"
+)
+
+func (d *decodeState) decodeObjectKey(key reflect.Value) error {
+	k, err := d.readObjectKey()
 	if err != nil {
 		return err
 	}
 
-	cnt, rCount, err := readSize(reader)
-	if err != nil {
-		return err
+	switch key.Kind() {
+	case reflect.String:
+		key.SetString(String(k))
+		return nil
+	case reflect.Interface:
+		key.Set(reflect.ValueOf(String(k)))
+		return nil
 	}
 
-	return decodeObjectItems(reader, v, sz, rSize+rCount, cnt)
+	return setTextKey(key, k)
 }
 
-func decodeObjectItems(reader io.Reader, v interface{}, size int, wasRead readLen, items int) error {
-	rItems := 0
-	rPosition := wasRead
+func (d *decodeState) interfaceObject() (interface{}, error) {
+	end, cnt, err := d.beginContainer(binn.ObjectType)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{}, sizeHint(cnt, end-d.off))
 
-	for rItems < items && rPosition < readLen(size) {
-		key, read, err := readObjectKey(reader)
+	for i := 0; i < cnt; i++ {
+		k, err := d.readObjectKey()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		rPosition += read
 
-		btype, read, err := readType(reader)
+		item, err := d.interfaceValue()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		rPosition += read
 
-		bval, err := readValue(btype, reader)
-		if err != nil {
-			return err
+		m[String(k)] = item
+	}
+
+	return m, d.endContainer(end)
+}
+
+type structDecoder struct {
+	t      reflect.Type
+	fields fieldIndex
+	// named are the field indexes with the keys of the untagged
+	// fields derived by a naming strategy.
+	named sync.Map // map[*naming.Strategy]*fieldIndex
+	// mapFields are the fields tagged with map keys, such as "#12".
+	mapFields map[int32]int
+}
+
+// fieldIndex maps object keys to the fields of a struct.
+type fieldIndex struct {
+	fields map[string]int
+	// folded maps the keys folded by foldKey, for the case-insensitive
+	// matching.
+	folded map[string]int
+}
+
+func newStructDecoder(t reflect.Type) decoderFunc {
+	sd := &structDecoder{t: t}
+	sd.fields = newFieldIndex(t, nil)
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("binn"), ",")[0]
+
+		if k, ok := mapKeyTag(name); ok {
+			if sd.mapFields == nil {
+				sd.mapFields = make(map[int32]int, t.NumField())
+			}
+			sd.mapFields[k] = i
 		}
-		rPosition += readLen(len(bval))
+	}
 
-		err = addObjectItem(key, btype, bval, v)
-		if err != nil {
-			return err
+	return sd.decode
+}
+
+// newFieldIndex indexes the fields of t by the names from their binn
+// tags, or by the keys derived by s for the untagged fields, and by
+// the field names.
+func newFieldIndex(t reflect.Type, s *naming.Strategy) fieldIndex {
+	idx := fieldIndex{
+		fields: make(map[string]int, 2*t.NumField()),
+		folded: make(map[string]int, 2*t.NumField()),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("binn"), ",")[0]
+		if _, ok := mapKeyTag(name); ok {
+			continue
+		}
+
+		if name == "" && s != nil {
+			name = s.Key(t.Field(i).Name)
+		}
+
+		if name != "" {
+			idx.fields[name] = i
 		}
+	}
 
-		rItems++
+	// Field names take precedence over tags.
+	for i := 0; i < t.NumField(); i++ {
+		idx.fields[t.Field(i).Name] = i
 	}
 
-	return nil
+	// The keys folded alike match the first of their fields.
+	for k, i := range idx.fields {
+		folded := string(foldKey(nil, []byte(k)))
+		if j, ok := idx.folded[folded]; !ok || i < j {
+			idx.folded[folded] = i
+		}
+	}
+
+	return idx
 }
 
-func readObjectKey(reader io.Reader) (string, readLen, error) {
-	var bsz = make([]byte, 1)
-	n, err := reader.Read(bsz)
+// fieldIndex returns the field index for the naming strategy s.
+func (sd *structDecoder) fieldIndex(s *naming.Strategy) *fieldIndex {
+	if s == nil {
+		return &sd.fields
+	}
+
+	if idx, ok := sd.named.Load(s); ok {
+		return idx.(*fieldIndex)
+	}
+
+	idx := newFieldIndex(sd.t, s)
+	sd.named.Store(s, &idx)
+
+	return &idx
+}
+
+// foldKey appends the key folded for the case-insensitive matching,
+// so that the keys equal by bytes.EqualFold are folded alike.
+func foldKey(dst, key []byte) []byte {
+	for i := 0; i < len(key); {
+		c := key[i]
+		if c < utf8.RuneSelf {
+			if 'a' <= c && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+
+			dst = append(dst, c)
+			i++
+
+			continue
+		}
+
+		r, n := utf8.DecodeRune(key[i:])
+		i += n
+
+		var buf [utf8.UTFMax]byte
+		n = utf8.EncodeRune(buf[:], unicode.ToUpper(unicode.ToLower(r)))
+		dst = append(dst, buf[:n]...)
+	}
+
+	return dst
+}
+
+// mapKeyTag parses a map key tag, such as "#12".
+func mapKeyTag(name string) (int32, bool) {
+	if !strings.HasPrefix(name, "#") {
+		return 0, false
+	}
+
+	k, err := strconv.ParseInt(name[1:], 10, 32)
+
+	return int32(k), err == nil
+}
+
+// decode decodes an object into a struct. Object keys are matched
+// to the field names and to the names from the binn tags, or to the keys
+// derived by the naming strategy of the decoder. The structs with
+// fields tagged with map keys are also decoded from maps.
+func (sd *structDecoder) decode(d *decodeState, v reflect.Value) error {
+	btype, err := d.readType()
+	if err != nil {
+		return err
+	}
+
+	if btype == binn.Null {
+		return nil
+	}
+
+	if btype != binn.ObjectType && (btype != binn.MapType || sd.mapFields == nil) {
+		d.off -= typeLen(btype)
+		return d.unexpected(v)
+	}
+
+	end, cnt, err := d.beginContainer(btype)
 	if err != nil {
-		return "", 0, err
+		return err
 	}
 
-	if n != 1 {
-		return "", readLen(n), ErrIncompleteRead
+	idx := sd.fieldIndex(d.opts.naming)
+
+	for i := 0; i < cnt; i++ {
+		fi, err := sd.readField(d, btype, idx)
+		if err != nil {
+			return err
+		}
+
+		f := v.Field(fi)
+		if !f.CanSet() {
+			return ErrCantSetValue
+		}
+
+		if err := loadDecoderFunc(f.Type())(d, f); err != nil {
+			return fmt.Errorf("failed to add object item to struct: %w", err)
+		}
 	}
 
-	sz := int(Uint8(bsz))
+	return d.endContainer(end)
+}
+
+// readField reads the key of an object or a map item and returns
+// the index of the matching field. Exact matches of object keys take
+// precedence over the case-insensitive ones.
+func (sd *structDecoder) readField(d *decodeState, btype binn.Type, idx *fieldIndex) (int, error) {
+	if btype == binn.MapType {
+		k, err := d.readMapKey()
+		if err != nil {
+			return 0, err
+		}
+
+		fi, ok := sd.mapFields[k]
+		if !ok {
+			return 0, fmt.Errorf("failed to find field by map key %d: %w", k, ErrItemNotFound)
+		}
 
-	var bkey = make([]byte, sz)
+		return fi, nil
+	}
 
-	n, err = reader.Read(bkey)
+	k, err := d.readObjectKey()
 	if err != nil {
-		return "", 0, err
+		return 0, err
 	}
 
-	if n != sz {
-		return "", readLen(n), ErrIncompleteRead
+	fi, ok := idx.fields[string(k)]
+	if !ok && d.opts.caseInsensitive {
+		d.keyBuf = foldKey(d.keyBuf[:0], k)
+		fi, ok = idx.folded[string(d.keyBuf)]
+	}
+	if !ok {
+		return 0, fmt.Errorf("failed to find field name by tag: %w", ErrItemNotFound)
 	}
 
-	return String(bkey), readLen(sz + 1), nil
+	return fi, nil
 }
@@ -23,7 +23,7 @@ func TestDecodeIntList(t *testing.T) {
 	v := []int{}
 	r := bytes.NewReader(b)
 
-	err := decode(r, &v)
+	err := NewDecoder(r).Decode(&v)
 
 	assert.Nil(t, err)
 	assert.Equal(t, []int{123, -456, 789}, v)
@@ -44,7 +44,7 @@ func TestDecodeStringList(t *testing.T) {
 	v := []string{}
 	r := bytes.NewReader(b)
 
-	err := decode(r, &v)
+	err := NewDecoder(r).Decode(&v)
 
 	assert.Nil(t, err)
 	assert.Equal(t, []string{"hello", "world"}, v)
@@ -67,7 +67,7 @@ func TestDecodeIterfaceList(t *testing.T) {
 	var v []interface{}
 	r := bytes.NewReader(b)
 
-	err := decode(r, &v)
+	err := NewDecoder(r).Decode(&v)
 
 	assert.Nil(t, err)
 	assert.Equal(t, []interface{}{"hello", "world", uint16(789)}, v)
@@ -94,7 +94,7 @@ func TestDecodeStringMap(t *testing.T) {
 	v := map[int]interface{}{}
 	r := bytes.NewReader(b)
 
-	err := decode(r, &v)
+	err := NewDecoder(r).Decode(&v)
 
 	assert.Nil(t, err)
 	assert.Equal(t, map[int]interface{}{
@@ -118,7 +118,7 @@ func TestDecodeStringObjectToStruct(t *testing.T) {
 	var v ts
 	r := bytes.NewReader(b)
 
-	err := decode(r, &v)
+	err := NewDecoder(r).Decode(&v)
 
 	assert.Nil(t, err)
 	assert.Equal(t, ts{Hello: "world"}, v)
@@ -136,8 +136,26 @@ func TestDecodeStringObjectToMap(t *testing.T) {
 	m := map[string]string{}
 	r := bytes.NewReader(b)
 
-	err := decode(r, &m)
+	err := NewDecoder(r).Decode(&m)
 
 	assert.Nil(t, err)
 	assert.Equal(t, map[string]string{"hello": "world"}, m)
 }
+
+func TestDecodeSkipsUserContainer(t *testing.T) {
+	b := []byte{
+		0xE0,			// type = list (container)
+		0x09,			// container total size
+		0x02,			// items count
+		0x20, 0x01,		// type = uint8, value
+		0xF0, 0x01,		// type = user container, 2 bytes
+		0x04,			// container total size
+		0x00,			// items count
+	}
+	var v [1]int
+
+	err := Unmarshal(b, &v)
+
+	assert.Nil(t, err)
+	assert.Equal(t, [1]int{1}, v)
+}
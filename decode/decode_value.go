@@ -1,43 +1,297 @@
 package decode
 
 import (
-	"io"
+	"math"
 	"reflect"
+	"strconv"
 
 	"github.com/et-nik/binngo/binn"
 )
 
-type valueDecoder struct {
-	binnType binn.Type
+// readScalar reads the type and the value of a non-container item.
+func (d *decodeState) readScalar() (binn.Type, []byte, error) {
+	btype, err := d.readType()
+	if err != nil {
+		return binn.Null, nil, err
+	}
+
+	if isStorageContainer(btype) {
+		d.off -= typeLen(btype)
+		return btype, nil, nil
+	}
+
+	bval, err := d.readValue(btype)
+	if err != nil {
+		return binn.Null, nil, err
+	}
+
+	return btype, bval, nil
 }
 
-func newValueDecoder(binnType binn.Type) *valueDecoder {
-	return &valueDecoder{binnType}
+// mismatch skips the item of the type btype that can't be stored into
+// a value of the kind k and reports the type mismatch.
+func (d *decodeState) mismatch(btype binn.Type, k reflect.Kind) error {
+	if isStorageContainer(btype) {
+		if err := d.skip(); err != nil {
+			return err
+		}
+	}
+
+	return &UnknownValueError{itemKind(btype), k}
 }
 
-func (vd *valueDecoder) DecodeValue(reader io.Reader, v interface{}) error {
-	valuePtr := reflect.ValueOf(v)
-	value := valuePtr.Elem()
+// unexpected skips the item at the current offset that can't be stored
+// into v and reports the type mismatch.
+func (d *decodeState) unexpected(v reflect.Value) error {
+	btype, err := d.peekType()
+	if err != nil {
+		return err
+	}
 
-	if !value.CanSet() {
-		return ErrCantSetValue
+	if err := d.skip(); err != nil {
+		return err
 	}
 
-	bval, err := readValue(vd.binnType, reader)
+	return &UnknownValueError{itemKind(btype), v.Kind()}
+}
+
+// scanBool reads a boolean item. It reports false for null items.
+func (d *decodeState) scanBool() (bool, bool, error) {
+	btype, _, err := d.readScalar()
 	if err != nil {
-		return err
+		return false, false, err
 	}
 
-	converted, err := decodeItem(value.Type(), vd.binnType, bval)
+	switch btype {
+	case binn.Null:
+		return false, false, nil
+	case binn.True:
+		return true, true, nil
+	case binn.False:
+		return false, true, nil
+	}
+
+	return false, false, d.mismatch(btype, reflect.Bool)
+}
+
+// scanInt reads a number or a numeric string as int64. It reports false
+// for null items. The type t is the destination type reported in errors.
+func (d *decodeState) scanInt(t reflect.Type) (int64, bool, error) {
+	btype, bval, err := d.readScalar()
 	if err != nil {
+		return 0, false, err
+	}
+
+	switch btype {
+	case binn.Null:
+		return 0, false, nil
+	case binn.Int8Type, binn.Int16Type, binn.Int32Type, binn.Int64Type:
+		return signedValue(btype, bval), true, nil
+	case binn.Uint8Type, binn.Uint16Type, binn.Uint32Type, binn.Uint64Type:
+		u := unsignedValue(btype, bval)
+		if u > math.MaxInt64 {
+			return 0, false, &OverflowError{strconv.FormatUint(u, 10), t}
+		}
+		return int64(u), true, nil
+	case binn.Float32Type, binn.Float64Type:
+		f := floatValue(btype, bval)
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false, &OverflowError{strconv.FormatFloat(f, 'g', -1, 64), t}
+		}
+		return int64(f), true, nil
+	case binn.StringType:
+		i, err := strconv.ParseInt(String(bval), 10, 64)
+		if err != nil {
+			return 0, false, &UnknownValueError{reflect.String, t.Kind()}
+		}
+		return i, true, nil
+	}
+
+	return 0, false, d.mismatch(btype, t.Kind())
+}
+
+// scanUint reads a number or a numeric string as uint64. It reports false
+// for null items. The type t is the destination type reported in errors.
+func (d *decodeState) scanUint(t reflect.Type) (uint64, bool, error) {
+	btype, bval, err := d.readScalar()
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch btype {
+	case binn.Null:
+		return 0, false, nil
+	case binn.Uint8Type, binn.Uint16Type, binn.Uint32Type, binn.Uint64Type:
+		return unsignedValue(btype, bval), true, nil
+	case binn.Int8Type, binn.Int16Type, binn.Int32Type, binn.Int64Type:
+		i := signedValue(btype, bval)
+		if i < 0 {
+			return 0, false, &OverflowError{strconv.FormatInt(i, 10), t}
+		}
+		return uint64(i), true, nil
+	case binn.Float32Type, binn.Float64Type:
+		f := floatValue(btype, bval)
+		if f < 0 || f >= math.MaxUint64 {
+			return 0, false, &OverflowError{strconv.FormatFloat(f, 'g', -1, 64), t}
+		}
+		return uint64(f), true, nil
+	case binn.StringType:
+		u, err := strconv.ParseUint(String(bval), 10, 64)
+		if err != nil {
+			return 0, false, &UnknownValueError{reflect.String, t.Kind()}
+		}
+		return u, true, nil
+	}
+
+	return 0, false, d.mismatch(btype, t.Kind())
+}
+
+// scanFloat reads a number or a numeric string as float64. It reports
+// false for null items. The type t is the destination type reported
+// in errors.
+func (d *decodeState) scanFloat(t reflect.Type) (float64, bool, error) {
+	btype, bval, err := d.readScalar()
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch btype {
+	case binn.Null:
+		return 0, false, nil
+	case binn.Float32Type, binn.Float64Type:
+		return floatValue(btype, bval), true, nil
+	case binn.Int8Type, binn.Int16Type, binn.Int32Type, binn.Int64Type:
+		return float64(signedValue(btype, bval)), true, nil
+	case binn.Uint8Type, binn.Uint16Type, binn.Uint32Type, binn.Uint64Type:
+		return float64(unsignedValue(btype, bval)), true, nil
+	case binn.StringType:
+		f, err := strconv.ParseFloat(String(bval), 64)
+		if err != nil {
+			return 0, false, &UnknownValueError{reflect.String, t.Kind()}
+		}
+		return f, true, nil
+	}
+
+	return 0, false, d.mismatch(btype, t.Kind())
+}
+
+// scanString reads a string or an integer formatted as a string.
+// It reports false for null items.
+func (d *decodeState) scanString() (string, bool, error) {
+	btype, bval, err := d.readScalar()
+	if err != nil {
+		return "", false, err
+	}
+
+	switch btype {
+	case binn.Null:
+		return "", false, nil
+	case binn.Int8Type, binn.Int16Type, binn.Int32Type, binn.Int64Type:
+		return strconv.FormatInt(signedValue(btype, bval), 10), true, nil
+	case binn.Uint8Type, binn.Uint16Type, binn.Uint32Type, binn.Uint64Type:
+		return strconv.FormatUint(unsignedValue(btype, bval), 10), true, nil
+	}
+
+	if btype.Storage() != binn.StorageString {
+		return "", false, d.mismatch(btype, reflect.String)
+	}
+
+	return String(bval), true, nil
+}
+
+func boolDecoder(d *decodeState, v reflect.Value) error {
+	b, ok, err := d.scanBool()
+	if ok {
+		v.SetBool(b)
+	}
+
+	return err
+}
+
+func intDecoder(d *decodeState, v reflect.Value) error {
+	i, ok, err := d.scanInt(v.Type())
+	if !ok {
 		return err
 	}
 
-	if value.Kind() != reflect.ValueOf(converted).Kind() && value.Kind() != reflect.Interface {
-		return &UnknownValueError{reflect.ValueOf(converted).Kind(), value.Kind()}
+	if v.OverflowInt(i) {
+		return &OverflowError{strconv.FormatInt(i, 10), v.Type()}
 	}
 
-	value.Set(reflect.ValueOf(converted))
+	v.SetInt(i)
 
 	return nil
 }
+
+func uintDecoder(d *decodeState, v reflect.Value) error {
+	u, ok, err := d.scanUint(v.Type())
+	if !ok {
+		return err
+	}
+
+	if v.OverflowUint(u) {
+		return &OverflowError{strconv.FormatUint(u, 10), v.Type()}
+	}
+
+	v.SetUint(u)
+
+	return nil
+}
+
+func floatDecoder(d *decodeState, v reflect.Value) error {
+	f, ok, err := d.scanFloat(v.Type())
+	if !ok {
+		return err
+	}
+
+	if v.OverflowFloat(f) {
+		return &OverflowError{strconv.FormatFloat(f, 'g', -1, 64), v.Type()}
+	}
+
+	v.SetFloat(f)
+
+	return nil
+}
+
+func stringDecoder(d *decodeState, v reflect.Value) error {
+	s, ok, err := d.scanString()
+	if ok {
+		v.SetString(s)
+	}
+
+	return err
+}
+
+func signedValue(btype binn.Type, bval []byte) int64 {
+	switch btype {
+	case binn.Int8Type:
+		return int64(Int8(bval))
+	case binn.Int16Type:
+		return int64(Int16(bval))
+	case binn.Int32Type:
+		return int64(Int32(bval))
+	default:
+		return Int64(bval)
+	}
+}
+
+func unsignedValue(btype binn.Type, bval []byte) uint64 {
+	switch btype {
+	case binn.Uint8Type:
+		return uint64(Uint8(bval))
+	case binn.Uint16Type:
+		return uint64(Uint16(bval))
+	case binn.Uint32Type:
+		return uint64(Uint32(bval))
+	default:
+		return Uint64(bval)
+	}
+}
+
+func floatValue(btype binn.Type, bval []byte) float64 {
+	if btype == binn.Float32Type {
+		return float64(Float32(bval))
+	}
+
+	return Float64(bval)
+}
@@ -2,44 +2,38 @@
 package decode
 
 import (
-	"bytes"
 	"reflect"
 )
 
+// Unmarshaler is the interface implemented by types that can unmarshal
+// a BINN item of themselves. UnmarshalBINN must copy the data if it wishes
+// to retain the data after returning.
 type Unmarshaler interface {
 	UnmarshalBINN([]byte) error
 }
 
 var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
 
+// Unmarshal parses the BINN-encoded data and stores the result
+// in the value pointed to by v.
+//
+// A value implementing Unmarshaler, at any nesting level, receives
+// the complete encoding of its item.
+// Otherwise blobs are decoded with encoding.BinaryUnmarshaler and strings
+// with encoding.TextUnmarshaler when the target type implements them.
+// Object keys are decoded into map keys implementing encoding.TextUnmarshaler.
+//
+// Unmarshal reads the data in place, strings and blobs stored into v
+// are copied, so the data can be reused after Unmarshal returns.
 func Unmarshal(data []byte, v interface{}) error {
-	decoder := NewDecoder(bytes.NewReader(data))
-
-	rt := reflect.TypeOf(v)
-
-	if rt.Implements(unmarshalerType) {
-		err := decodeUnmarshaler(data, v)
-		if err != nil {
-			return err
-		}
-
-		return nil
+	if u, ok := v.(Unmarshaler); ok {
+		return u.UnmarshalBINN(data)
 	}
 
-	return decoder.Decode(v)
-}
-
-func decodeUnmarshaler(data []byte, v interface{}) error {
 	rv := reflect.ValueOf(v)
-
-	unm, ok := rv.Interface().(Unmarshaler)
-	if !ok {
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return &InvalidUnmarshalError{reflect.TypeOf(v)}
 	}
-	err := unm.UnmarshalBINN(data)
-	if err != nil {
-		return err
-	}
 
-	return nil
+	return unmarshal(data, rv)
 }
@@ -3,12 +3,17 @@ package decode_test
 import (
 	"bytes"
 	"errors"
+	"io"
+	"math/big"
+	"net"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/et-nik/binngo/binn"
 	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/naming"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -407,5 +412,600 @@ func TestDecodeUnmarshaller_WhenStrippedBytes_ExpectError(t *testing.T) {
 
 	err := decoder.Decode(&v)
 
-	require.ErrorIs(t, err, decode.ErrIncompleteRead)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestDecodeArray(t *testing.T) {
+	b := []byte{
+		binn.ListType, // [type] list (container)
+		0x0B,          // [size] container total size
+		0x03,          // [count] items
+		0x20,          // [type] = uint8
+		0x7B,          // [data] (123)
+		0x41,          // [type] = int16
+		0xFE, 0x38,    // [data] (-456)
+		0x40,       // [type] = uint16
+		0x03, 0x15, // [data] (789)
+	}
+
+	tests := []struct {
+		name     string
+		v        interface{}
+		expected interface{}
+	}{
+		{"same length", &[3]int{}, &[3]int{123, -456, 789}},
+		{"extra items dropped", &[2]int{}, &[2]int{123, -456}},
+		{"missing items zeroed", &[5]int{1, 2, 3, 4, 5}, &[5]int{123, -456, 789, 0, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := decode.Unmarshal(b, test.v)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, test.v)
+		})
+	}
+}
+
+func TestDecodeNestedTypedLists(t *testing.T) {
+	b := []byte{
+		binn.ListType, // [type] list (container)
+		0x0E,          // [size] container total size
+		0x02,          // [count] items
+
+		binn.ListType, // [type] list (container)
+		0x05,          // [size] container total size
+		0x01,          // [count] items
+		0x20, 0x01,    // [type] = uint8, [data] (1)
+
+		binn.ListType, // [type] list (container)
+		0x06,          // [size] container total size
+		0x01,          // [count] items
+		0x41,          // [type] = int16
+		0xFE, 0x38,    // [data] (-456)
+	}
+
+	var v [][2]int16
+
+	err := decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	assert.Equal(t, [][2]int16{{1, 0}, {-456, 0}}, v)
+}
+
+func TestDecodeInterfaceListWithNestedContainers(t *testing.T) {
+	b := []byte{
+		binn.ListType, // [type] list (container)
+		0x1A,          // [size] container total size
+		0x03,          // [count] items
+
+		binn.ListType, // [type] list (container)
+		0x05,          // [size] container total size
+		0x01,          // [count] items
+		0x20, 0x01,    // [type] = uint8, [data] (1)
+
+		binn.MapType,           // [type] map (container)
+		0x09,                   // [size] container total size
+		0x01,                   // [count] key/value pairs
+		0x00, 0x00, 0x00, 0x02, // key
+		0x20, 0x03, // [type] = uint8, [data] (3)
+
+		binn.ObjectType,     // [type] object (container)
+		0x09,                // [size] container total size
+		0x01,                // [count] key/value pairs
+		0x03, 'k', 'e', 'y', // key
+		0x20, 0x04, // [type] = uint8, [data] (4)
+	}
+
+	var v []interface{}
+
+	err := decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		[]interface{}{uint8(1)},
+		map[int]interface{}{2: uint8(3)},
+		map[string]interface{}{"key": uint8(4)},
+	}, v)
+}
+
+func TestDecodeListToInterface(t *testing.T) {
+	b := []byte{
+		binn.ListType, // [type] list (container)
+		0x05,          // [size] container total size
+		0x01,          // [count] items
+		0x20, 0x7B,    // [type] = uint8, [data] (123)
+	}
+
+	var v interface{}
+
+	err := decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{uint8(123)}, v)
+}
+
+func TestDecodeTextUnmarshaler(t *testing.T) {
+	b := []byte{binn.StringType, 0x09, '1', '2', '7', '.', '0', '.', '0', '.', '1', 0x00}
+	var ip net.IP
+
+	err := decode.Unmarshal(b, &ip)
+
+	require.NoError(t, err)
+	assert.Equal(t, net.IPv4(127, 0, 0, 1), ip)
+}
+
+func TestDecodeTextUnmarshalerUserType(t *testing.T) {
+	b := []byte{0xB0, 0x01, 0x03, '1', '.', '2', 0x00} // [type] = user string type, [size], [data]
+	var v big.Float
+
+	err := decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.2", v.String())
+}
+
+func TestDecodeBinaryUnmarshaler(t *testing.T) {
+	tm := time.Date(2021, 5, 12, 10, 20, 30, 0, time.UTC)
+	data, err := tm.MarshalBinary()
+	require.NoError(t, err)
+	b := append([]byte{binn.BlobType, byte(len(data))}, data...)
+	var v time.Time
+
+	err = decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	assert.True(t, tm.Equal(v))
+}
+
+type textKey struct {
+	A, B byte
+}
+
+func (k *textKey) UnmarshalText(text []byte) error {
+	if len(text) != 3 || text[1] != ':' {
+		return errors.New("invalid key")
+	}
+
+	k.A, k.B = text[0], text[2]
+
+	return nil
+}
+
+func TestDecodeObjectWithTextUnmarshalerKeys(t *testing.T) {
+	b := []byte{
+		binn.ObjectType, // [type] object (container)
+		0x0B,            // [size] container total size
+		0x01,            // [count] key/value pairs
+
+		0x03, 'a', ':', 'b', // key
+		binn.StringType, 0x01, 'c', 0x00, // [type] = string, [data]
+	}
+	v := map[textKey]string{}
+
+	err := decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[textKey]string{{'a', 'b'}: "c"}, v)
+}
+
+func TestDecodeTextUnmarshalerStructField(t *testing.T) {
+	b := []byte{
+		binn.ObjectType, // [type] object (container)
+		0x13,            // [size] container total size
+		0x01,            // [count] key/value pairs
+
+		0x02, 'i', 'p', // key
+		binn.StringType, 0x09, '1', '2', '7', '.', '0', '.', '0', '.', '1', 0x00,
+	}
+	type host struct {
+		IP *net.IP `binn:"ip"`
+	}
+	var v host
+
+	err := decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	require.NotNil(t, v.IP)
+	assert.Equal(t, net.IPv4(127, 0, 0, 1), *v.IP)
+}
+
+type rawItem []byte
+
+func (r *rawItem) UnmarshalBINN(b []byte) error {
+	*r = append(rawItem{}, b...)
+
+	return nil
+}
+
+func TestDecodeNestedUnmarshaler(t *testing.T) {
+	b := []byte{
+		binn.ObjectType, // [type] object (container)
+		0x1E,            // [size] container total size
+		0x03,            // [count] key/value pairs
+
+		0x01, 'A', // key
+		binn.StringType, 0x02, 'h', 'i', 0x00, // [type] = string, [data]
+
+		0x01, 'B', // key
+		binn.ListType, 0x06, 0x02, // [type] list, [size], [count]
+		binn.Uint8Type, 0x01, // [type] = uint8, [data] (1)
+		binn.True, // [type] = true
+
+		0x01, 'C', // key
+		binn.ObjectType, 0x0A, 0x01, // [type] object, [size], [count]
+		0x01, 'k', // key
+		binn.ListType, 0x05, 0x01, binn.Uint8Type, 0x07, // [type] list, [size], [count], [data]
+	}
+	type obj struct {
+		A rawItem
+		B []rawItem
+		C map[string]*rawItem
+	}
+	var v obj
+
+	err := decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	assert.Equal(t, rawItem{binn.StringType, 0x02, 'h', 'i', 0x00}, v.A)
+	assert.Equal(t, []rawItem{{binn.Uint8Type, 0x01}, {binn.True}}, v.B)
+	require.Contains(t, v.C, "k")
+	assert.Equal(t, rawItem{binn.ListType, 0x05, 0x01, binn.Uint8Type, 0x07}, *v.C["k"])
+}
+
+func TestDecodeNestedUnmarshalerWithValueFields(t *testing.T) {
+	b := []byte{
+		binn.ListType, 0x12, 0x01, // [type] list, [size], [count]
+
+		binn.ListType, 0x0F, 0x02, // [type] list, [size], [count]
+		binn.Uint16Type, 0x01, 0xf4, // [type] = uint16, [data] (500)
+		binn.StringType, 0x06, 'c', 'u', 's', 't', 'o', 'm', 0x00, // [type] = string, [data]
+	}
+	var v []custom
+
+	err := decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	assert.Equal(t, []custom{{500, "custom"}}, v)
+}
+
+func TestDecodeIntOverflow(t *testing.T) {
+	b := []byte{binn.Uint16Type, 0x01, 0x2C} // [type] = uint16, [data] (300)
+	var v int8
+
+	err := decode.Unmarshal(b, &v)
+
+	var e *decode.OverflowError
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, "300", e.Value)
+	assert.Equal(t, int8(0), v)
+}
+
+func TestDecodeNegativeIntoUint(t *testing.T) {
+	b := []byte{binn.Int8Type, 0xFF} // [type] = int8, [data] (-1)
+	var v uint
+
+	err := decode.Unmarshal(b, &v)
+
+	var e *decode.OverflowError
+	require.ErrorAs(t, err, &e)
+}
+
+func TestDecodeReusesSliceBackingArray(t *testing.T) {
+	b := []byte{
+		binn.ListType, 0x07, 0x02, // [type] list, [size], [count]
+		binn.Uint8Type, 0x01, // [type] = uint8, [data] (1)
+		binn.Uint8Type, 0x02, // [type] = uint8, [data] (2)
+	}
+	v := make([]int, 5, 10)
+
+	err := decode.Unmarshal(b, &v)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, v)
+	assert.Equal(t, 10, cap(v))
+}
+
+func TestDecodeDoesNotRetainInput(t *testing.T) {
+	b := []byte{
+		binn.ListType, 0x0C, 0x02, // [type] list, [size], [count]
+		binn.StringType, 0x02, 'h', 'i', 0x00, // [type] = string, [data]
+		binn.BlobType, 0x01, 0x07, // [type] = blob, [size], [data]
+	}
+	var v []interface{}
+
+	err := decode.Unmarshal(b, &v)
+	for i := range b {
+		b[i] = 0
+	}
+
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"hi", []byte{0x07}}, v)
+}
+
+func TestDecodeScalarsWithoutAllocations(t *testing.T) {
+	b := []byte{
+		binn.ListType, 0x0D, 0x03, // [type] list, [size], [count]
+		binn.Uint8Type, 0x01, // [type] = uint8, [data] (1)
+		binn.Int16Type, 0xFF, 0xFE, // [type] = int16, [data] (-2)
+		binn.Float32Type, 0x3F, 0xC0, 0x00, 0x00, // [type] = float32, [data] (1.5)
+	}
+	v := make([]float64, 0, 3)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := decode.Unmarshal(b, &v); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	assert.Equal(t, []float64{1, -2, 1.5}, v)
+	assert.Zero(t, allocs)
+}
+
+func TestDecoderHugeSizeWithShortInput(t *testing.T) {
+	b := []byte{binn.BlobType, 0xFF, 0xFF, 0xFF, 0xFF, 0x01, 0x02} // [type] = blob, [size], [data]
+	var v []byte
+
+	err := decode.NewDecoder(bytes.NewReader(b)).Decode(&v)
+
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestDecoderRecords(t *testing.T) {
+	b := []byte{
+		binn.Uint8Type, 0x01,				// [type] = uint8, [data] (1)
+		binn.StringType, 0x01, 'a', 0x00,	// [type] = string, [size], [data] (null terminated)
+		binn.ListType, 0x04, 0x01,			// [type] list (container), [size], [count]
+		binn.True,							// [type] = true
+		binn.Null,							// [type] = null
+	}
+	dec := decode.NewDecoder(bytes.NewReader(b))
+	expected := []struct {
+		value  interface{}
+		offset int64
+	}{
+		{uint8(1), 2},
+		{"a", 6},
+		{[]interface{}{true}, 10},
+		{nil, 11},
+	}
+
+	for _, e := range expected {
+		var v interface{}
+		require.NoError(t, dec.Decode(&v))
+		assert.Equal(t, e.value, v)
+		assert.Equal(t, e.offset, dec.InputOffset())
+	}
+
+	var v interface{}
+	assert.Equal(t, io.EOF, dec.Decode(&v))
+	assert.Equal(t, int64(len(b)), dec.InputOffset())
+}
+
+func TestDecoderUnexpectedEOF(t *testing.T) {
+	records := [][]byte{
+		{binn.Uint16Type, 0x01, 0x2C},	// [type] = uint16, [data] (300)
+		{
+			0xB0, 0x01,					// [type] = user string type, 2 bytes
+			0x80, 0x00, 0x00, 0x02,		// [size]
+			'h', 'i', 0x00,				// [data] (null terminated)
+		},
+		{
+			binn.ObjectType, 0x06, 0x01,	// [type] object (container), [size], [count]
+			0x01, 'a',						// key
+			binn.False,						// [type] = false
+		},
+	}
+	values := []interface{}{uint16(300), "hi", map[string]interface{}{"a": false}}
+
+	var stream []byte
+	for _, r := range records {
+		stream = append(stream, r...)
+	}
+
+	for n := 1; n < len(stream); n++ {
+		dec := decode.NewDecoder(bytes.NewReader(stream[:n]))
+
+		var (
+			i   int
+			end int64
+			err error
+		)
+		for ; ; i++ {
+			var v interface{}
+			if err = dec.Decode(&v); err != nil {
+				break
+			}
+			assert.Equal(t, values[i], v)
+			end += int64(len(records[i]))
+		}
+
+		if end == int64(n) {
+			assert.Equal(t, io.EOF, err, "cut at %d", n)
+		} else {
+			assert.Equal(t, io.ErrUnexpectedEOF, err, "cut at %d", n)
+		}
+		assert.Equal(t, end, dec.InputOffset(), "cut at %d", n)
+
+		// Resume from the offset once the stream is complete.
+		dec = decode.NewDecoder(bytes.NewReader(stream[dec.InputOffset():]))
+		for ; i < len(values); i++ {
+			var v interface{}
+			require.NoError(t, dec.Decode(&v))
+			assert.Equal(t, values[i], v)
+		}
+	}
+}
+
+func TestDecodeMapIntKeys(t *testing.T) {
+	b := []byte{
+		binn.MapType, 0x08, 0x01,	// [type] map, [size], [count]
+		0x00, 0x00, 0x00, 0xC8,		// [key] (200)
+		binn.True,					// [type] = true
+	}
+
+	var u8 map[uint8]bool
+	require.NoError(t, decode.Unmarshal(b, &u8))
+	assert.Equal(t, map[uint8]bool{200: true}, u8)
+
+	var i16 map[int16]bool
+	require.NoError(t, decode.Unmarshal(b, &i16))
+	assert.Equal(t, map[int16]bool{200: true}, i16)
+
+	var u64 map[uint64]bool
+	require.NoError(t, decode.Unmarshal(b, &u64))
+	assert.Equal(t, map[uint64]bool{200: true}, u64)
+
+	var i8 map[int8]bool
+	var e *decode.OverflowError
+	require.ErrorAs(t, decode.Unmarshal(b, &i8), &e)
+	assert.Equal(t, "200", e.Value)
+}
+
+func TestDecodeNegativeMapKeyIntoUint(t *testing.T) {
+	b := []byte{
+		binn.MapType, 0x08, 0x01,	// [type] map, [size], [count]
+		0xFF, 0xFF, 0xFF, 0xFF,		// [key] (-1)
+		binn.True,					// [type] = true
+	}
+	var v map[uint]bool
+
+	err := decode.Unmarshal(b, &v)
+
+	var e *decode.OverflowError
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, "-1", e.Value)
+}
+
+func TestDecodeMapIntoStruct(t *testing.T) {
+	type msg struct {
+		ID   int    `binn:"#1"`
+		Name string `binn:"#-2"`
+	}
+	b := []byte{
+		binn.MapType, 0x11, 0x02,			// [type] map, [size], [count]
+		0xFF, 0xFF, 0xFF, 0xFE,				// [key] (-2)
+		binn.StringType, 0x01, 'a', 0x00,	// [type] = string, [size], [data]
+		0x00, 0x00, 0x00, 0x01,				// [key]
+		binn.Uint8Type, 0x05,				// [type] = uint8, [data]
+	}
+	var v msg
+
+	require.NoError(t, decode.Unmarshal(b, &v))
+	assert.Equal(t, msg{5, "a"}, v)
+
+	b[6] = 0x03 // [key] (3)
+	err := decode.Unmarshal(b, &v)
+	assert.ErrorIs(t, err, decode.ErrItemNotFound)
+
+	type obj struct {
+		ID int `binn:"id"`
+	}
+	var o obj
+	var u *decode.UnknownValueError
+	assert.ErrorAs(t, decode.Unmarshal(b, &o), &u)
+}
+
+func TestDecoderNaming(t *testing.T) {
+	type user struct {
+		UserID    int
+		FirstName string `binn:"name"`
+	}
+	b := []byte{
+		binn.ObjectType, 0x16, 0x02,				// [type] object, [size], [count]
+		0x07, 'u', 's', 'e', 'r', '_', 'i', 'd',	// [key]
+		binn.Uint8Type, 0x01,						// [type] = uint8, [data]
+		0x04, 'n', 'a', 'm', 'e',					// [key]
+		binn.StringType, 0x01, 'a', 0x00,			// [type] = string, [size], [data]
+	}
+
+	var v user
+	err := decode.NewDecoder(bytes.NewReader(b)).Decode(&v)
+	assert.ErrorIs(t, err, decode.ErrItemNotFound)
+
+	dec := decode.NewDecoder(bytes.NewReader(b))
+	dec.SetNaming(naming.SnakeCase)
+
+	require.NoError(t, dec.Decode(&v))
+	assert.Equal(t, user{1, "a"}, v)
+}
+
+func TestDecoderCaseInsensitive(t *testing.T) {
+	type user struct {
+		Name  string `binn:"name"`
+		Alias string `binn:"NAME"`
+		ID    int
+	}
+	b := []byte{
+		binn.ObjectType, 0x1A, 0x03,		// [type] object, [size], [count]
+		0x04, 'n', 'a', 'm', 'e',			// [key]
+		binn.StringType, 0x01, 'a', 0x00,	// [type] = string, [size], [data]
+		0x04, 'N', 'a', 'm', 'E',			// [key]
+		binn.StringType, 0x01, 'b', 0x00,	// [type] = string, [size], [data]
+		0x02, 'i', 'd',						// [key]
+		binn.Uint8Type, 0x01,				// [type] = uint8, [data]
+	}
+
+	var v user
+	err := decode.NewDecoder(bytes.NewReader(b)).Decode(&v)
+	assert.ErrorIs(t, err, decode.ErrItemNotFound)
+
+	dec := decode.NewDecoder(bytes.NewReader(b))
+	dec.SetCaseInsensitive(true)
+
+	require.NoError(t, dec.Decode(&v))
+	assert.Equal(t, user{Name: "b", ID: 1}, v)
+}
+
+// nestedLists returns the encoding of n lists nested in each other.
+func nestedLists(n int) []byte {
+	sizes := make([]int, n)
+	for i := range sizes {
+		size := 3
+		if i > 0 {
+			size = sizes[i-1] + 3
+		}
+		if size > 127 {
+			size += 3
+		}
+		sizes[i] = size
+	}
+
+	b := make([]byte, 0, sizes[n-1])
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, binn.ListType)
+		if sizes[i] > 127 {
+			b = append(b, byte(sizes[i]>>24)|0x80, byte(sizes[i]>>16), byte(sizes[i]>>8), byte(sizes[i]))
+		} else {
+			b = append(b, byte(sizes[i]))
+		}
+		if i > 0 {
+			b = append(b, 1)
+		} else {
+			b = append(b, 0)
+		}
+	}
+
+	return b
+}
+
+type recursiveList []recursiveList
+
+func TestDecodeMaxDepth(t *testing.T) {
+	var v interface{}
+	require.NoError(t, decode.Unmarshal(nestedLists(decode.MaxDepth), &v))
+
+	var l recursiveList
+	require.NoError(t, decode.Unmarshal(nestedLists(decode.MaxDepth), &l))
+
+	deep := nestedLists(decode.MaxDepth + 1)
+
+	assert.ErrorIs(t, decode.Unmarshal(deep, &v), decode.ErrTooDeep)
+	assert.ErrorIs(t, decode.Unmarshal(deep, &l), decode.ErrTooDeep)
+	assert.ErrorIs(t, decode.NewDecoder(bytes.NewReader(deep)).Decode(&v), decode.ErrTooDeep)
+
+	// Headers of lists claiming more bytes than there are.
+	forged := bytes.Repeat([]byte{binn.ListType, 0x7F, 0x01}, decode.MaxDepth+1)
+	assert.ErrorIs(t, decode.Unmarshal(forged, &v), decode.ErrTooDeep)
 }
@@ -13,6 +13,7 @@ var (
 	ErrInvalidItem        = errors.New("invalid item")
 	ErrInvalidStructValue = errors.New("invalid struct value")
 	ErrIncompleteRead     = errors.New("incomplete read")
+	ErrTooDeep            = errors.New("exceeded max depth")
 )
 
 type FailedToReadTypeError struct {
@@ -82,3 +83,14 @@ type UnknownValueError struct {
 func (e *UnknownValueError) Error() string {
 	return "binn: Unknown value. Expected " + e.Expected.String() + ", got " + e.Got.String()
 }
+
+// OverflowError is returned when a decoded number doesn't fit
+// into the destination type.
+type OverflowError struct {
+	Value string
+	Type  reflect.Type
+}
+
+func (e *OverflowError) Error() string {
+	return "binn: value " + e.Value + " overflows " + e.Type.String()
+}
@@ -0,0 +1,104 @@
+//go:build go1.18
+// +build go1.18
+
+package decode_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+)
+
+// seeds are the test vectors the fuzz targets start from: the binary
+// files of the tests and a few items exercising the size encodings.
+func seeds(f *testing.F) {
+	f.Helper()
+
+	for _, pattern := range []string{
+		"../test/binary/*.bin",
+		"../test/golden/testdata/*.bin",
+	} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(b)
+		}
+	}
+
+	f.Add([]byte{binn.Null})
+	f.Add([]byte{binn.StringType, 0x00, 0x00})
+	f.Add([]byte{binn.BlobType, 0x80, 0x00, 0x00, 0x01, 0xFF})
+	f.Add([]byte{binn.ListType, 0x03, 0x00})
+	f.Add([]byte{binn.ListType, 0x80, 0x00, 0x00, 0x06, 0x80, 0x00, 0x00, 0x01, binn.Uint8Type, 0x01})
+	f.Add([]byte{binn.MapType, 0x0A, 0x01, 0x00, 0x00, 0x00, 0x01, binn.Int8Type, 0xFF})
+	f.Add([]byte{binn.ObjectType, 0x07, 0x01, 0x01, 'a', binn.True})
+	f.Add([]byte{0xB0, 0x01, 0x02, 'h', 'i', 0x00})
+}
+
+// FuzzUnmarshalInterface and the other fuzz targets check that malformed
+// input returns an error instead of panicking.
+func FuzzUnmarshalInterface(f *testing.F) {
+	seeds(f)
+	f.Add(bytes.Repeat([]byte{binn.ListType, 0x7F, 0x01}, decode.MaxDepth+1))
+
+	f.Fuzz(func(_ *testing.T, b []byte) {
+		var v interface{}
+		_ = decode.Unmarshal(b, &v)
+
+		var sv interface{}
+		_ = decode.NewDecoder(bytes.NewReader(b)).Decode(&sv)
+	})
+}
+
+type fuzzStruct struct {
+	ID       int                    `binn:"id"`
+	Name     string                 `binn:"name"`
+	Flag     bool                   `binn:"flag"`
+	Ratio    float64                `binn:"ratio"`
+	Small    int8                   `binn:"small"`
+	Data     []byte                 `binn:"data"`
+	Tags     []string               `binn:"tags"`
+	Array    [2]uint16              `binn:"array"`
+	Children []*fuzzStruct          `binn:"children"`
+	Attrs    map[string]interface{} `binn:"attrs"`
+	Index    map[int]string         `binn:"index"`
+	Any      interface{}            `binn:"any"`
+}
+
+func FuzzUnmarshalStruct(f *testing.F) {
+	seeds(f)
+
+	f.Fuzz(func(_ *testing.T, b []byte) {
+		var v fuzzStruct
+		_ = decode.Unmarshal(b, &v)
+
+		var sv []fuzzStruct
+		_ = decode.NewDecoder(bytes.NewReader(b)).Decode(&sv)
+	})
+}
+
+func FuzzUnmarshalMap(f *testing.F) {
+	seeds(f)
+
+	f.Fuzz(func(_ *testing.T, b []byte) {
+		var objects map[string]interface{}
+		_ = decode.Unmarshal(b, &objects)
+
+		var maps map[int32][]int
+		_ = decode.Unmarshal(b, &maps)
+
+		var uints map[uint8]string
+		_ = decode.Unmarshal(b, &uints)
+	})
+}
@@ -0,0 +1,299 @@
+package decode
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+// The Read...Bytes functions read a single item from the start of b and
+// return the remaining bytes. They index directly into b and accept the
+// same items Unmarshal accepts for the values of the matching kinds.
+// They are used by the code generated by binngo-gen.
+
+var (
+	intTypes = [...]reflect.Type{
+		reflect.TypeOf(int8(0)), reflect.TypeOf(int16(0)),
+		reflect.TypeOf(int32(0)), reflect.TypeOf(int64(0)),
+	}
+	uintTypes = [...]reflect.Type{
+		reflect.TypeOf(uint8(0)), reflect.TypeOf(uint16(0)),
+		reflect.TypeOf(uint32(0)), reflect.TypeOf(uint64(0)),
+	}
+	floatTypes = [...]reflect.Type{
+		reflect.TypeOf(float32(0)), reflect.TypeOf(float64(0)),
+	}
+)
+
+// bitSizeIndex maps the bit sizes 8, 16, 32 and 64 to 0, 1, 2 and 3.
+func bitSizeIndex(bitSize int) int {
+	switch bitSize {
+	case 8:
+		return 0
+	case 16:
+		return 1
+	case 32:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// ReadNullBytes reports whether b starts with a null item,
+// and if so returns the bytes after it.
+func ReadNullBytes(b []byte) ([]byte, bool) {
+	if len(b) > 0 && b[0] == binn.Null {
+		return b[1:], true
+	}
+
+	return b, false
+}
+
+// ReadBoolBytes reads a boolean item. A null item reads as false.
+func ReadBoolBytes(b []byte) (bool, []byte, error) {
+	d := decodeState{data: b}
+
+	v, _, err := d.scanBool()
+	if err != nil {
+		return false, b, err
+	}
+
+	return v, b[d.off:], nil
+}
+
+// ReadIntBytes reads a number that fits into a signed integer of
+// the bitSize bits. A null item reads as zero.
+func ReadIntBytes(b []byte, bitSize int) (int64, []byte, error) {
+	d := decodeState{data: b}
+	t := intTypes[bitSizeIndex(bitSize)]
+
+	i, _, err := d.scanInt(t)
+	if err != nil {
+		return 0, b, err
+	}
+
+	if shift := 64 - uint(t.Bits()); i<<shift>>shift != i {
+		return 0, b, &OverflowError{strconv.FormatInt(i, 10), t}
+	}
+
+	return i, b[d.off:], nil
+}
+
+// ReadUintBytes reads a number that fits into an unsigned integer of
+// the bitSize bits. A null item reads as zero.
+func ReadUintBytes(b []byte, bitSize int) (uint64, []byte, error) {
+	d := decodeState{data: b}
+	t := uintTypes[bitSizeIndex(bitSize)]
+
+	u, _, err := d.scanUint(t)
+	if err != nil {
+		return 0, b, err
+	}
+
+	if shift := 64 - uint(t.Bits()); u<<shift>>shift != u {
+		return 0, b, &OverflowError{strconv.FormatUint(u, 10), t}
+	}
+
+	return u, b[d.off:], nil
+}
+
+// ReadFloatBytes reads a number as a float of the bitSize bits.
+// A null item reads as zero.
+func ReadFloatBytes(b []byte, bitSize int) (float64, []byte, error) {
+	d := decodeState{data: b}
+	t := floatTypes[1]
+	if bitSize == 32 {
+		t = floatTypes[0]
+	}
+
+	f, _, err := d.scanFloat(t)
+	if err != nil {
+		return 0, b, err
+	}
+
+	if bitSize == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+		return 0, b, &OverflowError{strconv.FormatFloat(f, 'g', -1, 64), t}
+	}
+
+	return f, b[d.off:], nil
+}
+
+// ReadStringBytes reads a string item and returns a copy of it.
+// A null item reads as an empty string.
+func ReadStringBytes(b []byte) (string, []byte, error) {
+	d := decodeState{data: b}
+
+	s, _, err := d.scanString()
+	if err != nil {
+		return "", b, err
+	}
+
+	return s, b[d.off:], nil
+}
+
+// ReadSizeBytes reads a size stored in 1 or 4 bytes, such as the size
+// of a string or a container or the items count of a container.
+func ReadSizeBytes(b []byte) (int, []byte, error) {
+	d := decodeState{data: b}
+
+	sz, err := d.readSize()
+	if err != nil {
+		return 0, b, err
+	}
+
+	return sz, b[d.off:], nil
+}
+
+// ReadTypeBytes returns the type of the item at the start of b.
+func ReadTypeBytes(b []byte) (binn.Type, error) {
+	d := decodeState{data: b}
+
+	return d.peekType()
+}
+
+// ReadValueBytes reads an item that isn't a container. It returns the
+// type of the item, its value and the bytes after it. The value refers
+// to b: it holds the bytes of numbers, strings without the null
+// terminator and blobs.
+func ReadValueBytes(b []byte) (binn.Type, []byte, []byte, error) {
+	d := decodeState{data: b}
+
+	btype, err := d.readType()
+	if err != nil {
+		return 0, nil, b, err
+	}
+
+	if btype.IsContainer() {
+		return 0, nil, b, ErrInvalidItem
+	}
+
+	v, err := d.readValue(btype)
+	if err != nil {
+		return 0, nil, b, err
+	}
+
+	return btype, v, b[d.off:], nil
+}
+
+// ReadItemBytes returns the complete encoding of the item
+// at the start of b and the bytes after it.
+func ReadItemBytes(b []byte) ([]byte, []byte, error) {
+	d := decodeState{data: b}
+
+	item, err := d.rawItem()
+	if err != nil {
+		return nil, b, err
+	}
+
+	return item, b[d.off:], nil
+}
+
+// SkipBytes returns the bytes after the item at the start of b.
+func SkipBytes(b []byte) ([]byte, error) {
+	_, rest, err := ReadItemBytes(b)
+
+	return rest, err
+}
+
+// ReadListBytes reads the header of a list. It returns the items count,
+// the bytes of the items and the bytes after the list.
+func ReadListBytes(b []byte) (int, []byte, []byte, error) {
+	return readContainerBytes(b, binn.ListType, reflect.Slice)
+}
+
+// ReadMapBytes reads the header of a map. It returns the items count,
+// the bytes of the items and the bytes after the map.
+func ReadMapBytes(b []byte) (int, []byte, []byte, error) {
+	return readContainerBytes(b, binn.MapType, reflect.Map)
+}
+
+// ReadObjectBytes reads the header of an object. It returns the items
+// count, the bytes of the items and the bytes after the object.
+func ReadObjectBytes(b []byte) (int, []byte, []byte, error) {
+	return readContainerBytes(b, binn.ObjectType, reflect.Map)
+}
+
+func readContainerBytes(b []byte, containerType binn.Type, k reflect.Kind) (int, []byte, []byte, error) {
+	d := decodeState{data: b}
+
+	btype, err := d.readType()
+	if err != nil {
+		return 0, nil, b, err
+	}
+
+	if btype != containerType {
+		d.off = 0
+		return 0, nil, b, d.mismatch(btype, k)
+	}
+
+	end, cnt, err := d.readContainer(btype)
+	if err != nil {
+		return 0, nil, b, err
+	}
+
+	// Every item takes at least one byte.
+	if cnt > end-d.off {
+		return 0, nil, b, ErrInvalidItem
+	}
+
+	return cnt, b[d.off:end], b[end:], nil
+}
+
+// ReadObjectKeyBytes reads the key of an object item.
+// The returned key refers to b.
+func ReadObjectKeyBytes(b []byte) ([]byte, []byte, error) {
+	d := decodeState{data: b}
+
+	k, err := d.readObjectKey()
+	if err != nil {
+		return nil, b, err
+	}
+
+	return k, b[d.off:], nil
+}
+
+// ReadMapKeyBytes reads the key of a map item.
+func ReadMapKeyBytes(b []byte) (int32, []byte, error) {
+	d := decodeState{data: b}
+
+	k, err := d.readMapKey()
+	if err != nil {
+		return 0, b, err
+	}
+
+	return k, b[d.off:], nil
+}
+
+// ReadIntMapKeyBytes reads the key of a map item that fits into
+// a signed integer of the bitSize bits.
+func ReadIntMapKeyBytes(b []byte, bitSize int) (int64, []byte, error) {
+	k, rest, err := ReadMapKeyBytes(b)
+	if err != nil {
+		return 0, b, err
+	}
+
+	t := intTypes[bitSizeIndex(bitSize)]
+	if shift := 64 - uint(t.Bits()); int64(k)<<shift>>shift != int64(k) {
+		return 0, b, &OverflowError{strconv.Itoa(int(k)), t}
+	}
+
+	return int64(k), rest, nil
+}
+
+// ReadUintMapKeyBytes reads the key of a map item that fits into
+// an unsigned integer of the bitSize bits.
+func ReadUintMapKeyBytes(b []byte, bitSize int) (uint64, []byte, error) {
+	k, rest, err := ReadMapKeyBytes(b)
+	if err != nil {
+		return 0, b, err
+	}
+
+	t := uintTypes[bitSizeIndex(bitSize)]
+	if shift := 64 - uint(t.Bits()); k < 0 || uint64(k)<<shift>>shift != uint64(k) {
+		return 0, b, &OverflowError{strconv.Itoa(int(k)), t}
+	}
+
+	return uint64(k), rest, nil
+}
@@ -0,0 +1,117 @@
+package decode_test
+
+import (
+	"testing"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBytes(t *testing.T) {
+	b := []byte{
+		binn.ObjectType, 0x0F, 0x02, // [type] object, [size], [count]
+		0x01, 'a', // key
+		binn.Uint16Type, 0x01, 0x2C, // [type] = uint16, [data] (300)
+		0x01, 'b', // key
+		binn.StringType, 0x02, 'h', 'i', 0x00, // [type] = string, [data]
+		binn.True, // next item
+	}
+
+	n, items, rest, err := decode.ReadObjectBytes(b)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []byte{binn.True}, rest)
+
+	key, items, err := decode.ReadObjectKeyBytes(items)
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(key))
+
+	_, _, err = decode.ReadIntBytes(items, 8)
+	var e *decode.OverflowError
+	require.ErrorAs(t, err, &e)
+
+	i, items, err := decode.ReadIntBytes(items, 16)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), i)
+
+	_, items, err = decode.ReadObjectKeyBytes(items)
+	require.NoError(t, err)
+	s, items, err := decode.ReadStringBytes(items)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", s)
+	assert.Empty(t, items)
+
+	rest, isNull := decode.ReadNullBytes(rest)
+	assert.False(t, isNull)
+	v, rest, err := decode.ReadBoolBytes(rest)
+	require.NoError(t, err)
+	assert.True(t, v)
+	assert.Empty(t, rest)
+}
+
+func TestReadListBytesMismatch(t *testing.T) {
+	b := []byte{binn.ObjectType, 0x03, 0x00} // [type] object, [size], [count]
+
+	_, _, rest, err := decode.ReadListBytes(b)
+
+	var e *decode.UnknownValueError
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, b, rest)
+}
+
+func TestReadValueBytes(t *testing.T) {
+	b := []byte{
+		0xB0, 0x01, 0x02, 'h', 'i', 0x00, // [type] = user string type, [size], [data]
+		binn.ListType, 0x03, 0x00,
+	}
+
+	btype, err := decode.ReadTypeBytes(b)
+	require.NoError(t, err)
+	assert.Equal(t, binn.Type(0xB001), btype)
+
+	btype, v, rest, err := decode.ReadValueBytes(b)
+	require.NoError(t, err)
+	assert.Equal(t, binn.Type(0xB001), btype)
+	assert.Equal(t, []byte("hi"), v)
+
+	_, _, _, err = decode.ReadValueBytes(rest)
+	assert.ErrorIs(t, err, decode.ErrInvalidItem)
+}
+
+func TestReadIntMapKeyBytes(t *testing.T) {
+	b := []byte{0xFF, 0xFF, 0xFF, 0x80, binn.True} // [key] (-128)
+
+	k, rest, err := decode.ReadIntMapKeyBytes(b, 8)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-128), k)
+	assert.Equal(t, []byte{binn.True}, rest)
+
+	_, rest, err = decode.ReadUintMapKeyBytes(b, 64)
+	var e *decode.OverflowError
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, b, rest)
+
+	u, _, err := decode.ReadUintMapKeyBytes([]byte{0x00, 0x00, 0x01, 0x00}, 16)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(256), u)
+
+	_, _, err = decode.ReadUintMapKeyBytes([]byte{0x00, 0x00, 0x01, 0x00}, 8)
+	require.ErrorAs(t, err, &e)
+}
+
+func TestReadSizeBytes(t *testing.T) {
+	sz, rest, err := decode.ReadSizeBytes([]byte{0x7F, binn.True})
+	require.NoError(t, err)
+	assert.Equal(t, 127, sz)
+	assert.Equal(t, []byte{binn.True}, rest)
+
+	sz, rest, err = decode.ReadSizeBytes([]byte{0x80, 0x00, 0x01, 0x00})
+	require.NoError(t, err)
+	assert.Equal(t, 256, sz)
+	assert.Empty(t, rest)
+
+	_, _, err = decode.ReadSizeBytes([]byte{0x80, 0x00})
+	assert.ErrorIs(t, err, decode.ErrIncompleteRead)
+}
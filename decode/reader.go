@@ -1,124 +1,190 @@
 package decode
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
 
 	"github.com/et-nik/binngo/binn"
-	"github.com/et-nik/binngo/encode"
 )
 
-//nolint:funlen
-func readValue(btype binn.Type, reader io.Reader) ([]byte, error) {
-	tp := btype &^ binn.StorageTypeMask
+// maxPreallocSize limits the buffer allocated upfront for an item read
+// from a stream, larger items grow the buffer as their data arrives.
+const maxPreallocSize = 64 << 10
+
+// readItem reads a single complete item from the reader and returns
+// its encoding, starting with the type byte.
+//
+// It returns io.EOF if the reader ends before the item, and
+// io.ErrUnexpectedEOF if the reader ends in the middle of the item.
+// A container running past the end of the reader is returned as short,
+// its items may still be complete, which is checked by the decoder.
+func readItem(reader io.Reader) (item []byte, short bool, err error) {
+	btype, _, err := readType(reader)
+	if err != nil {
+		var te *FailedToReadTypeError
+		if errors.As(err, &te) && te.Previous == io.EOF {
+			return nil, false, io.EOF
+		}
+
+		return nil, false, unexpectedEOF(err)
+	}
 
-	var readingSize int
-	var containerSize int
+	item = appendType(make([]byte, 0, 16), btype)
 
-	var bytes []byte
+	var n int
 
-	switch tp {
+	switch btype.Storage() {
 	case binn.StorageNoBytes:
-		readingSize = 0
+		return item, false, nil
 	case binn.StorageByte:
-		readingSize = 1
+		n = 1
 	case binn.StorageWord:
-		readingSize = 2
+		n = 2
 	case binn.StorageDWord:
-		readingSize = 4
+		n = 4
 	case binn.StorageQWord:
-		readingSize = 8
-	case binn.StorageString:
-		dataSize, _, err := readSize(reader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read string storage size: %w", err)
-		}
-		readingSize = dataSize + 1 // data size and null terminator
-	case binn.StorageBlob:
-		dataSize, _, err := readSize(reader)
+		n = 8
+	case binn.StorageString, binn.StorageBlob, binn.StorageContainer:
+		sz, l, err := readSize(reader)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read string storage size: %w", err)
+			return nil, false, unexpectedEOF(fmt.Errorf("failed to read storage size: %w", err))
 		}
-		readingSize = dataSize
-	case binn.StorageContainer:
-		s, l, err := readSize(reader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read storage size: %w", err)
+
+		item = appendSize(item, sz, l)
+
+		switch btype.Storage() {
+		case binn.StorageString:
+			n = sz + 1 // data size and null terminator
+		case binn.StorageBlob:
+			n = sz
+		default:
+			n = sz - len(item) // the size includes the type byte and the size bytes
+			if n < 0 {
+				return nil, false, ErrInvalidItem
+			}
 		}
+	default:
+		return nil, false, ErrUnknownType
+	}
 
-		containerSize = s
+	item, err = readFull(reader, item, n)
+	if btype.Storage() == binn.StorageContainer && errors.Is(err, io.ErrUnexpectedEOF) {
+		return item, true, nil
+	}
+	if err != nil {
+		return nil, false, unexpectedEOF(fmt.Errorf("failed to read storage: %w", err))
+	}
 
-		bytes = append(bytes, encode.Size(s, false)...)
+	return item, false, nil
+}
 
-		readingSize = containerSize - 1 - int(l) // minus container type byte and size byte
-	default:
-		return nil, ErrUnknownType
+// unmarshalItem decodes an item read by readItem with dec. The items
+// missing from a short container are reported as io.ErrUnexpectedEOF.
+func unmarshalItem(item []byte, short bool, dec decoderFunc, v reflect.Value, opts decodeOptions) error {
+	d := decodeStatePool.Get().(*decodeState)
+	d.init(item).opts = opts
+	err := dec(d, v)
+	d.release()
+
+	if short && errors.Is(err, ErrIncompleteRead) {
+		return io.ErrUnexpectedEOF
 	}
 
-	if readingSize == 0 {
-		return []byte{byte(btype)}, nil
+	return err
+}
+
+// unexpectedEOF returns io.ErrUnexpectedEOF for the errors of a reader
+// that ended in the middle of an item, and err for the other errors.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return io.ErrUnexpectedEOF
 	}
 
-	b := make([]byte, readingSize)
-	read := 0
+	return err
+}
 
-	for read < readingSize {
-		n, err := reader.Read(b[read:])
-		if err != nil {
-			return nil, fmt.Errorf("failed to read storage: %w", err)
-		}
+// readFull appends exactly n bytes read from the reader to b.
+func readFull(reader io.Reader, b []byte, n int) ([]byte, error) {
+	if n <= maxPreallocSize {
+		start := len(b)
+		b = append(b, make([]byte, n)...)
+		read, err := io.ReadFull(reader, b[start:])
 
-		read += n
+		return b[:start+read], err
 	}
 
-	bytes = append(bytes, b...)
+	buf := bytes.NewBuffer(b)
+
+	written, err := io.CopyN(buf, reader, int64(n))
+	if err == nil && written != int64(n) {
+		err = io.ErrUnexpectedEOF
+	}
 
-	return bytes, nil
+	return buf.Bytes(), err
 }
 
-func isStorageContainer(btype binn.Type) bool {
-	return (btype &^ binn.StorageTypeMask) == binn.StorageContainer
+func appendSize(b []byte, sz int, l readLen) []byte {
+	if l == 1 {
+		return append(b, byte(sz))
+	}
+
+	return append(b, byte(sz>>24)|0x80, byte(sz>>16), byte(sz>>8), byte(sz))
 }
 
 func readType(reader io.Reader) (binn.Type, readLen, error) {
-	var bt = make([]byte, 1)
+	var bt [2]byte
 
-	_, err := reader.Read(bt)
+	_, err := io.ReadFull(reader, bt[:1])
 	if err != nil {
 		return binn.Null, 0, &FailedToReadTypeError{Previous: err}
 	}
 
-	return Type(bt), 1, nil
-}
+	if bt[0]&binn.StorageHasMore == 0 {
+		return binn.Type(bt[0]), 1, nil
+	}
 
-func readSize(reader io.Reader) (int, readLen, error) {
-	var bsz = make([]byte, 1)
-	_, err := reader.Read(bsz)
+	_, err = io.ReadFull(reader, bt[1:])
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
 	if err != nil {
-		return 0, 0, &FailedToReadSizeError{err}
+		return binn.Null, 0, &FailedToReadTypeError{Previous: err}
 	}
 
-	read := 1
+	return binn.Type(bt[0])<<8 | binn.Type(bt[1]), 2, nil
+}
 
-	sz := int(Uint8(bsz))
+// appendType appends the one or two bytes of the type.
+func appendType(b []byte, t binn.Type) []byte {
+	if t > 0xFF {
+		return append(b, byte(t>>8), byte(t))
+	}
 
-	if sz > maxOneByteSize {
-		var bszOtherBytes = make([]byte, 3)
-		_, err := reader.Read(bszOtherBytes)
-		if err != nil {
-			return 0, 0, fmt.Errorf("failed to read long size: %w", err)
-		}
-		read += 3
+	return append(b, byte(t))
+}
 
-		sz = int(Uint32([]byte{
-			byte(sz),
-			bszOtherBytes[0],
-			bszOtherBytes[1],
-			bszOtherBytes[2],
-		}))
+func readSize(reader io.Reader) (int, readLen, error) {
+	var bsz [4]byte
 
-		sz &= 0x7FFFFFFF
+	_, err := io.ReadFull(reader, bsz[:1])
+	if err != nil {
+		return 0, 0, &FailedToReadSizeError{err}
 	}
 
-	return sz, readLen(read), nil
+	sz := int(bsz[0])
+	if sz <= maxOneByteSize {
+		return sz, 1, nil
+	}
+
+	_, err = io.ReadFull(reader, bsz[1:])
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read long size: %w", err)
+	}
+
+	sz = int(Uint32(bsz[:]) & 0x7FFFFFFF)
+
+	return sz, 4, nil
 }
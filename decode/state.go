@@ -0,0 +1,300 @@
+package decode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/naming"
+)
+
+const mapKeySize = 4
+
+// MaxDepth is the maximum nesting depth of the containers decoded
+// into Go values.
+const MaxDepth = 10000
+
+// decodeState holds the input of a single decoding and the read offset.
+// Decoders index directly into the input and never copy it, except for
+// strings and blobs stored into the destination value.
+type decodeState struct {
+	data []byte
+	off  int
+	opts decodeOptions
+	// depth is the number of the containers being decoded.
+	depth int
+
+	// keyBuf holds the folded object keys.
+	keyBuf []byte
+}
+
+// decodeOptions are the options set on a Decoder.
+type decodeOptions struct {
+	naming          *naming.Strategy
+	caseInsensitive bool
+}
+
+var decodeStatePool = sync.Pool{
+	New: func() interface{} {
+		return new(decodeState)
+	},
+}
+
+// release returns the decodeState to the pool, dropping the reference
+// to the input.
+func (d *decodeState) release() {
+	d.data = nil
+	decodeStatePool.Put(d)
+}
+
+func (d *decodeState) init(data []byte) *decodeState {
+	d.data = data
+	d.off = 0
+	d.opts = decodeOptions{}
+	d.depth = 0
+
+	return d
+}
+
+// peekType returns the type of the item at the current offset.
+// Types with the StorageHasMore bit take two bytes.
+func (d *decodeState) peekType() (binn.Type, error) {
+	if d.off >= len(d.data) {
+		return binn.Null, ErrIncompleteRead
+	}
+
+	t := binn.Type(d.data[d.off])
+	if t&binn.StorageHasMore == 0 {
+		return t, nil
+	}
+
+	if d.off+1 >= len(d.data) {
+		return binn.Null, ErrIncompleteRead
+	}
+
+	return t<<8 | binn.Type(d.data[d.off+1]), nil
+}
+
+func (d *decodeState) readType() (binn.Type, error) {
+	t, err := d.peekType()
+	if err != nil {
+		return binn.Null, err
+	}
+	d.off += typeLen(t)
+
+	return t, nil
+}
+
+// typeLen returns the number of bytes the type takes.
+func typeLen(t binn.Type) int {
+	if t > 0xFF {
+		return 2
+	}
+
+	return 1
+}
+
+// readSize reads a 1 or 4 bytes size.
+func (d *decodeState) readSize() (int, error) {
+	if d.off >= len(d.data) {
+		return 0, ErrIncompleteRead
+	}
+
+	sz := int(d.data[d.off])
+	if sz <= maxOneByteSize {
+		d.off++
+		return sz, nil
+	}
+
+	if d.off+4 > len(d.data) {
+		return 0, ErrIncompleteRead
+	}
+
+	sz = int(binary.BigEndian.Uint32(d.data[d.off:]) & 0x7FFFFFFF)
+	d.off += 4
+
+	return sz, nil
+}
+
+// readBytes returns the next n bytes of the input.
+func (d *decodeState) readBytes(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.off {
+		return nil, ErrIncompleteRead
+	}
+
+	b := d.data[d.off : d.off+n]
+	d.off += n
+
+	return b, nil
+}
+
+// readValue reads the value of an item of the type btype, the type byte
+// has to be read already. Strings are returned without the null terminator.
+// Containers are not supported, use readContainer instead.
+func (d *decodeState) readValue(btype binn.Type) ([]byte, error) {
+	switch btype.Storage() {
+	case binn.StorageNoBytes:
+		return nil, nil
+	case binn.StorageByte:
+		return d.readBytes(1)
+	case binn.StorageWord:
+		return d.readBytes(2)
+	case binn.StorageDWord:
+		return d.readBytes(4)
+	case binn.StorageQWord:
+		return d.readBytes(8)
+	case binn.StorageString:
+		sz, err := d.readSize()
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := d.readBytes(sz + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if b[sz] != 0x00 {
+			return nil, ErrInvalidItem
+		}
+
+		return b[:sz], nil
+	case binn.StorageBlob:
+		sz, err := d.readSize()
+		if err != nil {
+			return nil, err
+		}
+
+		return d.readBytes(sz)
+	}
+
+	return nil, fmt.Errorf("%w %s", ErrUnknownType, btype)
+}
+
+// readContainer reads the header of a container, its type btype has to be
+// read already. It returns the container end offset and the items count.
+func (d *decodeState) readContainer(btype binn.Type) (int, int, error) {
+	start := d.off - typeLen(btype)
+
+	sz, err := d.readSize()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Items are decoded by the count, a size running past
+	// the end of the input is tolerated if the items are complete.
+	end := start + sz
+	if end > len(d.data) {
+		end = len(d.data)
+	}
+
+	cnt, err := d.readSize()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if d.off > end {
+		return 0, 0, ErrInvalidItem
+	}
+
+	return end, cnt, nil
+}
+
+// beginContainer reads the header of a container to decode its items,
+// as readContainer does. It fails if the container is nested deeper
+// than MaxDepth, endContainer has to be called after the items.
+func (d *decodeState) beginContainer(btype binn.Type) (int, int, error) {
+	if d.depth >= MaxDepth {
+		return 0, 0, ErrTooDeep
+	}
+
+	end, cnt, err := d.readContainer(btype)
+	if err != nil {
+		return 0, 0, err
+	}
+	d.depth++
+
+	return end, cnt, nil
+}
+
+// endContainer checks that the items of a container didn't overrun it
+// and moves the offset to the container end.
+func (d *decodeState) endContainer(end int) error {
+	if d.off > end {
+		return ErrInvalidItem
+	}
+	d.off = end
+	d.depth--
+
+	return nil
+}
+
+// readObjectKey reads an object key stored as a 1 byte size and the key bytes.
+func (d *decodeState) readObjectKey() ([]byte, error) {
+	if d.off >= len(d.data) {
+		return nil, ErrIncompleteRead
+	}
+
+	sz := int(d.data[d.off])
+	d.off++
+
+	return d.readBytes(sz)
+}
+
+func (d *decodeState) readMapKey() (int32, error) {
+	b, err := d.readBytes(mapKeySize)
+	if err != nil {
+		return 0, err
+	}
+
+	return Int32(b), nil
+}
+
+// skip moves the offset past the item at the current offset.
+func (d *decodeState) skip() error {
+	btype, err := d.readType()
+	if err != nil {
+		return err
+	}
+
+	if isStorageContainer(btype) {
+		end, _, err := d.readContainer(btype)
+		if err != nil {
+			return err
+		}
+		d.off = end
+
+		return nil
+	}
+
+	_, err = d.readValue(btype)
+
+	return err
+}
+
+// rawItem returns the complete encoding of the item at the current offset
+// and moves the offset past it.
+func (d *decodeState) rawItem() ([]byte, error) {
+	start := d.off
+
+	if err := d.skip(); err != nil {
+		return nil, err
+	}
+
+	return d.data[start:d.off], nil
+}
+
+func isStorageContainer(btype binn.Type) bool {
+	return btype.IsContainer()
+}
+
+// sizeHint limits a preallocation for cnt items by the remaining bytes,
+// so a forged count can't cause a huge allocation.
+func sizeHint(cnt, remaining int) int {
+	if cnt > remaining {
+		return remaining
+	}
+
+	return cnt
+}
@@ -3,21 +3,70 @@ package decode
 import (
 	"io"
 	"reflect"
+
+	"github.com/et-nik/binngo/naming"
 )
 
+// A Decoder reads and decodes BINN items from an input stream.
+//
+// The items are read back to back, as written by encode.Encoder.
+// The decoder reads exactly the bytes of each item and doesn't buffer
+// the input past it, so the reader can be shared with other readers
+// between the calls to Decode.
 type Decoder struct {
-	r io.Reader
+	r    io.Reader
+	off  int64
+	opts decodeOptions
 }
 
+// NewDecoder returns a new decoder that reads from r.
 func NewDecoder(r io.Reader) *Decoder {
-	return &Decoder{r}
+	return &Decoder{r: r}
 }
 
+// Decode reads the next item from its input and stores it in the value
+// pointed to by v. See the documentation for Unmarshal for details.
+//
+// Decode returns io.EOF when the input ends before the next item,
+// and io.ErrUnexpectedEOF when it ends in the middle of an item.
 func (dec *Decoder) Decode(v interface{}) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return &InvalidUnmarshalError{reflect.TypeOf(v)}
 	}
 
-	return decode(dec.r, v)
+	raw, short, err := readItem(dec.r)
+	if err != nil {
+		return err
+	}
+
+	err = unmarshalItem(raw, short, loadDecoderFunc(rv.Elem().Type()), rv.Elem(), dec.opts)
+	if err != io.ErrUnexpectedEOF {
+		dec.off += int64(len(raw))
+	}
+
+	return err
+}
+
+// InputOffset returns the input stream offset of the end of the last
+// item read by Decode. It doesn't move on errors reading an item, so
+// a reader of a growing log can seek to it and resume once the item
+// is complete.
+func (dec *Decoder) InputOffset() int64 {
+	return dec.off
+}
+
+// SetNaming sets the strategy deriving the object keys of the struct
+// fields without a name in their binn tags from the field names, such
+// as naming.SnakeCase. The field names still match the object keys.
+// Nil, the default, matches the field names only.
+func (dec *Decoder) SetNaming(s *naming.Strategy) {
+	dec.opts.naming = s
+}
+
+// SetCaseInsensitive makes the decoder match the object keys to the keys
+// of the struct fields case-insensitively, as encoding/json does, if
+// no key matches exactly.
+func (dec *Decoder) SetCaseInsensitive(on bool) {
+	dec.opts.caseInsensitive = on
 }
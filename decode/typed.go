@@ -0,0 +1,72 @@
+//go:build go1.18
+// +build go1.18
+
+package decode
+
+import (
+	"io"
+	"reflect"
+)
+
+// UnmarshalTo parses the BINN-encoded data into a new value of the type T.
+// Go has no variables per type parameter, so the decoder of T is looked
+// up in the decoder cache on every call, as with Unmarshal. Only
+// a TypedDecoder resolves it once, for all the values it reads.
+func UnmarshalTo[T any](data []byte) (T, error) {
+	var v T
+
+	err := unmarshalTyped(data, loadDecoderFunc(reflect.TypeOf(&v).Elem()), &v)
+
+	return v, err
+}
+
+// UnmarshalList parses a BINN list into a slice of T.
+func UnmarshalList[T any](data []byte) ([]T, error) {
+	return UnmarshalTo[[]T](data)
+}
+
+// UnmarshalMap parses a BINN object or map into a map with the keys
+// of the type K and the values of the type V.
+func UnmarshalMap[K comparable, V any](data []byte) (map[K]V, error) {
+	return UnmarshalTo[map[K]V](data)
+}
+
+func unmarshalTyped[T any](data []byte, dec decoderFunc, v *T) error {
+	d := decodeStatePool.Get().(*decodeState)
+	err := dec(d.init(data), reflect.ValueOf(v).Elem())
+	d.release()
+
+	return err
+}
+
+// A TypedDecoder reads values of the type T from a stream of BINN items.
+// The decoder of T is resolved once, when the TypedDecoder is created.
+type TypedDecoder[T any] struct {
+	r   io.Reader
+	dec decoderFunc
+}
+
+// NewTypedDecoder returns a new decoder of values of the type T
+// that reads from r.
+func NewTypedDecoder[T any](r io.Reader) *TypedDecoder[T] {
+	var v T
+
+	return &TypedDecoder[T]{r, loadDecoderFunc(reflect.TypeOf(&v).Elem())}
+}
+
+// Next reads the next item from the stream and decodes it into a new
+// value of the type T. It returns io.EOF when the stream ends before
+// the next item, and io.ErrUnexpectedEOF when it ends in the middle
+// of an item.
+func (dec *TypedDecoder[T]) Next() (T, error) {
+	var v T
+
+	raw, short, err := readItem(dec.r)
+	if err != nil {
+		return v, err
+	}
+
+	err = unmarshalItem(raw, short, dec.dec, reflect.ValueOf(&v).Elem(), decodeOptions{})
+
+	return v, err
+}
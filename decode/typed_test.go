@@ -0,0 +1,107 @@
+//go:build go1.18
+// +build go1.18
+
+package decode_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalTo(t *testing.T) {
+	b := []byte{
+		binn.ObjectType, 0x0B, 0x01, // [type] object, [size], [count]
+		0x01, 'a', // key
+		binn.StringType, 0x02, 'h', 'i', 0x00, // [type] = string, [data]
+	}
+	type obj struct {
+		A string `binn:"a"`
+	}
+
+	v, err := decode.UnmarshalTo[obj](b)
+
+	require.NoError(t, err)
+	assert.Equal(t, obj{"hi"}, v)
+}
+
+func TestUnmarshalList(t *testing.T) {
+	b := []byte{
+		binn.ListType, 0x07, 0x02, // [type] list, [size], [count]
+		binn.Uint8Type, 0x01, // [type] = uint8, [data] (1)
+		binn.Int8Type, 0xFE, // [type] = int8, [data] (-2)
+	}
+
+	v, err := decode.UnmarshalList[int16](b)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int16{1, -2}, v)
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	b := []byte{
+		binn.MapType, 0x09, 0x01, // [type] map, [size], [count]
+		0x00, 0x00, 0x00, 0x07, // key (7)
+		binn.Uint8Type, 0x03, // [type] = uint8, [data] (3)
+	}
+
+	v, err := decode.UnmarshalMap[int, uint](b)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int]uint{7: 3}, v)
+}
+
+func TestUnmarshalToMismatch(t *testing.T) {
+	_, err := decode.UnmarshalTo[string]([]byte{binn.True})
+
+	var e *decode.UnknownValueError
+	require.ErrorAs(t, err, &e)
+}
+
+func TestTypedDecoder(t *testing.T) {
+	b := []byte{
+		binn.Uint8Type, 0x01, // [type] = uint8, [data] (1)
+		binn.Uint16Type, 0x01, 0x2C, // [type] = uint16, [data] (300)
+	}
+	dec := decode.NewTypedDecoder[int](bytes.NewReader(b))
+
+	v, err := dec.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	v, err = dec.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 300, v)
+
+	_, err = dec.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestTypedDecoderIncompleteItem(t *testing.T) {
+	b := []byte{binn.Uint16Type, 0x01} // [type] = uint16, [data] (stripped)
+	dec := decode.NewTypedDecoder[int](bytes.NewReader(b))
+
+	_, err := dec.Next()
+
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func BenchmarkUnmarshalTo(b *testing.B) {
+	data := []byte{
+		binn.ListType, 0x07, 0x02, // [type] list, [size], [count]
+		binn.Uint8Type, 0x01, // [type] = uint8, [data] (1)
+		binn.Int8Type, 0xFE, // [type] = int8, [data] (-2)
+	}
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := decode.UnmarshalTo[[2]int](data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package decode
+
+import (
+	"encoding"
+	"reflect"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+var (
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// unmarshalerDecoder passes the complete item encoding to UnmarshalBINN.
+func unmarshalerDecoder(d *decodeState, v reflect.Value) error {
+	raw, err := d.rawItem()
+	if err != nil {
+		return err
+	}
+
+	return v.Addr().Interface().(Unmarshaler).UnmarshalBINN(raw)
+}
+
+type textOrBinaryDecoder struct {
+	isBinary bool
+	isText   bool
+	kindDec  decoderFunc
+}
+
+// newTextOrBinaryDecoder returns a decoder that decodes blobs with
+// encoding.BinaryUnmarshaler and strings with encoding.TextUnmarshaler
+// when the pointer to t implements them. Other items are decoded
+// as for the kind of t.
+func newTextOrBinaryDecoder(t reflect.Type) decoderFunc {
+	pt := reflect.PtrTo(t)
+
+	tb := textOrBinaryDecoder{
+		isBinary: pt.Implements(binaryUnmarshalerType),
+		isText:   pt.Implements(textUnmarshalerType),
+		kindDec:  newKindDecoder(t),
+	}
+
+	return tb.decode
+}
+
+func (tb *textOrBinaryDecoder) decode(d *decodeState, v reflect.Value) error {
+	btype, err := d.peekType()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case btype == binn.BlobType && tb.isBinary:
+		d.off += typeLen(btype)
+
+		bval, err := d.readValue(btype)
+		if err != nil {
+			return err
+		}
+
+		return v.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(bval)
+	case btype.Storage() == binn.StorageString && tb.isText:
+		d.off += typeLen(btype)
+
+		bval, err := d.readValue(btype)
+		if err != nil {
+			return err
+		}
+
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(bval)
+	}
+
+	return tb.kindDec(d, v)
+}
+
+// setTextKey decodes an object key into the map key implementing
+// encoding.TextUnmarshaler.
+func setTextKey(key reflect.Value, k []byte) error {
+	if !reflect.PtrTo(key.Type()).Implements(textUnmarshalerType) {
+		return &UnknownValueError{reflect.String, key.Kind()}
+	}
+
+	ptr := reflect.New(key.Type())
+
+	if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText(k); err != nil {
+		return err
+	}
+
+	key.Set(ptr.Elem())
+
+	return nil
+}
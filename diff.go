@@ -0,0 +1,372 @@
+package binngo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/internal/itempath"
+)
+
+// EqualOptions are the options of Equal and Diff.
+type EqualOptions struct {
+	// IgnoreIntWidth compares integers by their values,
+	// so uint8 1 equals int64 1.
+	IgnoreIntWidth bool
+}
+
+// A Difference is a pair of differing items of two documents.
+type Difference struct {
+	// Path is the path to the items, such as .items[2].name for the key
+	// "name" of the third item of the list under the key "items".
+	// Map keys are written as [12].
+	Path string
+	// A and B are the items of the documents. One of them is nil if
+	// a list item or a key is missing from its document.
+	A, B *DiffItem
+}
+
+// A DiffItem is an item of a document compared by Diff.
+type DiffItem struct {
+	Type binn.Type
+	// Value is the item as decoded by Unmarshal into an interface{},
+	// or its encoding if Unmarshal can't decode it.
+	Value interface{}
+}
+
+// String returns the difference in the form
+// `.id: uint8 1 != uint16 1`.
+func (d Difference) String() string {
+	return d.Path + ": " + d.A.String() + " != " + d.B.String()
+}
+
+func (it *DiffItem) String() string {
+	switch {
+	case it == nil:
+		return "missing"
+	case it.Type.IsContainer(), it.Type.Storage() == binn.StorageNoBytes:
+		return it.Type.String()
+	case it.Type.Storage() == binn.StorageString:
+		return fmt.Sprintf("%s %q", it.Type, it.Value)
+	case it.Type.Storage() == binn.StorageBlob:
+		return fmt.Sprintf("%s %x", it.Type, it.Value)
+	}
+
+	return fmt.Sprintf("%s %v", it.Type, it.Value)
+}
+
+// Equal reports whether the BINN documents a and b are equal. Unlike
+// bytes.Equal, it ignores the order of object and map keys and the
+// encoding of sizes. Malformed documents are never equal.
+func Equal(a, b []byte, opts *EqualOptions) bool {
+	diffs, err := Diff(a, b, opts)
+
+	return err == nil && len(diffs) == 0
+}
+
+// Diff compares the BINN documents a and b as Equal does, and returns
+// their differences in the order of the items of a, followed by the
+// items missing from a. The containers of different types are reported
+// as a whole, and the items of the containers of the same type are
+// compared one by one. Containers nested deeper than decode.MaxDepth
+// return decode.ErrTooDeep.
+func Diff(a, b []byte, opts *EqualOptions) ([]Difference, error) {
+	d := differ{}
+	if opts != nil {
+		d.opts = *opts
+	}
+
+	restA, restB, err := d.diff(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(restA) > 0 || len(restB) > 0 {
+		return nil, fmt.Errorf("binngo: %w: trailing data", decode.ErrInvalidItem)
+	}
+
+	return d.diffs, nil
+}
+
+type differ struct {
+	opts  EqualOptions
+	diffs []Difference
+	// path holds the path segments of the items being compared,
+	// they are joined only for the differences.
+	path []string
+}
+
+func (d *differ) add(a, b []byte) {
+	d.diffs = append(d.diffs, Difference{strings.Join(d.path, ""), diffItem(a), diffItem(b)})
+}
+
+// push appends the path segment of an item of the containers
+// being compared.
+func (d *differ) push(segment string) {
+	d.path = append(d.path, segment)
+}
+
+func (d *differ) pop() {
+	d.path = d.path[:len(d.path)-1]
+}
+
+func diffItem(raw []byte) *DiffItem {
+	if raw == nil {
+		return nil
+	}
+
+	btype, _ := decode.ReadTypeBytes(raw)
+
+	var v interface{}
+	if err := decode.Unmarshal(raw, &v); err != nil {
+		v = raw
+	}
+
+	return &DiffItem{btype, v}
+}
+
+// diff compares the items at the start of a and b,
+// and returns the bytes after them.
+func (d *differ) diff(a, b []byte) ([]byte, []byte, error) {
+	rawA, restA, err := decode.ReadItemBytes(a)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawB, restB, err := decode.ReadItemBytes(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	typeA, _ := decode.ReadTypeBytes(rawA)
+	typeB, _ := decode.ReadTypeBytes(rawB)
+
+	var equal bool
+
+	switch {
+	case typeA == typeB && isContainer(typeA):
+		if len(d.path) >= decode.MaxDepth {
+			return nil, nil, decode.ErrTooDeep
+		}
+
+		return restA, restB, d.diffContainers(typeA, rawA, rawB)
+	case typeA == typeB && typeA.IsContainer():
+		equal = bytes.Equal(rawA, rawB)
+	case typeA == typeB:
+		_, valueA, _, _ := decode.ReadValueBytes(rawA)
+		_, valueB, _, _ := decode.ReadValueBytes(rawB)
+		equal = bytes.Equal(valueA, valueB)
+	case d.opts.IgnoreIntWidth && isInt(typeA) && isInt(typeB):
+		equal = intValue(rawA) == intValue(rawB)
+	}
+
+	if !equal {
+		d.add(rawA, rawB)
+	}
+
+	return restA, restB, nil
+}
+
+func (d *differ) diffContainers(btype binn.Type, a, b []byte) error {
+	if btype == binn.ListType {
+		return d.diffLists(a, b)
+	}
+
+	entriesA, err := readEntries(btype, a)
+	if err != nil {
+		return err
+	}
+
+	entriesB, err := readEntries(btype, b)
+	if err != nil {
+		return err
+	}
+
+	// The n-th item of a key repeated in a container is compared
+	// to the n-th item of the key in the other container.
+	index := make(map[string][][]byte, len(entriesB))
+	for _, e := range entriesB {
+		index[e.key] = append(index[e.key], e.item)
+	}
+
+	matched := make(map[string]int, len(entriesA))
+
+	for _, e := range entriesA {
+		d.push(itempath.Key(btype, e.key))
+
+		items := index[e.key]
+		if matched[e.key] >= len(items) {
+			d.add(e.item, nil)
+			d.pop()
+
+			continue
+		}
+
+		item := items[matched[e.key]]
+		matched[e.key]++
+
+		if _, _, err := d.diff(e.item, item); err != nil {
+			return err
+		}
+		d.pop()
+	}
+
+	for _, e := range entriesB {
+		if matched[e.key] > 0 {
+			matched[e.key]--
+			continue
+		}
+
+		d.push(itempath.Key(btype, e.key))
+		d.add(nil, e.item)
+		d.pop()
+	}
+
+	return nil
+}
+
+func (d *differ) diffLists(a, b []byte) error {
+	countA, itemsA, _, err := decode.ReadListBytes(a)
+	if err != nil {
+		return err
+	}
+
+	countB, itemsB, _, err := decode.ReadListBytes(b)
+	if err != nil {
+		return err
+	}
+
+	i := 0
+	for ; i < countA && i < countB; i++ {
+		d.push(itempath.Index(i))
+		itemsA, itemsB, err = d.diff(itemsA, itemsB)
+		if err != nil {
+			return err
+		}
+		d.pop()
+	}
+
+	for ; i < countA || i < countB; i++ {
+		var item []byte
+
+		if i < countA {
+			item, itemsA, err = decode.ReadItemBytes(itemsA)
+		} else {
+			item, itemsB, err = decode.ReadItemBytes(itemsB)
+		}
+		if err != nil {
+			return err
+		}
+
+		d.push(itempath.Index(i))
+		if i < countA {
+			d.add(item, nil)
+		} else {
+			d.add(nil, item)
+		}
+		d.pop()
+	}
+
+	return nil
+}
+
+// entry is an item of an object or a map, with map keys in decimal.
+type entry struct {
+	key    string
+	mapKey int32
+	item   []byte
+}
+
+func readEntries(btype binn.Type, b []byte) ([]entry, error) {
+	var (
+		count int
+		items []byte
+		err   error
+	)
+
+	if btype == binn.MapType {
+		count, items, _, err = decode.ReadMapBytes(b)
+	} else {
+		count, items, _, err = decode.ReadObjectBytes(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]entry, count)
+
+	for i := range entries {
+		if btype == binn.MapType {
+			entries[i].mapKey, items, err = decode.ReadMapKeyBytes(items)
+			entries[i].key = strconv.Itoa(int(entries[i].mapKey))
+		} else {
+			var k []byte
+			k, items, err = decode.ReadObjectKeyBytes(items)
+			entries[i].key = string(k)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i].item, items, err = decode.ReadItemBytes(items)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// isContainer reports whether the container type is a list,
+// a map or an object.
+func isContainer(t binn.Type) bool {
+	return t == binn.ListType || t == binn.MapType || t == binn.ObjectType
+}
+
+func isInt(t binn.Type) bool {
+	switch t {
+	case binn.Uint8Type, binn.Int8Type, binn.Uint16Type, binn.Int16Type,
+		binn.Uint32Type, binn.Int32Type, binn.Uint64Type, binn.Int64Type:
+		return true
+	}
+
+	return false
+}
+
+// number is an integer of any width and signedness.
+type number struct {
+	negative bool
+	// bits are the 64 bits of the value, sign-extended if negative.
+	bits uint64
+}
+
+func intValue(raw []byte) number {
+	btype, v, _, _ := decode.ReadValueBytes(raw)
+
+	var u uint64
+
+	switch len(v) {
+	case 1:
+		u = uint64(v[0])
+	case 2:
+		u = uint64(binary.BigEndian.Uint16(v))
+	case 4:
+		u = uint64(binary.BigEndian.Uint32(v))
+	case 8:
+		u = binary.BigEndian.Uint64(v)
+	}
+
+	if btype&1 == 0 {
+		return number{bits: u}
+	}
+
+	// Sign-extend the signed value.
+	shift := 64 - 8*uint(len(v))
+	i := int64(u<<shift) >> shift
+
+	return number{negative: i < 0, bits: uint64(i)}
+}
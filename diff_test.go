@@ -0,0 +1,191 @@
+package binngo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/et-nik/binngo"
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  []byte
+		opts  *binngo.EqualOptions
+		equal bool
+	}{
+		{
+			"object key order",
+			[]byte{binn.ObjectType, 0x0B, 0x02, 0x01, 'a', binn.True, 0x01, 'b', binn.Uint8Type, 0x01},
+			[]byte{binn.ObjectType, 0x0B, 0x02, 0x01, 'b', binn.Uint8Type, 0x01, 0x01, 'a', binn.True},
+			nil,
+			true,
+		},
+		{
+			"map key order",
+			[]byte{binn.MapType, 0x0D, 0x02, 0, 0, 0, 1, binn.True, 0, 0, 0, 2, binn.False},
+			[]byte{binn.MapType, 0x0D, 0x02, 0, 0, 0, 2, binn.False, 0, 0, 0, 1, binn.True},
+			nil,
+			true,
+		},
+		{
+			"size encoding",
+			[]byte{binn.StringType, 0x02, 'h', 'i', 0x00},
+			[]byte{binn.StringType, 0x80, 0x00, 0x00, 0x02, 'h', 'i', 0x00},
+			nil,
+			true,
+		},
+		{
+			"list order",
+			[]byte{binn.ListType, 0x05, 0x02, binn.True, binn.False},
+			[]byte{binn.ListType, 0x05, 0x02, binn.False, binn.True},
+			nil,
+			false,
+		},
+		{
+			"int width",
+			[]byte{binn.Uint8Type, 0x01},
+			[]byte{binn.Int64Type, 0, 0, 0, 0, 0, 0, 0, 0x01},
+			nil,
+			false,
+		},
+		{
+			"ignored int width",
+			[]byte{binn.Uint8Type, 0x01},
+			[]byte{binn.Int64Type, 0, 0, 0, 0, 0, 0, 0, 0x01},
+			&binngo.EqualOptions{IgnoreIntWidth: true},
+			true,
+		},
+		{
+			"ignored int width of negative values",
+			[]byte{binn.Int8Type, 0xFF},
+			[]byte{binn.Int32Type, 0xFF, 0xFF, 0xFF, 0xFF},
+			&binngo.EqualOptions{IgnoreIntWidth: true},
+			true,
+		},
+		{
+			"ignored int width of different signs",
+			[]byte{binn.Int64Type, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			[]byte{binn.Uint64Type, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			&binngo.EqualOptions{IgnoreIntWidth: true},
+			false,
+		},
+		{
+			"malformed",
+			[]byte{binn.StringType, 0x02, 'h'},
+			[]byte{binn.StringType, 0x02, 'h'},
+			nil,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.equal, binngo.Equal(test.a, test.b, test.opts))
+			assert.Equal(t, test.equal, binngo.Equal(test.b, test.a, test.opts))
+		})
+	}
+}
+
+func TestEqualMarshaledMaps(t *testing.T) {
+	m := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		m[string(rune('a'+i%26))+string(rune('a'+i/26))] = i
+	}
+
+	a, err := binngo.Marshal(m)
+	require.NoError(t, err)
+	b, err := binngo.Marshal(m)
+	require.NoError(t, err)
+
+	assert.True(t, binngo.Equal(a, b, nil))
+}
+
+func TestDiff(t *testing.T) {
+	a, err := binngo.Marshal(map[string]interface{}{
+		"id":    1,
+		"name":  "gopher",
+		"tags":  []string{"a", "b"},
+		"attrs": map[int]interface{}{1: true, 2: 1.5},
+		"data":  nil,
+		"x y":   "removed",
+	})
+	require.NoError(t, err)
+
+	b, err := binngo.Marshal(map[string]interface{}{
+		"id":    300,
+		"name":  "gopher",
+		"tags":  []string{"a", "c", "d"},
+		"attrs": map[int]interface{}{1: true, 2: float32(1.5)},
+		"data":  []int{},
+		"added": -1,
+	})
+	require.NoError(t, err)
+
+	diffs, err := binngo.Diff(a, b, nil)
+	require.NoError(t, err)
+
+	s := make([]string, len(diffs))
+	for i, d := range diffs {
+		s[i] = d.String()
+	}
+
+	assert.ElementsMatch(t, []string{
+		".id: uint8 1 != uint16 300",
+		`.tags[1]: string "b" != string "c"`,
+		`.tags[2]: missing != string "d"`,
+		".attrs[2]: float64 1.5 != float32 1.5",
+		".data: null != list",
+		`["x y"]: string "removed" != missing`,
+		".added: missing != int8 -1",
+	}, s)
+
+	for _, d := range diffs {
+		if d.Path == ".data" {
+			assert.Equal(t, &binngo.DiffItem{Type: binn.ListType, Value: []interface{}{}}, d.B)
+		}
+	}
+}
+
+func TestDiffMalformed(t *testing.T) {
+	_, err := binngo.Diff([]byte{binn.True, binn.True}, []byte{binn.True}, nil)
+	assert.ErrorIs(t, err, decode.ErrInvalidItem)
+
+	list := []byte{binn.ListType, 0x04, 0x01}
+	_, err = binngo.Diff(list, list, nil)
+	assert.ErrorIs(t, err, decode.ErrInvalidItem)
+}
+
+func TestDiffRepeatedKeys(t *testing.T) {
+	a := []byte{binn.ObjectType, 0x0F, 0x03, 0x01, 'a', binn.True, 0x01, 'b', binn.Null, 0x01, 'a', binn.False}
+	b := []byte{binn.ObjectType, 0x0F, 0x03, 0x01, 'a', binn.True, 0x01, 'a', binn.True, 0x01, 'a', binn.False}
+
+	assert.True(t, binngo.Equal(a, a, nil))
+
+	diffs, err := binngo.Diff(a, b, nil)
+	require.NoError(t, err)
+
+	require.Len(t, diffs, 3)
+	assert.Equal(t, ".b: null != missing", diffs[0].String())
+	assert.Equal(t, ".a: false != true", diffs[1].String())
+	assert.Equal(t, ".a: missing != false", diffs[2].String())
+}
+
+func TestDiffMaxDepth(t *testing.T) {
+	a := nestedLists(decode.MaxDepth)
+
+	assert.True(t, binngo.Equal(a, a, nil))
+
+	diffs, err := binngo.Diff(a, nestedLists(decode.MaxDepth-1), nil)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, strings.Repeat("[0]", decode.MaxDepth-1), diffs[0].Path)
+	assert.Nil(t, diffs[0].B)
+
+	_, err = binngo.Diff(nestedLists(decode.MaxDepth+1), nestedLists(decode.MaxDepth+1), nil)
+	assert.ErrorIs(t, err, decode.ErrTooDeep)
+}
@@ -0,0 +1,124 @@
+package encode
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+// The Append functions append complete items, starting with the type,
+// to dst and return the extended buffer. They produce exactly what
+// Marshal produces for the values of the matching kinds, and are used
+// by the code generated by binngo-gen.
+
+// AppendNull appends a null item.
+func AppendNull(dst []byte) []byte {
+	return append(dst, binn.Null)
+}
+
+// AppendBool appends a true or false item.
+func AppendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, binn.True)
+	}
+
+	return append(dst, binn.False)
+}
+
+// AppendInt appends v using the smallest integer type that holds it.
+func AppendInt(dst []byte, v int64) []byte {
+	return appendInt(dst, int(v))
+}
+
+// AppendUint appends v using the smallest unsigned type that holds it.
+func AppendUint(dst []byte, v uint64) []byte {
+	return appendUint(dst, uint(v))
+}
+
+// AppendFloat32 appends a float32 item.
+func AppendFloat32(dst []byte, f float32) []byte {
+	return appendFloat32(append(dst, binn.Float32Type), f)
+}
+
+// AppendFloat64 appends a float64 item.
+func AppendFloat64(dst []byte, f float64) []byte {
+	return appendFloat64(append(dst, binn.Float64Type), f)
+}
+
+// AppendString appends a null terminated string item.
+func AppendString(dst []byte, s string) []byte {
+	return appendString(dst, s)
+}
+
+// AppendBlob appends a blob item.
+func AppendBlob(dst []byte, b []byte) []byte {
+	return appendBlobData(append(dst, binn.BlobType), b)
+}
+
+// AppendObjectKey appends the key of an object item. It panics if the key
+// is longer than binn.MaxKeySize bytes, the code generated by binngo-gen
+// checks the keys of maps first and returns ErrKeyTooLong.
+func AppendObjectKey(dst []byte, key string) []byte {
+	if len(key) > binn.MaxKeySize {
+		panic("binn: " + ErrKeyTooLong.Error())
+	}
+
+	return appendObjectKey(dst, key)
+}
+
+// AppendMapKey appends the key of a map item. The key is truncated
+// to int32, CheckIntMapKey and CheckUintMapKey check that it fits.
+func AppendMapKey(dst []byte, key int64) []byte {
+	return appendUint32(dst, uint32(int32(key)))
+}
+
+// CheckIntMapKey returns a *MapKeyError if the key doesn't fit
+// into the int32 keys of maps.
+func CheckIntMapKey(key int64) error {
+	if key < math.MinInt32 || key > math.MaxInt32 {
+		return &MapKeyError{strconv.FormatInt(key, 10)}
+	}
+
+	return nil
+}
+
+// CheckUintMapKey returns a *MapKeyError if the key doesn't fit
+// into the int32 keys of maps.
+func CheckUintMapKey(key uint64) error {
+	if key > math.MaxInt32 {
+		return &MapKeyError{strconv.FormatUint(key, 10)}
+	}
+
+	return nil
+}
+
+// BeginContainer appends the header of a container of the type
+// containerType with count items. It returns the extended buffer and
+// the container offset, which has to be passed to EndContainer once
+// the items are appended.
+func BeginContainer(dst []byte, containerType uint8, count int) ([]byte, int) {
+	start := len(dst)
+	dst = append(dst, containerType, 0)
+	dst = appendSize(dst, count)
+
+	return dst, start
+}
+
+// EndContainer writes the size of the container started at start.
+// The items are shifted only if the size doesn't fit into one byte.
+func EndContainer(dst []byte, start int) []byte {
+	size := len(dst) - start
+	if size <= math.MaxInt8 {
+		dst[start+1] = byte(size)
+		return dst
+	}
+
+	size += 3
+	dst = append(dst, 0, 0, 0)
+	copy(dst[start+5:], dst[start+2:len(dst)-3])
+	binary.BigEndian.PutUint32(dst[start+1:], uint32(size)|0x80000000)
+
+	return dst
+}
@@ -0,0 +1,60 @@
+package encode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendMatchesMarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		appended []byte
+	}{
+		{"bool", true, AppendBool(nil, true)},
+		{"negative int", int16(-300), AppendInt(nil, -300)},
+		{"int", 70000, AppendInt(nil, 70000)},
+		{"uint", uint64(1 << 40), AppendUint(nil, 1<<40)},
+		{"float32", float32(1.5), AppendFloat32(nil, 1.5)},
+		{"float64", 2.25, AppendFloat64(nil, 2.25)},
+		{"string", "binn", AppendString(nil, "binn")},
+		{"nil pointer", (*int)(nil), AppendNull(nil)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := Marshal(test.value)
+
+			require.NoError(t, err)
+			assert.Equal(t, b, test.appended)
+		})
+	}
+}
+
+func TestAppendContainer(t *testing.T) {
+	m := map[string][]int{"k": make([]int, 100)}
+	want, err := Marshal(m)
+	require.NoError(t, err)
+
+	dst := []byte{0xFF}
+	dst, start := BeginContainer(dst, binn.ObjectType, 1)
+	dst = AppendObjectKey(dst, "k")
+	dst, list := BeginContainer(dst, binn.ListType, 100)
+	for i := 0; i < 100; i++ {
+		dst = AppendInt(dst, 0)
+	}
+	dst = EndContainer(dst, list)
+	dst = EndContainer(dst, start)
+
+	assert.Equal(t, byte(0xFF), dst[0])
+	assert.Equal(t, want, dst[1:])
+}
+
+func TestAppendObjectKeyTooLong(t *testing.T) {
+	assert.NotPanics(t, func() { AppendObjectKey(nil, strings.Repeat("k", binn.MaxKeySize)) })
+	assert.Panics(t, func() { AppendObjectKey(nil, strings.Repeat("k", binn.MaxKeySize+1)) })
+}
@@ -2,6 +2,7 @@ package encode
 
 import (
 	"reflect"
+	"strconv"
 
 	"github.com/et-nik/binngo/binn"
 )
@@ -11,31 +12,28 @@ type arrayEncoder struct {
 }
 
 func newArrayEncoder(t reflect.Type) encoderFunc {
-	ae := arrayEncoder{newTypeEncoder(t.Elem())}
+	ae := arrayEncoder{loadEncodeFunc(t.Elem())}
 	return ae.encode
 }
 
-func (ae *arrayEncoder) encode(v reflect.Value) ([]byte, error) {
-	var dataBytes []byte
-	n := v.Len()
-	for i := 0; i < n; i++ {
-		encoded, err := ae.elemEnc(v.Index(i))
-		if err != nil {
-			return nil, err
+func (ae *arrayEncoder) encode(e *encodeState, v reflect.Value) error {
+	if v.Kind() == reflect.Slice && !v.IsNil() {
+		if err := e.enter(v); err != nil {
+			return err
 		}
-
-		dataBytes = append(dataBytes, encoded...)
+		defer e.leave(v)
 	}
 
-	bytes := []byte{}
+	n := v.Len()
+	start := e.beginContainer(binn.ListType, n)
 
-	typeBytes := Uint8(binn.ListType)
-	countBytes := Size(v.Len(), false)
+	for i := 0; i < n; i++ {
+		if err := ae.elemEnc(e, v.Index(i)); err != nil {
+			return withPath(err, "["+strconv.Itoa(i)+"]")
+		}
+	}
 
-	bytes = append(bytes, typeBytes...)
-	bytes = append(bytes, Size(len(typeBytes)+len(dataBytes)+len(countBytes), true)...)
-	bytes = append(bytes, countBytes...)
-	bytes = append(bytes, dataBytes...)
+	e.endContainer(start)
 
-	return bytes, nil
+	return nil
 }
@@ -0,0 +1,39 @@
+package encode
+
+import (
+	"encoding"
+	"reflect"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+func Blob(b []byte) []byte {
+	return appendBlobData(make([]byte, 0, len(b)+4), b)
+}
+
+func appendBlobData(b []byte, data []byte) []byte {
+	b = appendSize(b, len(data))
+	return append(b, data...)
+}
+
+func binaryMarshalerEncoder(e *encodeState, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		e.buf = append(e.buf, binn.Null)
+		return nil
+	}
+
+	m, ok := v.Interface().(encoding.BinaryMarshaler)
+	if !ok {
+		e.buf = append(e.buf, binn.Null)
+		return nil
+	}
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return &MarshalerError{v.Type(), err, "MarshalBinary"}
+	}
+
+	e.buf = append(e.buf, binn.BlobType)
+	e.buf = appendBlobData(e.buf, b)
+
+	return nil
+}
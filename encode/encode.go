@@ -8,33 +8,41 @@ import (
 	"github.com/et-nik/binngo/binn"
 )
 
-type encoderFunc func(v reflect.Value) ([]byte, error)
+// encoderFunc appends the encoding of v to the buffer of the encodeState.
+type encoderFunc func(e *encodeState, v reflect.Value) error
 
 var encoderCache sync.Map // map[reflect.Type]encoderFunc
 
 var (
-	marshalerType     = reflect.TypeOf((*Marshaler)(nil)).Elem()
-	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	marshalerType       = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	appenderType        = reflect.TypeOf((*Appender)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
 )
 
+// Marshaler is the interface implemented by types that can marshal
+// themselves into a single valid BINN item.
 type Marshaler interface {
 	MarshalBINN() ([]byte, error)
 }
 
-func marshal(v interface{}) ([]byte, error) {
-	rv := reflect.ValueOf(v)
+// Appender is implemented by types that can append their BINN encoding
+// to dst without allocating a separate buffer. It takes precedence over
+// Marshaler. The appended bytes must be a single valid BINN item.
+type Appender interface {
+	AppendBINN(dst []byte) ([]byte, error)
+}
 
-	if !rv.IsValid() {
-		return nil, ErrInvalidValue
-	}
+func (e *encodeState) marshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
 
 	if !rv.IsValid() {
-		return nil, ErrInvalidValue
+		return ErrInvalidValue
 	}
 
 	enc := loadEncodeFunc(rv.Type())
 
-	return enc(rv)
+	return enc(e, rv)
 }
 
 func loadEncodeFunc(t reflect.Type) encoderFunc {
@@ -47,94 +55,91 @@ func loadEncodeFunc(t reflect.Type) encoderFunc {
 		f  encoderFunc
 	)
 	wg.Add(1)
-	fi, loaded := encoderCache.LoadOrStore(t, encoderFunc(func(v reflect.Value) ([]byte, error) {
+	fi, loaded := encoderCache.LoadOrStore(t, encoderFunc(func(e *encodeState, v reflect.Value) error {
 		wg.Wait()
-		return f(v)
+		return f(e, v)
 	}))
 	if loaded {
 		return fi.(encoderFunc)
 	}
 
-	f = newTypeEncoder(t)
+	f = newTypeEncoder(t, true)
 	wg.Done()
 	encoderCache.Store(t, f)
 	return f
 }
 
+// newTypeEncoder constructs an encoderFunc for a type.
+// The returned encoder only checks CanAddr when allowAddr is true.
+//
 //nolint:funlen
-func newTypeEncoder(t reflect.Type) encoderFunc {
+func newTypeEncoder(t reflect.Type, allowAddr bool) encoderFunc {
+	if t.Kind() != reflect.Ptr && allowAddr {
+		if addrEnc := newAddrEncoder(t); addrEnc != nil {
+			return newCondAddrEncoder(addrEnc, newTypeEncoder(t, false))
+		}
+	}
+
+	if t.Implements(appenderType) {
+		return appenderEncoder
+	}
 	if t.Implements(marshalerType) {
 		return marshalerEncoder
 	}
+	if t.Implements(binaryMarshalerType) {
+		return binaryMarshalerEncoder
+	}
 	if t.Implements(textMarshalerType) {
 		return textMarshalerEncoder
 	}
 
 	switch t.Kind() {
 	case reflect.Bool:
-		return func(v reflect.Value) ([]byte, error) {
+		return func(e *encodeState, v reflect.Value) error {
 			if v.Bool() {
-				return []byte{binn.True}, nil
+				e.buf = append(e.buf, binn.True)
+			} else {
+				e.buf = append(e.buf, binn.False)
 			}
-			return []byte{binn.False}, nil
+			return nil
 		}
 	case reflect.Struct:
 		return newStructEncoder(t)
 	case reflect.Map:
 		return newMapEncoder(t)
 	case reflect.Interface:
-		return func(v reflect.Value) ([]byte, error) {
+		return func(e *encodeState, v reflect.Value) error {
 			if v.IsNil() {
-				return []byte{binn.Null}, nil
+				e.buf = append(e.buf, binn.Null)
+				return nil
 			}
 
-			return loadEncodeFunc(v.Elem().Type())(v.Elem())
+			return loadEncodeFunc(v.Elem().Type())(e, v.Elem())
 		}
 	case reflect.String:
-		return func(v reflect.Value) ([]byte, error) {
-			var bytes []byte
-
-			bytes = append(bytes, Uint8(binn.StringType)...)
-			bytes = append(bytes, String(v.String())...)
-			bytes = append(bytes, 0x00)
-
-			return bytes, nil
+		return func(e *encodeState, v reflect.Value) error {
+			e.buf = appendString(e.buf, v.String())
+			return nil
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return func(v reflect.Value) ([]byte, error) {
-			var bytes []byte
-
-			bytes = append(bytes, Uint8(uint8(detectIntType(int(v.Int()))))...)
-			bytes = append(bytes, Int(int(v.Int()))...)
-
-			return bytes, nil
+		return func(e *encodeState, v reflect.Value) error {
+			e.buf = appendInt(e.buf, int(v.Int()))
+			return nil
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return func(v reflect.Value) ([]byte, error) {
-			var bytes []byte
-
-			bytes = append(bytes, Uint8(uint8(detectUintType(uint(v.Uint()))))...)
-			bytes = append(bytes, Uint(uint(v.Uint()))...)
-
-			return bytes, nil
+		return func(e *encodeState, v reflect.Value) error {
+			e.buf = appendUint(e.buf, uint(v.Uint()))
+			return nil
 		}
 	case reflect.Float32:
-		return func(v reflect.Value) ([]byte, error) {
-			var bytes []byte
-
-			bytes = append(bytes, Uint8(binn.Float32Type)...)
-			bytes = append(bytes, Float32(float32(v.Float()))...)
-
-			return bytes, nil
+		return func(e *encodeState, v reflect.Value) error {
+			e.buf = appendFloat32(append(e.buf, binn.Float32Type), float32(v.Float()))
+			return nil
 		}
 	case reflect.Float64:
-		return func(v reflect.Value) ([]byte, error) {
-			var bytes []byte
-
-			bytes = append(bytes, Uint8(binn.Float64Type)...)
-			bytes = append(bytes, Float64(v.Float())...)
-
-			return bytes, nil
+		return func(e *encodeState, v reflect.Value) error {
+			e.buf = appendFloat64(append(e.buf, binn.Float64Type), v.Float())
+			return nil
 		}
 	case reflect.Slice, reflect.Array:
 		return newArrayEncoder(t)
@@ -142,23 +147,102 @@ func newTypeEncoder(t reflect.Type) encoderFunc {
 		return newPtrEncoder(t)
 	}
 
-	return func(_ reflect.Value) ([]byte, error) {
-		return nil, &UnsupportedTypeError{t}
+	return func(_ *encodeState, _ reflect.Value) error {
+		return &UnsupportedTypeError{t}
+	}
+}
+
+// newAddrEncoder returns an encoder for a type whose pointer implements
+// one of the marshaler interfaces, or nil if there is no such method.
+func newAddrEncoder(t reflect.Type) encoderFunc {
+	pt := reflect.PtrTo(t)
+
+	var enc encoderFunc
+
+	switch {
+	case pt.Implements(appenderType):
+		enc = appenderEncoder
+	case pt.Implements(marshalerType):
+		enc = marshalerEncoder
+	case pt.Implements(binaryMarshalerType):
+		enc = binaryMarshalerEncoder
+	case pt.Implements(textMarshalerType):
+		enc = textMarshalerEncoder
+	default:
+		return nil
+	}
+
+	return func(e *encodeState, v reflect.Value) error {
+		return enc(e, v.Addr())
+	}
+}
+
+type condAddrEncoder struct {
+	canAddrEnc, elseEnc encoderFunc
+}
+
+func (ce condAddrEncoder) encode(e *encodeState, v reflect.Value) error {
+	if v.CanAddr() {
+		return ce.canAddrEnc(e, v)
 	}
+
+	return ce.elseEnc(e, v)
+}
+
+// newCondAddrEncoder returns an encoder that checks whether its value
+// CanAddr and delegates to canAddrEnc if so, else to elseEnc.
+func newCondAddrEncoder(canAddrEnc, elseEnc encoderFunc) encoderFunc {
+	enc := condAddrEncoder{canAddrEnc: canAddrEnc, elseEnc: elseEnc}
+	return enc.encode
 }
 
-func marshalerEncoder(v reflect.Value) ([]byte, error) {
+func marshalerEncoder(e *encodeState, v reflect.Value) error {
 	if v.Kind() == reflect.Ptr && v.IsNil() {
-		return []byte{0x00}, nil
+		e.buf = append(e.buf, binn.Null)
+		return nil
 	}
 	m, ok := v.Interface().(Marshaler)
 	if !ok {
-		return []byte{0x00}, nil
+		e.buf = append(e.buf, binn.Null)
+		return nil
 	}
 	b, err := m.MarshalBINN()
+	if err == nil {
+		err = validItem(b)
+	}
+	if err != nil {
+		return &MarshalerError{v.Type(), err, "MarshalBINN"}
+	}
+
+	e.buf = append(e.buf, b...)
+
+	return nil
+}
+
+func appenderEncoder(e *encodeState, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		e.buf = append(e.buf, binn.Null)
+		return nil
+	}
+	a, ok := v.Interface().(Appender)
+	if !ok {
+		e.buf = append(e.buf, binn.Null)
+		return nil
+	}
+
+	start := len(e.buf)
+	b, err := a.AppendBINN(e.buf)
+	if err == nil && len(b) < start {
+		err = ErrInvalidItem
+	}
+	if err == nil {
+		err = validItem(b[start:])
+	}
 	if err != nil {
-		return nil, &MarshalerError{v.Type(), err, "MarshalBINN"}
+		return &MarshalerError{v.Type(), err, "AppendBINN"}
 	}
 
-	return b, nil
+	e.buf = b
+
+	return nil
 }
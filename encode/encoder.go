@@ -1,6 +1,82 @@
 // Package encoder implements BINN encoding.
 package encode
 
+import (
+	"io"
+
+	"github.com/et-nik/binngo/naming"
+)
+
+// Marshal returns the BINN encoding of v.
+//
+// A value implementing Marshaler is encoded with MarshalBINN. Otherwise
+// a value implementing encoding.BinaryMarshaler is encoded as a blob, and
+// a value implementing encoding.TextMarshaler is encoded as a string.
+//
+// Cyclic data structures are not supported. Marshal returns
+// an UnsupportedValueError when it encounters a cycle deeper than
+// DefaultCycleDetectionDepth instead of overflowing the stack.
 func Marshal(v interface{}) ([]byte, error) {
-	return marshal(v)
+	e := newEncodeState()
+	defer e.release()
+
+	err := e.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := append([]byte(nil), e.buf...)
+
+	return buf, nil
+}
+
+// An Encoder writes BINN values to an output stream.
+type Encoder struct {
+	w                   io.Writer
+	cycleDetectionDepth uint
+	naming              *naming.Strategy
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, cycleDetectionDepth: DefaultCycleDetectionDepth}
+}
+
+// Encode writes the BINN encoding of v to the stream.
+//
+// The items are written back to back with a single Write each, and
+// nothing is written if v can't be encoded, so the stream can be read
+// item by item with decode.Decoder. Every BINN item is self-delimiting,
+// no length prefix is needed.
+func (enc *Encoder) Encode(v interface{}) error {
+	e := newEncodeState()
+	defer e.release()
+	e.cycleDetectionDepth = enc.cycleDetectionDepth
+	e.naming = enc.naming
+
+	err := e.marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = enc.w.Write(e.buf)
+
+	return err
+}
+
+// SetCycleDetectionDepth sets the nesting depth of pointers, maps and
+// slices after which the encoder starts to look for cycles.
+// Zero checks every value, which shortens the path reported
+// by UnsupportedValueError at the cost of encoding speed.
+func (enc *Encoder) SetCycleDetectionDepth(depth uint) {
+	enc.cycleDetectionDepth = depth
+}
+
+// SetNaming sets the strategy deriving the object keys of the struct
+// fields without a name in their binn tags from the field names, such
+// as naming.SnakeCase. Nil, the default, uses the field names as they
+// are. Types implementing Marshaler or Appender encode themselves
+// regardless of the strategy.
+func (enc *Encoder) SetNaming(s *naming.Strategy) {
+	enc.naming = s
 }
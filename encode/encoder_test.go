@@ -1,11 +1,19 @@
 package encode_test
 
 import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/et-nik/binngo/binn"
 	"github.com/et-nik/binngo/encode"
+	"github.com/et-nik/binngo/naming"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEncodeStruct(t *testing.T) {
@@ -253,3 +261,427 @@ func TestEncodeCustom(t *testing.T) {
 		}, result)
 	}
 }
+
+func TestEncodeTextMarshaler(t *testing.T) {
+	v := net.IPv4(127, 0, 0, 1)
+
+	result, err := encode.Marshal(v)
+
+	if assert.Nil(t, err) {
+		assert.Equal(t, []byte{
+			binn.StringType,									// [type] = string
+			0x09,												// [size]
+			'1', '2', '7', '.', '0', '.', '0', '.', '1', 0x00,	// [data] null terminated
+		}, result)
+	}
+}
+
+func TestEncodeBinaryMarshaler(t *testing.T) {
+	v := time.Date(2021, 5, 12, 10, 20, 30, 0, time.UTC)
+	data, err := v.MarshalBinary()
+	require.NoError(t, err)
+
+	result, err := encode.Marshal(v)
+
+	if assert.Nil(t, err) {
+		assert.Equal(t, append([]byte{binn.BlobType, byte(len(data))}, data...), result)
+	}
+}
+
+type binaryAndCustom struct{}
+
+func (binaryAndCustom) MarshalBINN() ([]byte, error) {
+	return []byte{binn.True}, nil
+}
+
+func (binaryAndCustom) MarshalBinary() ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func TestEncodeMarshalerTakesPrecedenceOverBinaryMarshaler(t *testing.T) {
+	result, err := encode.Marshal(binaryAndCustom{})
+
+	if assert.Nil(t, err) {
+		assert.Equal(t, []byte{binn.True}, result)
+	}
+}
+
+type ptrCustom struct {
+	A int
+}
+
+func (c *ptrCustom) MarshalBINN() ([]byte, error) {
+	return encode.Marshal(c.A * 2)
+}
+
+func TestEncodeNestedPointerReceiverMarshaler(t *testing.T) {
+	v := struct {
+		C ptrCustom
+		L []ptrCustom
+	}{
+		ptrCustom{1},
+		[]ptrCustom{{2}},
+	}
+
+	result, err := encode.Marshal(&v)
+
+	if assert.Nil(t, err) {
+		assert.Equal(t, []byte{
+			binn.ObjectType,
+			0x0E,								// [size] container total size
+			0x02,								// [count] key/value pairs
+
+			0x01, 'C',							// key
+			binn.Uint8Type, 0x02,				// value encoded by MarshalBINN
+
+			0x01, 'L',							// key
+			binn.ListType, 0x05, 0x01,			// [type] list, [size], [count]
+			binn.Uint8Type, 0x04,				// item encoded by MarshalBINN
+		}, result)
+	}
+}
+
+type invalidMarshaler []byte
+
+func (m invalidMarshaler) MarshalBINN() ([]byte, error) {
+	return m, nil
+}
+
+func TestEncodeInvalidMarshalerOutput(t *testing.T) {
+	v := []interface{}{1, invalidMarshaler{binn.Uint16Type, 0x01}}
+
+	_, err := encode.Marshal(v)
+
+	var e *encode.MarshalerError
+	require.ErrorAs(t, err, &e)
+	assert.ErrorIs(t, err, encode.ErrInvalidItem)
+	assert.Equal(t, reflect.TypeOf(invalidMarshaler{}), e.Type)
+}
+
+type rawItem []byte
+
+func (r rawItem) MarshalBINN() ([]byte, error) {
+	return r, nil
+}
+
+func TestEncodeMarshalerUserType(t *testing.T) {
+	item := rawItem{0xB0, 0x01, 0x02, 'h', 'i', 0x00} // [type] = user string type, [size], [data]
+
+	result, err := encode.Marshal([]interface{}{item})
+
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte{binn.ListType, 0x09, 0x01}, item...), result)
+}
+
+type appender uint8
+
+func (a appender) AppendBINN(dst []byte) ([]byte, error) {
+	return append(dst, binn.Uint8Type, byte(a)), nil
+}
+
+func (a appender) MarshalBINN() ([]byte, error) {
+	return nil, errors.New("MarshalBINN must not be called")
+}
+
+func TestEncodeAppender(t *testing.T) {
+	v := []appender{1, 2}
+
+	result, err := encode.Marshal(v)
+
+	if assert.Nil(t, err) {
+		assert.Equal(t, []byte{
+			binn.ListType, 0x07, 0x02,	// [type] list, [size], [count]
+			binn.Uint8Type, 0x01,		// [type] = uint8, [data] (1)
+			binn.Uint8Type, 0x02,		// [type] = uint8, [data] (2)
+		}, result)
+	}
+}
+
+type invalidAppender struct{}
+
+func (invalidAppender) AppendBINN(dst []byte) ([]byte, error) {
+	return append(dst, binn.ListType, 0x05, 0x01), nil
+}
+
+func TestEncodeInvalidAppenderOutput(t *testing.T) {
+	_, err := encode.Marshal(invalidAppender{})
+
+	var e *encode.MarshalerError
+	require.ErrorAs(t, err, &e)
+	assert.ErrorIs(t, err, encode.ErrInvalidItem)
+	assert.Contains(t, err.Error(), "AppendBINN")
+}
+
+type node struct {
+	Value int
+	Next  *node
+}
+
+func TestEncodeCycle(t *testing.T) {
+	list := &node{Value: 1}
+	list.Next = &node{Value: 2, Next: list}
+
+	m := map[string]interface{}{}
+	m["self"] = m
+
+	s := []interface{}{nil}
+	s[0] = s
+
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"pointers", list},
+		{"map", m},
+		{"slice", s},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := encode.Marshal(test.v)
+
+			var e *encode.UnsupportedValueError
+			require.ErrorAs(t, err, &e)
+			assert.Contains(t, e.Error(), "encountered a cycle")
+		})
+	}
+}
+
+type recursiveMap map[string]recursiveMap
+
+type recursiveList []recursiveList
+
+func TestEncodeRecursiveTypes(t *testing.T) {
+	b, err := encode.Marshal(recursiveMap{"a": recursiveMap{"b": nil}})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		binn.ObjectType, 0x0D, 0x01,
+		0x01, 'a', binn.ObjectType, 0x08, 0x01,
+		0x01, 'b', binn.ObjectType, 0x03, 0x00,
+	}, b)
+
+	b, err = encode.Marshal(recursiveList{recursiveList{}, nil})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{binn.ListType, 0x09, 0x02, binn.ListType, 0x03, 0x00, binn.ListType, 0x03, 0x00}, b)
+
+	m := recursiveMap{}
+	m["self"] = m
+
+	l := recursiveList{nil}
+	l[0] = l
+
+	for _, v := range []interface{}{m, l} {
+		_, err := encode.Marshal(v)
+
+		var e *encode.UnsupportedValueError
+		require.ErrorAs(t, err, &e)
+		assert.Contains(t, e.Error(), "encountered a cycle")
+	}
+}
+
+func TestEncoderCycleDetectionDepthPath(t *testing.T) {
+	list := &node{Value: 1}
+	list.Next = &node{Value: 2, Next: list}
+	buf := &bytes.Buffer{}
+	enc := encode.NewEncoder(buf)
+	enc.SetCycleDetectionDepth(0)
+
+	err := enc.Encode(map[string]interface{}{"list": list})
+
+	var e *encode.UnsupportedValueError
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, `["list"].Next.Next`, e.Path)
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestEncodeSharedPointersAreNotCycles(t *testing.T) {
+	shared := &node{Value: 1}
+	v := []*node{shared, shared}
+	enc := encode.NewEncoder(&bytes.Buffer{})
+	enc.SetCycleDetectionDepth(0)
+
+	err := enc.Encode(v)
+
+	assert.NoError(t, err)
+}
+
+func TestEncodeListWithLongSize(t *testing.T) {
+	v := make([]uint16, 50)
+	expected := []byte{
+		binn.ListType,				// [type] list (container)
+		0x80, 0x00, 0x00, 0x9C,		// [size] container total size (156)
+		50,							// [count] items
+	}
+	for i := range v {
+		v[i] = 1000
+		expected = append(expected, binn.Uint16Type, 0x03, 0xE8)
+	}
+
+	result, err := encode.Marshal(v)
+
+	if assert.Nil(t, err) {
+		assert.Equal(t, expected, result)
+	}
+}
+
+func TestEncodeNestedListsWithLongSize(t *testing.T) {
+	inner := make([]string, 10)
+	innerBytes := []byte{
+		binn.ListType,				// [type] list (container)
+		0x80, 0x00, 0x00, 0x88,		// [size] container total size (136)
+		10,							// [count] items
+	}
+	for i := range inner {
+		inner[i] = "0123456789"
+		innerBytes = append(innerBytes, binn.StringType, 10)
+		innerBytes = append(innerBytes, inner[i]...)
+		innerBytes = append(innerBytes, 0x00)
+	}
+	expected := []byte{
+		binn.ListType,				// [type] list (container)
+		0x80, 0x00, 0x00, 0x90,		// [size] container total size (144)
+		2,							// [count] items
+		binn.Uint8Type, 1,			// [type] = uint8, [data] (1)
+	}
+	expected = append(expected, innerBytes...)
+
+	result, err := encode.Marshal([]interface{}{1, inner})
+
+	if assert.Nil(t, err) {
+		assert.Equal(t, expected, result)
+	}
+}
+
+func TestEncoderWritesItemsBackToBack(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := encode.NewEncoder(buf)
+
+	require.NoError(t, enc.Encode(uint8(1)))
+	require.NoError(t, enc.Encode("a"))
+	require.Error(t, enc.Encode(make(chan int)))
+	require.NoError(t, enc.Encode([]bool{true}))
+
+	expected := []byte{
+		binn.Uint8Type, 0x01,				// [type] = uint8, [data] (1)
+		binn.StringType, 0x01, 'a', 0x00,	// [type] = string, [size], [data] (null terminated)
+		binn.ListType, 0x04, 0x01,			// [type] list (container), [size], [count]
+		binn.True,							// [type] = true
+	}
+	assert.Equal(t, expected, buf.Bytes())
+}
+
+func TestEncodeMapIntKeys(t *testing.T) {
+	expected := []byte{
+		binn.MapType, 0x08, 0x01,	// [type] map, [size], [count]
+		0x00, 0x00, 0x00, 0x07,		// [key]
+		binn.True,					// [type] = true
+	}
+
+	for _, v := range []interface{}{
+		map[int8]bool{7: true},
+		map[int64]bool{7: true},
+		map[uint8]bool{7: true},
+		map[uint64]bool{7: true},
+	} {
+		result, err := encode.Marshal(v)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	}
+}
+
+func TestEncodeMapKeyOverflow(t *testing.T) {
+	for _, v := range []interface{}{
+		map[int64]bool{1 << 40: true},
+		map[int64]bool{-1 << 31 - 1: true},
+		map[uint64]bool{1 << 31: true},
+		map[uint]bool{1 << 32: true},
+	} {
+		_, err := encode.Marshal(v)
+
+		var e *encode.MapKeyError
+		assert.ErrorAs(t, err, &e)
+	}
+
+	_, err := encode.Marshal(map[int32]bool{-1 << 31: true})
+	assert.NoError(t, err)
+}
+
+func TestEncodeStructWithMapKeys(t *testing.T) {
+	type msg struct {
+		ID   int    `binn:"#1"`
+		Name string `binn:"#-2"`
+	}
+
+	result, err := encode.Marshal(msg{5, "a"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		binn.MapType, 0x11, 0x02,			// [type] map, [size], [count]
+		0x00, 0x00, 0x00, 0x01,				// [key]
+		binn.Uint8Type, 0x05,				// [type] = uint8, [data]
+		0xFF, 0xFF, 0xFF, 0xFE,				// [key] -2
+		binn.StringType, 0x01, 'a', 0x00,	// [type] = string, [size], [data]
+	}, result)
+}
+
+func TestEncodeStructWithInvalidMapKeys(t *testing.T) {
+	type mixed struct {
+		ID   int    `binn:"#1"`
+		Name string `binn:"name"`
+	}
+
+	_, err := encode.Marshal(mixed{})
+	assert.ErrorIs(t, err, encode.ErrMixedKeys)
+
+	type invalid struct {
+		ID int `binn:"#x"`
+	}
+
+	_, err = encode.Marshal(invalid{})
+	assert.ErrorIs(t, err, encode.ErrInvalidMapKeyTag)
+}
+
+func TestEncoderNaming(t *testing.T) {
+	type user struct {
+		UserID    int
+		FirstName string `binn:"name"`
+		HTTPAddr  string `binn:",omitempty"`
+	}
+	buf := &bytes.Buffer{}
+	enc := encode.NewEncoder(buf)
+	enc.SetNaming(naming.SnakeCase)
+
+	require.NoError(t, enc.Encode(user{1, "a", "b"}))
+
+	assert.Equal(t, []byte{
+		binn.ObjectType, 0x24, 0x03,							// [type] object, [size], [count]
+		0x07, 'u', 's', 'e', 'r', '_', 'i', 'd',				// [key]
+		binn.Uint8Type, 0x01,									// [type] = uint8, [data]
+		0x04, 'n', 'a', 'm', 'e',								// [key]
+		binn.StringType, 0x01, 'a', 0x00,						// [type] = string, [size], [data]
+		0x09, 'h', 't', 't', 'p', '_', 'a', 'd', 'd', 'r',		// [key]
+		binn.StringType, 0x01, 'b', 0x00,						// [type] = string, [size], [data]
+	}, buf.Bytes())
+
+	buf.Reset()
+	enc.SetNaming(nil)
+	require.NoError(t, enc.Encode(struct{ UserID int }{1}))
+
+	assert.Equal(t, []byte{
+		binn.ObjectType, 0x0C, 0x01,						// [type] object, [size], [count]
+		0x06, 'U', 's', 'e', 'r', 'I', 'D',				// [key]
+		binn.Uint8Type, 0x01,								// [type] = uint8, [data]
+	}, buf.Bytes())
+}
+
+func TestEncoderNamingKeyTooLong(t *testing.T) {
+	enc := encode.NewEncoder(&bytes.Buffer{})
+	enc.SetNaming(naming.New(func(name string) string {
+		return strings.Repeat(name, 100)
+	}))
+
+	err := enc.Encode(struct{ Name string }{})
+
+	assert.ErrorIs(t, err, encode.ErrKeyTooLong)
+}
@@ -7,6 +7,11 @@ import (
 
 var (
 	ErrInvalidValue = errors.New("invalid value")
+	ErrInvalidItem  = errors.New("invalid item")
+	ErrKeyTooLong   = errors.New("object key is longer than 255 bytes")
+
+	ErrInvalidMapKeyTag = errors.New("map key tag is not an int32")
+	ErrMixedKeys        = errors.New("struct mixes map key tags and object keys")
 )
 
 type UnsupportedTypeError struct {
@@ -17,6 +22,34 @@ func (e *UnsupportedTypeError) Error() string {
 	return "binn: unsupported type: " + e.Type.String()
 }
 
+// UnsupportedValueError is returned when attempting to encode
+// an unsupported value, such as a cyclic structure.
+type UnsupportedValueError struct {
+	Value reflect.Value
+	Str   string
+	// Path is the path to the value from the root, like .Next[1]["key"].
+	Path string
+}
+
+func (e *UnsupportedValueError) Error() string {
+	msg := "binn: unsupported value: " + e.Str
+	if e.Path != "" {
+		msg += " at " + e.Path
+	}
+
+	return msg
+}
+
+// A MapKeyError is returned for the integer map keys outside
+// of the int32 range of the keys of BINN maps.
+type MapKeyError struct {
+	Key string
+}
+
+func (e *MapKeyError) Error() string {
+	return "binn: map key " + e.Key + " overflows int32"
+}
+
 type MarshalerError struct {
 	Type       reflect.Type
 	Err        error
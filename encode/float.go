@@ -1,20 +1,21 @@
 package encode
 
 import (
-	"encoding/binary"
 	"math"
 )
 
 func Float32(f float32) []byte {
-	b := make([]byte, 4)
-	binary.BigEndian.PutUint32(b, math.Float32bits(f))
-
-	return b
+	return appendFloat32(make([]byte, 0, 4), f)
 }
 
 func Float64(f float64) []byte {
-	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b, math.Float64bits(f))
+	return appendFloat64(make([]byte, 0, 8), f)
+}
+
+func appendFloat32(b []byte, f float32) []byte {
+	return appendUint32(b, math.Float32bits(f))
+}
 
-	return b
+func appendFloat64(b []byte, f float64) []byte {
+	return appendUint64(b, math.Float64bits(f))
 }
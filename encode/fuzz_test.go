@@ -0,0 +1,53 @@
+//go:build go1.18
+// +build go1.18
+
+package encode
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+)
+
+// FuzzValidItem checks that the validation of marshaler output returns
+// an error instead of panicking, and accepts only the input decode reads
+// as a single item.
+func FuzzValidItem(f *testing.F) {
+	for _, pattern := range []string{
+		"../test/binary/*.bin",
+		"../test/golden/testdata/*.bin",
+	} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(b)
+		}
+	}
+
+	f.Add([]byte{binn.ListType, 0x05, 0x01, binn.Null, binn.Null})
+	f.Add([]byte{binn.MapType, 0x05, 0x01, 0x00, 0x00})
+	f.Add([]byte{0xF0, 0x01, 0x05, 0x01, 0xFF})
+	f.Add(bytes.Repeat([]byte{binn.ListType, 0x7F, 0x01}, 100))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if validItem(b) != nil {
+			return
+		}
+
+		raw, rest, err := decode.ReadItemBytes(b)
+		if err != nil || len(rest) > 0 || !bytes.Equal(raw, b) {
+			t.Errorf("valid item % x read as % x, % x, %v", b, raw, rest, err)
+		}
+	})
+}
@@ -1,7 +1,6 @@
 package encode
 
 import (
-	"encoding/binary"
 	"math"
 
 	"github.com/et-nik/binngo/binn"
@@ -62,10 +61,14 @@ func detectUintType(v uint) intType {
 	}
 }
 
+// detectIntType returns the smallest type holding v, as binn_list_add_int64
+// and the other int functions of the C library choose it: the non-negative
+// values, zero included, are stored as unsigned, so 0 is a uint8 and the
+// values up to math.MaxUint32 fit into uint32.
 func detectIntType(v int) intType {
 	t := binn.Int64Type
 
-	if v > 0 {
+	if v >= 0 {
 		switch t {
 		case binn.Int64Type:
 			t = binn.Uint64Type
@@ -99,7 +102,7 @@ func detectIntType(v int) intType {
 			t = binn.Uint8Type
 		case v <= math.MaxUint16:
 			t = binn.Uint16Type
-		case v <= math.MaxInt32:
+		case int64(v) <= math.MaxUint32:
 			t = binn.Uint32Type
 		}
 	}
@@ -116,57 +119,27 @@ func Uint8(v uint8) []byte {
 }
 
 func Uint16(v uint16) []byte {
-	t := make([]byte, 2)
-	binary.BigEndian.PutUint16(t, v)
-
-	var r []byte
-	r = append(r, t...)
-
-	return r
+	return appendUint16(make([]byte, 0, 2), v)
 }
 
 func Int16(v int16) []byte {
-	t := make([]byte, 2)
-	binary.BigEndian.PutUint16(t, uint16(v))
-
-	var r []byte
-	r = append(r, t...)
-
-	return r
+	return appendUint16(make([]byte, 0, 2), uint16(v))
 }
 
 func Uint32(v uint32) []byte {
-	t := make([]byte, 4)
-	binary.BigEndian.PutUint32(t, v)
-
-	var r []byte
-	r = append(r, t...)
-
-	return r
+	return appendUint32(make([]byte, 0, 4), v)
 }
 
 func Int32(v int32) []byte {
-	t := make([]byte, 4)
-	binary.BigEndian.PutUint32(t, uint32(v))
-
-	var r []byte
-	r = append(r, t...)
-
-	return r
+	return appendUint32(make([]byte, 0, 4), uint32(v))
 }
 
 func Uint64(v uint64) []byte {
-	t := make([]byte, 8)
-	binary.BigEndian.PutUint64(t, v)
-
-	return t
+	return appendUint64(make([]byte, 0, 8), v)
 }
 
 func Int64(v int64) []byte {
-	t := make([]byte, 8)
-	binary.BigEndian.PutUint64(t, uint64(v))
-
-	return t
+	return appendUint64(make([]byte, 0, 8), uint64(v))
 }
 
 func Size(size int, totalSize bool) []byte {
@@ -188,10 +161,68 @@ func Size(size int, totalSize bool) []byte {
 }
 
 func encodeSize32(s int) []byte {
-	i := s | (-1 << 31)
+	return appendSize32(make([]byte, 0, 4), s)
+}
+
+func appendSize(b []byte, size int) []byte {
+	if size <= math.MaxInt8 {
+		return append(b, byte(size))
+	}
+
+	return appendSize32(b, size)
+}
+
+func appendSize32(b []byte, s int) []byte {
+	return appendUint32(b, uint32(s|(-1<<31)))
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return append(b,
+		byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32),
+		byte(v>>24), byte(v>>16), byte(v>>8), byte(v),
+	)
+}
+
+// appendInt appends the type and the value of v using the same
+// storage type as Int.
+func appendInt(b []byte, v int) []byte {
+	t := detectIntType(v)
+	b = append(b, uint8(t))
+
+	switch t {
+	case binn.Int8Type, binn.Uint8Type:
+		return append(b, byte(v))
+	case binn.Int16Type, binn.Uint16Type:
+		return appendUint16(b, uint16(v))
+	case binn.Int32Type, binn.Uint32Type:
+		return appendUint32(b, uint32(v))
+	default:
+		return appendUint64(b, uint64(v))
+	}
+}
 
-	b := make([]byte, 4)
-	binary.BigEndian.PutUint32(b, uint32(i))
+// appendUint appends the type and the value of v using the same
+// storage type as Uint.
+func appendUint(b []byte, v uint) []byte {
+	t := detectUintType(v)
+	b = append(b, uint8(t))
 
-	return b
+	switch t {
+	case binn.Uint8Type:
+		return append(b, byte(v))
+	case binn.Uint16Type:
+		return appendUint16(b, uint16(v))
+	case binn.Uint32Type:
+		return appendUint32(b, uint32(v))
+	default:
+		return appendUint64(b, uint64(v))
+	}
 }
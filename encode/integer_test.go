@@ -1,6 +1,7 @@
 package encode
 
 import (
+	"math"
 	"testing"
 
 	"github.com/et-nik/binngo/binn"
@@ -55,3 +56,32 @@ func TestIntPack(t *testing.T) {
 		})
 	}
 }
+
+func TestIntPackBoundaries(t *testing.T) {
+	tests := []struct {
+		name        string
+		int         int
+		binExpected []byte
+	}{
+		{"zero", 0, []byte{binn.Uint8Type, 0x00}},
+		{"MaxInt8+1", math.MaxInt8 + 1, []byte{binn.Uint8Type, 0x80}},
+		{"MaxUint8+1", math.MaxUint8 + 1, []byte{binn.Uint16Type, 0x01, 0x00}},
+		{"MaxInt16+1", math.MaxInt16 + 1, []byte{binn.Uint16Type, 0x80, 0x00}},
+		{"MaxInt32+1", math.MaxInt32 + 1, []byte{binn.Uint32Type, 0x80, 0x00, 0x00, 0x00}},
+		{"MaxUint32", math.MaxUint32, []byte{binn.Uint32Type, 0xff, 0xff, 0xff, 0xff}},
+		{"MaxUint32+1", math.MaxUint32 + 1, []byte{binn.Uint64Type, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}},
+		{"-1", -1, []byte{binn.Int8Type, 0xff}},
+		{"MinInt8-1", math.MinInt8 - 1, []byte{binn.Int16Type, 0xff, 0x7f}},
+		{"MinInt16-1", math.MinInt16 - 1, []byte{binn.Int32Type, 0xff, 0xff, 0x7f, 0xff}},
+		{"MinInt32-1", math.MinInt32 - 1, []byte{binn.Int64Type, 0xff, 0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0xff}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := Marshal(test.int)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.binExpected, b)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package encode
 
 import (
 	"encoding"
+	"fmt"
 	"reflect"
 
 	"github.com/et-nik/binngo/binn"
@@ -11,25 +12,27 @@ func newMapEncoder(t reflect.Type) encoderFunc {
 	switch t.Key().Kind() {
 	case reflect.String:
 		me := mapObjectEncoder{
-			newTypeEncoder(t.Elem()),
+			loadEncodeFunc(t.Elem()),
 			t,
 		}
 		return me.encode
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		me := mapEncoder{newTypeEncoder(t.Elem())}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		me := mapEncoder{loadEncodeFunc(t.Elem()), true}
+		return me.encode
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		me := mapEncoder{loadEncodeFunc(t.Elem()), false}
 		return me.encode
 	default:
 		if t.Key().Implements(textMarshalerType) {
 			me := mapObjectEncoder{
-				newTypeEncoder(t.Elem()),
+				loadEncodeFunc(t.Elem()),
 				t,
 			}
 			return me.encode
 		}
 
-		return func(_ reflect.Value) ([]byte, error) {
-			return nil, &UnsupportedTypeError{t}
+		return func(_ *encodeState, _ reflect.Value) error {
+			return &UnsupportedTypeError{t}
 		}
 	}
 }
@@ -39,90 +42,109 @@ type mapObjectEncoder struct {
 	itemType reflect.Type
 }
 
-func (me *mapObjectEncoder) encode(v reflect.Value) ([]byte, error) {
-	dataBytes := []byte{}
+func (me *mapObjectEncoder) encode(e *encodeState, v reflect.Value) error {
+	if !v.IsNil() {
+		if err := e.enter(v); err != nil {
+			return err
+		}
+		defer e.leave(v)
+	}
 
-	keys := v.MapKeys()
+	start := e.beginContainer(binn.ObjectType, v.Len())
 
-	for _, key := range keys {
-		bval, err := encodeTextKey(key)
-		if err != nil {
-			return nil, err
-		}
-		dataBytes = append(dataBytes, bval...)
+	iter := v.MapRange()
+
+	for iter.Next() {
+		key := iter.Key()
 
-		encodedValue, err := me.elemEnc(v.MapIndex(key))
+		err := e.appendTextKey(key)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		dataBytes = append(dataBytes, encodedValue...)
+		if err := me.elemEnc(e, iter.Value()); err != nil {
+			return withPath(err, fmt.Sprintf("[%q]", fmt.Sprint(key)))
+		}
 	}
 
-	bytes := []byte{}
-
-	typeBytes := Uint8(binn.ObjectType)
-	countBytes := Size(len(keys), false)
+	e.endContainer(start)
 
-	bytes = append(bytes, typeBytes...)
-	bytes = append(bytes, Size(len(typeBytes)+len(dataBytes)+len(countBytes), true)...)
-	bytes = append(bytes, countBytes...)
-	bytes = append(bytes, dataBytes...)
-
-	return bytes, nil
+	return nil
 }
 
+// mapEncoder encodes maps with integer keys, which have to fit
+// into int32.
 type mapEncoder struct {
 	elemEnc encoderFunc
+	signed  bool
 }
 
-func (me *mapEncoder) encode(v reflect.Value) ([]byte, error) {
-	var dataBytes []byte
+func (me *mapEncoder) encode(e *encodeState, v reflect.Value) error {
+	if !v.IsNil() {
+		if err := e.enter(v); err != nil {
+			return err
+		}
+		defer e.leave(v)
+	}
+
+	start := e.beginContainer(binn.MapType, v.Len())
+
+	var err error
 
 	iter := v.MapRange()
 
 	for iter.Next() {
 		key := iter.Key()
-		val := iter.Value()
 
-		dataBytes = append(dataBytes, Int32(int32(key.Int()))...)
-
-		encodedValue, err := me.elemEnc(val)
+		var k int64
+		if me.signed {
+			k = key.Int()
+			err = CheckIntMapKey(k)
+		} else {
+			k = int64(key.Uint())
+			err = CheckUintMapKey(key.Uint())
+		}
 		if err != nil {
-			return nil, err
+			return err
 		}
-		dataBytes = append(dataBytes, encodedValue...)
-	}
-
-	keys := v.MapKeys()
 
-	bytes := []byte{}
+		e.buf = appendUint32(e.buf, uint32(int32(k)))
 
-	typeBytes := Uint8(binn.MapType)
-	countBytes := Size(len(keys), false)
+		if err := me.elemEnc(e, iter.Value()); err != nil {
+			return withPath(err, fmt.Sprintf("[%v]", key))
+		}
+	}
 
-	bytes = append(bytes, typeBytes...)
-	bytes = append(bytes, Size(len(typeBytes)+len(dataBytes)+len(countBytes), true)...)
-	bytes = append(bytes, countBytes...)
-	bytes = append(bytes, dataBytes...)
+	e.endContainer(start)
 
-	return bytes, nil
+	return nil
 }
 
-func encodeTextKey(v reflect.Value) ([]byte, error) {
+func (e *encodeState) appendTextKey(v reflect.Value) error {
 	if v.Kind() == reflect.String {
-		return String(v.String()), nil
+		return e.appendObjectKey(v.String())
 	}
 
 	m, ok := v.Interface().(encoding.TextMarshaler)
 	if !ok {
-		return []byte{binn.Null}, nil
+		e.buf = append(e.buf, binn.Null)
+		return nil
 	}
 
 	s, err := m.MarshalText()
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	return e.appendObjectKey(string(s))
+}
+
+func (e *encodeState) appendObjectKey(key string) error {
+	if len(key) > binn.MaxKeySize {
+		return fmt.Errorf("binn: %w: %.32q...", ErrKeyTooLong, key)
 	}
 
-	return String(string(s)), nil
+	e.buf = appendObjectKey(e.buf, key)
+
+	return nil
 }
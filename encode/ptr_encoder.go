@@ -11,14 +11,20 @@ type ptrEncoder struct {
 }
 
 func newPtrEncoder(t reflect.Type) encoderFunc {
-	enc := ptrEncoder{newTypeEncoder(t.Elem())}
+	enc := ptrEncoder{loadEncodeFunc(t.Elem())}
 	return enc.encode
 }
 
-func (pe ptrEncoder) encode(v reflect.Value) ([]byte, error) {
+func (pe ptrEncoder) encode(e *encodeState, v reflect.Value) error {
 	if v.IsNil() {
-		return []byte{binn.Null}, nil
+		e.buf = append(e.buf, binn.Null)
+		return nil
 	}
 
-	return pe.elemEnc(v.Elem())
+	if err := e.enter(v); err != nil {
+		return err
+	}
+	defer e.leave(v)
+
+	return pe.elemEnc(e, v.Elem())
 }
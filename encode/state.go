@@ -0,0 +1,136 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/et-nik/binngo/naming"
+)
+
+// DefaultCycleDetectionDepth is the nesting depth of pointers, maps and
+// slices after which the encoder starts to track visited values.
+const DefaultCycleDetectionDepth = 1000
+
+// maxPooledBufferSize limits the capacity of buffers kept in the pool,
+// so a single huge document doesn't pin its memory forever.
+const maxPooledBufferSize = 64 << 10
+
+// encodeState keeps the state of a single Marshal call. Every encoder
+// appends to the same buffer, and containers backpatch their size
+// once their items are written.
+type encodeState struct {
+	buf []byte
+
+	// Keep track of what pointers we've seen in the current recursive call
+	// path, to avoid cycles that could lead to a stack overflow. Only do
+	// the relatively expensive map operations if ptrLevel is larger than
+	// cycleDetectionDepth, so that we skip the work if we're within
+	// a reasonable amount of nested pointers deep.
+	ptrLevel            uint
+	ptrSeen             map[visit]struct{}
+	cycleDetectionDepth uint
+
+	// naming derives the keys of the untagged struct fields.
+	naming *naming.Strategy
+}
+
+// visit identifies a pointer, map or slice value. Slices sharing
+// the same array but having different lengths are different values.
+type visit struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+var encodeStatePool sync.Pool
+
+func newEncodeState() *encodeState {
+	if v := encodeStatePool.Get(); v != nil {
+		e := v.(*encodeState)
+		e.buf = e.buf[:0]
+		e.ptrLevel = 0
+		e.cycleDetectionDepth = DefaultCycleDetectionDepth
+		e.naming = nil
+
+		return e
+	}
+
+	return &encodeState{
+		ptrSeen:             make(map[visit]struct{}),
+		cycleDetectionDepth: DefaultCycleDetectionDepth,
+	}
+}
+
+// release returns the state to the pool. The buffer must not be used
+// after the release.
+func (e *encodeState) release() {
+	if cap(e.buf) > maxPooledBufferSize {
+		return
+	}
+
+	for k := range e.ptrSeen {
+		delete(e.ptrSeen, k)
+	}
+
+	encodeStatePool.Put(e)
+}
+
+// beginContainer writes the container type, a one byte size slot and
+// the items count. It returns the container offset for endContainer.
+func (e *encodeState) beginContainer(containerType uint8, count int) int {
+	var start int
+	e.buf, start = BeginContainer(e.buf, containerType, count)
+
+	return start
+}
+
+// endContainer backpatches the size of the container started at start.
+func (e *encodeState) endContainer(start int) {
+	e.buf = EndContainer(e.buf, start)
+}
+
+func visitOf(v reflect.Value) visit {
+	vis := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		vis.len = v.Len()
+	}
+
+	return vis
+}
+
+// enter registers the visit of the pointer, map or slice v. It returns
+// an error if v was already visited in the current recursive call path.
+func (e *encodeState) enter(v reflect.Value) error {
+	e.ptrLevel++
+	if e.ptrLevel <= e.cycleDetectionDepth {
+		return nil
+	}
+
+	vis := visitOf(v)
+	if _, ok := e.ptrSeen[vis]; ok {
+		e.ptrLevel--
+		return &UnsupportedValueError{v, fmt.Sprintf("encountered a cycle via %s", v.Type()), ""}
+	}
+	e.ptrSeen[vis] = struct{}{}
+
+	return nil
+}
+
+func (e *encodeState) leave(v reflect.Value) {
+	if e.ptrLevel > e.cycleDetectionDepth {
+		delete(e.ptrSeen, visitOf(v))
+	}
+	e.ptrLevel--
+}
+
+// withPath prepends the path segment to the path of UnsupportedValueError.
+func withPath(err error, segment string) error {
+	var uve *UnsupportedValueError
+	if errors.As(err, &uve) {
+		uve.Path = segment + uve.Path
+	}
+
+	return err
+}
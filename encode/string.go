@@ -8,27 +8,50 @@ import (
 )
 
 func String(s string) []byte {
-	var t []byte
+	return appendStringData(make([]byte, 0, len(s)+4), s)
+}
 
-	t = append(t, Size(len(s), false)...)
-	t = append(t, []byte(s)...)
+// appendStringData appends the size and the data of s without the type
+// and the null terminator.
+func appendStringData(b []byte, s string) []byte {
+	b = appendSize(b, len(s))
+	return append(b, s...)
+}
 
-	return t
+// appendObjectKey appends an object key stored as a one byte size
+// and the key bytes. The key must not be longer than binn.MaxKeySize.
+func appendObjectKey(b []byte, key string) []byte {
+	b = append(b, byte(len(key)))
+	return append(b, key...)
 }
 
-func textMarshalerEncoder(v reflect.Value) ([]byte, error) {
+// appendString appends a complete null terminated string item.
+func appendString(b []byte, s string) []byte {
+	b = append(b, binn.StringType)
+	b = appendStringData(b, s)
+	return append(b, 0x00)
+}
+
+func textMarshalerEncoder(e *encodeState, v reflect.Value) error {
 	if v.Kind() == reflect.Ptr && v.IsNil() {
-		return []byte{binn.Null}, nil
+		e.buf = append(e.buf, binn.Null)
+		return nil
 	}
 
 	m, ok := v.Interface().(encoding.TextMarshaler)
 	if !ok {
-		return []byte{binn.Null}, nil
+		e.buf = append(e.buf, binn.Null)
+		return nil
 	}
 	b, err := m.MarshalText()
 	if err != nil {
-		return nil, err
+		return &MarshalerError{v.Type(), err, "MarshalText"}
 	}
 
-	return String(string(b)), nil
+	e.buf = append(e.buf, binn.StringType)
+	e.buf = appendSize(e.buf, len(b))
+	e.buf = append(e.buf, b...)
+	e.buf = append(e.buf, 0x00)
+
+	return nil
 }
@@ -1,50 +1,182 @@
 package encode
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/naming"
 )
 
+// structEncoder encodes structs into objects, or into maps if their
+// fields are tagged with map keys.
 type structEncoder struct {
-	t reflect.Type
+	containerType uint8
+	fields        []field
+
+	// named are the fields with the keys of the untagged fields
+	// derived by a naming strategy.
+	named sync.Map // map[*naming.Strategy]namedFields
+}
+
+// field is a struct field with its precomputed object or map key.
+type field struct {
+	name   string
+	key    []byte
+	tagged bool
+	index  int
+	enc    encoderFunc
+}
+
+type namedFields struct {
+	fields []field
+	err    error
 }
 
 func newStructEncoder(t reflect.Type) encoderFunc {
-	se := structEncoder{t}
+	se := structEncoder{
+		containerType: binn.ObjectType,
+		fields:        make([]field, 0, t.NumField()),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		key, err := se.fieldKey(f, i)
+		if err != nil {
+			return func(*encodeState, reflect.Value) error {
+				return err
+			}
+		}
+
+		se.fields = append(se.fields, field{
+			name:   f.Name,
+			key:    key,
+			tagged: tagName(f) != "",
+			index:  i,
+			enc:    loadEncodeFunc(f.Type),
+		})
+	}
+
 	return se.encode
 }
 
-func (se *structEncoder) encode(v reflect.Value) ([]byte, error) {
-	dataBytes := []byte{}
+// namedFields returns the fields with the keys of the untagged fields
+// derived by the strategy s.
+func (se *structEncoder) namedFields(s *naming.Strategy) ([]field, error) {
+	if nf, ok := se.named.Load(s); ok {
+		return nf.(namedFields).fields, nf.(namedFields).err
+	}
 
-	for i := 0; i < v.NumField(); i++ {
-		keyName := strings.Split(se.t.Field(i).Tag.Get("binn"), ",")[0]
-		if keyName == "" {
-			keyName = se.t.Field(i).Name
-		}
+	fields := make([]field, len(se.fields))
+	copy(fields, se.fields)
 
-		dataBytes = append(dataBytes, String(keyName)...)
+	var err error
 
-		encodeValue := loadEncodeFunc(v.Field(i).Type())
-		val, err := encodeValue(v.Field(i))
-		if err != nil {
-			return nil, err
+	for i := range fields {
+		f := &fields[i]
+		if f.tagged {
+			continue
 		}
 
-		dataBytes = append(dataBytes, val...)
+		key := s.Key(f.name)
+		if len(key) > binn.MaxKeySize {
+			err = fmt.Errorf("binn: field %s: %w", f.name, ErrKeyTooLong)
+			break
+		}
+
+		f.key = appendObjectKey(nil, key)
+	}
+
+	se.named.Store(s, namedFields{fields, err})
+
+	return fields, err
+}
+
+// fieldKey returns the encoded key of the field i. The type of
+// the container is set by the first field.
+func (se *structEncoder) fieldKey(f reflect.StructField, i int) ([]byte, error) {
+	mapKey, isMapKey, err := FieldMapKey(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if i == 0 && isMapKey {
+		se.containerType = binn.MapType
+	}
+
+	if isMapKey != (se.containerType == binn.MapType) {
+		return nil, fmt.Errorf("binn: field %s: %w", f.Name, ErrMixedKeys)
+	}
+
+	if isMapKey {
+		return appendUint32(nil, uint32(mapKey)), nil
+	}
+
+	keyName := FieldKey(f)
+	if len(keyName) > binn.MaxKeySize {
+		return nil, fmt.Errorf("binn: field %s: %w", f.Name, ErrKeyTooLong)
+	}
+
+	return appendObjectKey(nil, keyName), nil
+}
+
+// FieldKey returns the object key of a struct field: the name from
+// its binn tag, or the field name if the tag has none.
+func FieldKey(f reflect.StructField) string {
+	if name := tagName(f); name != "" {
+		return name
+	}
+
+	return f.Name
+}
+
+func tagName(f reflect.StructField) string {
+	return strings.Split(f.Tag.Get("binn"), ",")[0]
+}
+
+// FieldMapKey returns the map key of a struct field tagged with
+// a map key, such as `binn:"#12"`. The structs with such fields are
+// encoded into maps, and all their fields have to be tagged so.
+func FieldMapKey(f reflect.StructField) (int32, bool, error) {
+	name := FieldKey(f)
+	if !strings.HasPrefix(name, "#") {
+		return 0, false, nil
 	}
 
-	bytes := []byte{}
+	k, err := strconv.ParseInt(name[1:], 10, 32)
+	if err != nil {
+		return 0, false, fmt.Errorf("binn: field %s: %w %q", f.Name, ErrInvalidMapKeyTag, name)
+	}
 
-	typeBytes := Uint8(binn.ObjectType)
-	countBytes := Size(v.NumField(), false)
+	return int32(k), true, nil
+}
+
+func (se *structEncoder) encode(e *encodeState, v reflect.Value) error {
+	fields := se.fields
+	if e.naming != nil && se.containerType == binn.ObjectType {
+		var err error
+		if fields, err = se.namedFields(e.naming); err != nil {
+			return err
+		}
+	}
+
+	start := e.beginContainer(se.containerType, len(fields))
+
+	for i := range fields {
+		f := &fields[i]
+
+		e.buf = append(e.buf, f.key...)
+
+		if err := f.enc(e, v.Field(f.index)); err != nil {
+			return withPath(err, "."+f.name)
+		}
+	}
 
-	bytes = append(bytes, typeBytes...)
-	bytes = append(bytes, Size(len(typeBytes)+len(dataBytes)+len(countBytes), true)...)
-	bytes = append(bytes, countBytes...)
-	bytes = append(bytes, dataBytes...)
+	e.endContainer(start)
 
-	return bytes, nil
+	return nil
 }
@@ -0,0 +1,12 @@
+//go:build go1.18
+// +build go1.18
+
+package encode
+
+// MarshalAs returns the BINN encoding of v. It's the typed counterpart
+// of Marshal and encodes v byte for byte the same: like a value passed
+// to Marshal, v isn't addressable, so the marshaling methods of *T
+// aren't called.
+func MarshalAs[T any](v T) ([]byte, error) {
+	return Marshal(v)
+}
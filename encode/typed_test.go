@@ -0,0 +1,47 @@
+//go:build go1.18
+// +build go1.18
+
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalAs(t *testing.T) {
+	v := map[string][]int{"k": {1, -2}}
+	want, err := Marshal(v)
+	require.NoError(t, err)
+
+	got, err := MarshalAs(v)
+
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestMarshalAsNilInterface(t *testing.T) {
+	_, err := MarshalAs[interface{}](nil)
+
+	assert.ErrorIs(t, err, ErrInvalidValue)
+}
+
+type ptrMarshaler struct {
+	A int
+}
+
+func (*ptrMarshaler) MarshalBINN() ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func TestMarshalAsPointerReceiver(t *testing.T) {
+	want, err := Marshal(ptrMarshaler{1})
+	require.NoError(t, err)
+
+	got, err := MarshalAs(ptrMarshaler{1})
+
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.NotEqual(t, []byte{0x01}, got)
+}
@@ -0,0 +1,92 @@
+package encode
+
+import (
+	"fmt"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+)
+
+// validItem checks that b holds exactly one complete BINN item.
+func validItem(b []byte) error {
+	rest, err := checkItem(b)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidItem, err)
+	}
+
+	if len(rest) > 0 {
+		return fmt.Errorf("%w: trailing data at offset %d", ErrInvalidItem, len(b)-len(rest))
+	}
+
+	return nil
+}
+
+// checkItem checks the item at the start of b, and the items of lists,
+// maps and objects recursively, and returns the bytes after the item.
+func checkItem(b []byte) ([]byte, error) {
+	raw, rest, err := decode.ReadItemBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	btype, _ := decode.ReadTypeBytes(raw)
+	if !btype.IsContainer() {
+		return rest, nil
+	}
+
+	// ReadItemBytes clips the containers running past the end of b.
+	header := raw[1:]
+	if btype > 0xFF {
+		header = raw[2:]
+	}
+
+	size, _, err := decode.ReadSizeBytes(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if size != len(raw) {
+		return nil, fmt.Errorf("container size %d exceeds the %d bytes left", size, len(raw))
+	}
+
+	var (
+		count int
+		items []byte
+	)
+
+	switch btype {
+	case binn.ListType:
+		count, items, _, err = decode.ReadListBytes(raw)
+	case binn.MapType:
+		count, items, _, err = decode.ReadMapBytes(raw)
+	case binn.ObjectType:
+		count, items, _, err = decode.ReadObjectBytes(raw)
+	default:
+		return nil, fmt.Errorf("unknown container type %s", btype)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < count; i++ {
+		switch btype {
+		case binn.MapType:
+			_, items, err = decode.ReadMapKeyBytes(items)
+		case binn.ObjectType:
+			_, items, err = decode.ReadObjectKeyBytes(items)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if items, err = checkItem(items); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(items) > 0 {
+		return nil, fmt.Errorf("%d bytes left after the items of %s", len(items), btype)
+	}
+
+	return rest, nil
+}
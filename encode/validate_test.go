@@ -0,0 +1,50 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidItem(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  []byte
+		valid bool
+	}{
+		{"null", []byte{binn.Null}, true},
+		{"uint16", []byte{binn.Uint16Type, 0x03, 0x15}, true},
+		{"string", []byte{binn.StringType, 0x02, 'h', 'i', 0x00}, true},
+		{"blob", []byte{binn.BlobType, 0x02, 0x01, 0x02}, true},
+		{"list", []byte{binn.ListType, 0x05, 0x01, binn.Uint8Type, 0x01}, true},
+		{"map", []byte{binn.MapType, 0x09, 0x01, 0x00, 0x00, 0x00, 0x01, binn.Uint8Type, 0x01}, true},
+		{"object", []byte{binn.ObjectType, 0x07, 0x01, 0x01, 'a', binn.Uint8Type, 0x01}, true},
+		{"4 bytes size list", []byte{binn.ListType, 0x80, 0x00, 0x00, 0x08, 0x01, binn.Uint8Type, 0x01}, true},
+		{"2 bytes type string", []byte{0xB0, 0x01, 0x02, 'h', 'i', 0x00}, true},
+		{"2 bytes type in list", []byte{binn.ListType, 0x09, 0x01, 0xB0, 0x01, 0x02, 'h', 'i', 0x00}, true},
+		{"empty", []byte{}, false},
+		{"truncated uint16", []byte{binn.Uint16Type, 0x03}, false},
+		{"trailing data", []byte{binn.Uint8Type, 0x01, 0x02}, false},
+		{"unterminated string", []byte{binn.StringType, 0x02, 'h', 'i', 'x'}, false},
+		{"truncated blob", []byte{binn.BlobType, 0x05, 0x01, 0x02}, false},
+		{"list size too big", []byte{binn.ListType, 0x06, 0x01, binn.Uint8Type, 0x01}, false},
+		{"list size too small", []byte{binn.ListType, 0x04, 0x01, binn.Uint8Type, 0x01}, false},
+		{"list count too big", []byte{binn.ListType, 0x05, 0x02, binn.Uint8Type, 0x01}, false},
+		{"object key overflow", []byte{binn.ObjectType, 0x05, 0x01, 0x09, 'a'}, false},
+		{"unknown container", []byte{0xE5, 0x04, 0x01, binn.Null}, false},
+		{"truncated 2 bytes type string", []byte{0xB0, 0x01, 0x02, 'h', 'i'}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validItem(test.item)
+
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidItem)
+			}
+		})
+	}
+}
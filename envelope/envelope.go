@@ -0,0 +1,125 @@
+// Package envelope wraps BINN-encoded values in integrity-checked
+// envelopes, for the messages passing through untrusted systems.
+//
+// An envelope is a BINN object:
+//
+//	{"v": 1, "alg": "hmac-sha256", "body": <blob>, "mac": <blob>}
+//
+// The body is the encoding of the value by Marshal, and the mac is
+// computed over the exact body bytes: a CRC32C checksum (Castagnoli,
+// 4 bytes, big endian) detecting corruption, or an HMAC-SHA256 signature
+// detecting tampering. Open verifies the mac before decoding the body.
+package envelope
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/crc32"
+
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+)
+
+// Version is the version of the envelopes written by Seal.
+const Version = 1
+
+// Algorithm is the algorithm computing the mac of an envelope.
+type Algorithm string
+
+const (
+	// CRC32C checksums the body with CRC-32 with the Castagnoli polynomial.
+	CRC32C Algorithm = "crc32c"
+	// HMACSHA256 signs the body with HMAC-SHA256.
+	HMACSHA256 Algorithm = "hmac-sha256"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+type envelope struct {
+	Version   int       `binn:"v"`
+	Algorithm Algorithm `binn:"alg"`
+	Body      blob      `binn:"body"`
+	MAC       blob      `binn:"mac"`
+}
+
+// blob is a byte slice stored as a blob.
+type blob []byte
+
+func (b blob) MarshalBinary() ([]byte, error) {
+	return b, nil
+}
+
+func (b *blob) UnmarshalBinary(data []byte) error {
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// Seal returns an envelope of the BINN encoding of v with the mac
+// computed by alg. The key is the HMAC key, and is ignored by CRC32C.
+func Seal(v interface{}, alg Algorithm, key []byte) ([]byte, error) {
+	body, err := encode.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	mac, err := computeMAC(alg, key, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return encode.Marshal(&envelope{Version, alg, body, mac})
+}
+
+// Open verifies the envelope in data with the algorithm alg and the key,
+// and decodes its body into v.
+//
+// Envelopes of other versions are rejected with a *VersionError, and
+// envelopes of other algorithms with an *AlgorithmError, so a signed
+// envelope can't be replaced by a checksummed one. A mac mismatch is
+// reported with a *VerificationError, and v is left unchanged.
+func Open(data []byte, v interface{}, alg Algorithm, key []byte) error {
+	var e envelope
+	if err := decode.Unmarshal(data, &e); err != nil {
+		return &MalformedError{err}
+	}
+
+	if e.Version != Version {
+		return &VersionError{e.Version}
+	}
+	if e.Algorithm != alg {
+		return &AlgorithmError{Got: e.Algorithm, Want: alg}
+	}
+
+	mac, err := computeMAC(alg, key, e.Body)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(mac, e.MAC) {
+		return &VerificationError{alg}
+	}
+
+	return decode.Unmarshal(e.Body, v)
+}
+
+func computeMAC(alg Algorithm, key, body []byte) ([]byte, error) {
+	switch alg {
+	case CRC32C:
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], crc32.Checksum(body, castagnoli))
+
+		return b[:], nil
+	case HMACSHA256:
+		if len(key) == 0 {
+			return nil, ErrNoKey
+		}
+
+		h := hmac.New(sha256.New, key)
+		h.Write(body)
+
+		return h.Sum(nil), nil
+	}
+
+	return nil, &AlgorithmError{Got: alg}
+}
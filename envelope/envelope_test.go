@@ -0,0 +1,210 @@
+package envelope_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+	"github.com/et-nik/binngo/envelope"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type message struct {
+	ID   int    `binn:"id"`
+	Text string `binn:"text"`
+}
+
+var key = []byte("secret key")
+
+func TestSealOpen(t *testing.T) {
+	for _, alg := range []envelope.Algorithm{envelope.CRC32C, envelope.HMACSHA256} {
+		t.Run(string(alg), func(t *testing.T) {
+			b, err := envelope.Seal(message{1, "hello"}, alg, key)
+			require.NoError(t, err)
+
+			var m message
+			err = envelope.Open(b, &m, alg, key)
+
+			require.NoError(t, err)
+			assert.Equal(t, message{1, "hello"}, m)
+		})
+	}
+}
+
+func TestWireFormat(t *testing.T) {
+	body, err := encode.Marshal(message{1, "hello"})
+	require.NoError(t, err)
+
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.Checksum(body, crc32.MakeTable(crc32.Castagnoli)))
+
+	h := hmac.New(sha256.New, key)
+	h.Write(body)
+
+	tests := []struct {
+		alg envelope.Algorithm
+		mac []byte
+	}{
+		{envelope.CRC32C, crc},
+		{envelope.HMACSHA256, h.Sum(nil)},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.alg), func(t *testing.T) {
+			b, err := envelope.Seal(message{1, "hello"}, test.alg, key)
+			require.NoError(t, err)
+
+			var e map[string]interface{}
+			require.NoError(t, decode.Unmarshal(b, &e))
+
+			assert.Equal(t, map[string]interface{}{
+				"v":    uint8(1),
+				"alg":  string(test.alg),
+				"body": body,
+				"mac":  test.mac,
+			}, e)
+		})
+	}
+}
+
+// wire is the envelope as stored, for building altered envelopes.
+type wire struct {
+	Version   int    `binn:"v"`
+	Algorithm string `binn:"alg"`
+	Body      blob   `binn:"body"`
+	MAC       blob   `binn:"mac"`
+}
+
+type blob []byte
+
+func (b blob) MarshalBinary() ([]byte, error) {
+	return b, nil
+}
+
+func (b *blob) UnmarshalBinary(data []byte) error {
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// alter returns the envelope in data changed by fn.
+func alter(t *testing.T, data []byte, fn func(w *wire)) []byte {
+	t.Helper()
+
+	var w wire
+	require.NoError(t, decode.Unmarshal(data, &w))
+
+	fn(&w)
+
+	b, err := encode.Marshal(&w)
+	require.NoError(t, err)
+
+	return b
+}
+
+func seal(t *testing.T, v map[string]interface{}) []byte {
+	t.Helper()
+
+	b, err := encode.Marshal(v)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestOpenErrors(t *testing.T) {
+	signed, err := envelope.Seal(message{1, "hello"}, envelope.HMACSHA256, key)
+	require.NoError(t, err)
+	checksummed, err := envelope.Seal(message{1, "hello"}, envelope.CRC32C, nil)
+	require.NoError(t, err)
+
+	tampered := alter(t, signed, func(w *wire) {
+		w.Body, _ = encode.Marshal(message{2, "hello"})
+	})
+	corrupted := alter(t, checksummed, func(w *wire) {
+		w.Body[len(w.Body)-1] ^= 1
+	})
+	badMAC := alter(t, signed, func(w *wire) {
+		w.MAC[0] ^= 1
+	})
+	truncatedMAC := alter(t, signed, func(w *wire) {
+		w.MAC = w.MAC[:16]
+	})
+
+	tests := []struct {
+		name  string
+		data  []byte
+		alg   envelope.Algorithm
+		key   []byte
+		check func(t *testing.T, err error)
+	}{
+		{"tampered", tampered, envelope.HMACSHA256, key, isVerificationError},
+		{"corrupted", corrupted, envelope.CRC32C, nil, isVerificationError},
+		{"bad mac", badMAC, envelope.HMACSHA256, key, isVerificationError},
+		{"truncated mac", truncatedMAC, envelope.HMACSHA256, key, isVerificationError},
+		{"wrong key", signed, envelope.HMACSHA256, []byte("other key"), isVerificationError},
+		{"downgrade", checksummed, envelope.HMACSHA256, key, func(t *testing.T, err error) {
+			var e *envelope.AlgorithmError
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, envelope.CRC32C, e.Got)
+			assert.Equal(t, envelope.HMACSHA256, e.Want)
+		}},
+		{"no key", signed, envelope.HMACSHA256, nil, func(t *testing.T, err error) {
+			assert.ErrorIs(t, err, envelope.ErrNoKey)
+		}},
+		{
+			"version",
+			seal(t, map[string]interface{}{"v": 2, "alg": "crc32c"}),
+			envelope.CRC32C, nil,
+			func(t *testing.T, err error) {
+				var e *envelope.VersionError
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, 2, e.Version)
+			},
+		},
+		{
+			"unknown key",
+			seal(t, map[string]interface{}{"v": 1, "alg": "crc32c", "extra": 1}),
+			envelope.CRC32C, nil,
+			isMalformedError,
+		},
+		{"not an envelope", []byte{0x20, 0x01}, envelope.CRC32C, nil, isMalformedError},
+		{"truncated", signed[:len(signed)-1], envelope.HMACSHA256, key, isMalformedError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := message{ID: 7}
+
+			err := envelope.Open(test.data, &m, test.alg, test.key)
+
+			test.check(t, err)
+			assert.Equal(t, message{ID: 7}, m)
+		})
+	}
+}
+
+func isVerificationError(t *testing.T, err error) {
+	t.Helper()
+
+	var e *envelope.VerificationError
+	assert.ErrorAs(t, err, &e)
+}
+
+func isMalformedError(t *testing.T, err error) {
+	t.Helper()
+
+	var e *envelope.MalformedError
+	assert.ErrorAs(t, err, &e)
+}
+
+func TestSealUnknownAlgorithm(t *testing.T) {
+	_, err := envelope.Seal(message{}, "md5", nil)
+
+	var e *envelope.AlgorithmError
+	require.ErrorAs(t, err, &e)
+	assert.EqualError(t, err, `envelope: unknown algorithm "md5"`)
+}
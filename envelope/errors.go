@@ -0,0 +1,56 @@
+package envelope
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoKey is returned for HMAC-SHA256 envelopes without a key.
+var ErrNoKey = errors.New("envelope: no HMAC key")
+
+// A MalformedError describes data that isn't an envelope.
+type MalformedError struct {
+	Err error
+}
+
+func (e *MalformedError) Error() string {
+	return "envelope: malformed envelope: " + e.Err.Error()
+}
+
+func (e *MalformedError) Unwrap() error {
+	return e.Err
+}
+
+// A VersionError describes an envelope of an unsupported version.
+type VersionError struct {
+	Version int
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("envelope: unsupported version %d", e.Version)
+}
+
+// An AlgorithmError describes an envelope of an unexpected
+// or unknown algorithm.
+type AlgorithmError struct {
+	Got  Algorithm
+	Want Algorithm
+}
+
+func (e *AlgorithmError) Error() string {
+	if e.Want == "" {
+		return fmt.Sprintf("envelope: unknown algorithm %q", e.Got)
+	}
+
+	return fmt.Sprintf("envelope: algorithm %q, expected %q", e.Got, e.Want)
+}
+
+// A VerificationError describes an envelope whose mac doesn't match
+// its body: a corrupted envelope, or a signature made with another key.
+type VerificationError struct {
+	Algorithm Algorithm
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("envelope: %s verification failed", e.Algorithm)
+}
@@ -0,0 +1,97 @@
+//go:build go1.18
+// +build go1.18
+
+package binngo_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/et-nik/binngo"
+)
+
+// FuzzRoundTrip checks that the values decoded from any input survive
+// a round trip: the value decoded from Marshal of a decoded value encodes
+// and decodes back to itself. The values are compared in the %#v format,
+// which sorts the map keys and compares NaN floats equal.
+func FuzzRoundTrip(f *testing.F) {
+	seeds(f)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var v interface{}
+		if err := binngo.Unmarshal(b, &v); err != nil || v == nil {
+			return
+		}
+
+		first := roundTrip(t, v)
+		second := roundTrip(t, first)
+
+		if fmt.Sprintf("%#v", first) != fmt.Sprintf("%#v", second) {
+			t.Fatalf("round trip mismatch:\n%#v\n%#v", first, second)
+		}
+	})
+}
+
+func roundTrip(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+
+	b, err := binngo.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal(%#v): %v", v, err)
+	}
+
+	var decoded interface{}
+	if err := binngo.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal(% x): %v", b, err)
+	}
+
+	return decoded
+}
+
+// FuzzCanonicalize checks that the normal form of a document equals
+// the document and is its own normal form.
+func FuzzCanonicalize(f *testing.F) {
+	seeds(f)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		for _, opts := range []*binngo.CanonicalOptions{nil, {MinimalInts: true}} {
+			c, err := binngo.Canonicalize(b, opts)
+			if err != nil {
+				return
+			}
+
+			diffs, err := binngo.Diff(b, c, &binngo.EqualOptions{IgnoreIntWidth: opts != nil})
+			if err != nil || len(diffs) > 0 {
+				t.Fatalf("Diff(% x, % x) = %v, %v", b, c, diffs, err)
+			}
+
+			again, err := binngo.Canonicalize(c, opts)
+			if err != nil || !bytes.Equal(c, again) {
+				t.Fatalf("Canonicalize(% x) = % x, %v", c, again, err)
+			}
+		}
+	})
+}
+
+// seeds adds the binary files of the tests to the corpus.
+func seeds(f *testing.F) {
+	f.Helper()
+
+	for _, pattern := range []string{"test/binary/*.bin", "test/golden/testdata/*.bin"} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(b)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+//go:build go1.18
+// +build go1.18
+
+package binngo
+
+import (
+	"database/sql/driver"
+	"io"
+
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+)
+
+// MarshalAs returns the BINN encoding of v.
+func MarshalAs[T any](v T) ([]byte, error) {
+	return encode.MarshalAs(v)
+}
+
+// UnmarshalTo parses the BINN-encoded data into a new value of the type T.
+func UnmarshalTo[T any](data []byte) (T, error) {
+	return decode.UnmarshalTo[T](data)
+}
+
+// UnmarshalList parses a BINN list into a slice of T.
+func UnmarshalList[T any](data []byte) ([]T, error) {
+	return decode.UnmarshalList[T](data)
+}
+
+// UnmarshalMap parses a BINN object or map into a map[K]V.
+func UnmarshalMap[K comparable, V any](data []byte) (map[K]V, error) {
+	return decode.UnmarshalMap[K, V](data)
+}
+
+// NewTypedDecoder returns a decoder of values of the type T that reads
+// from r. Its Next method returns io.EOF at the end of the stream.
+func NewTypedDecoder[T any](r io.Reader) *decode.TypedDecoder[T] {
+	return decode.NewTypedDecoder[T](r)
+}
+
+// ColumnOf stores a value of the type T as BINN in a database column.
+// It's the typed counterpart of Column.
+type ColumnOf[T any] struct {
+	V T
+}
+
+// Value returns the BINN encoding of V, or nil, the SQL NULL,
+// if V is a nil pointer or interface.
+func (c ColumnOf[T]) Value() (driver.Value, error) {
+	return Column{c.V}.Value()
+}
+
+// Scan decodes the BINN encoding of a []byte or a string into V.
+// A NULL sets V to its zero value.
+func (c *ColumnOf[T]) Scan(src interface{}) error {
+	if src == nil {
+		var zero T
+		c.V = zero
+
+		return nil
+	}
+
+	data, err := columnBytes(src)
+	if err != nil {
+		return err
+	}
+
+	return decode.Unmarshal(data, &c.V)
+}
@@ -0,0 +1,30 @@
+//go:build go1.18
+// +build go1.18
+
+package binngo_test
+
+import (
+	"testing"
+
+	"github.com/et-nik/binngo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnOf(t *testing.T) {
+	db := openFakeDB(t)
+	s := settings{"dark", 12, []string{"a", "b"}}
+
+	_, err := db.Exec("INSERT", binngo.ColumnOf[settings]{V: s})
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT", binngo.ColumnOf[*settings]{})
+	require.NoError(t, err)
+
+	rows := scanAll(t, db, "SELECT TEXT", func() interface{} {
+		return &binngo.ColumnOf[settings]{V: settings{Theme: "light"}}
+	})
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, s, rows[0].(*binngo.ColumnOf[settings]).V)
+	assert.Equal(t, settings{}, rows[1].(*binngo.ColumnOf[settings]).V)
+}
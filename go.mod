@@ -2,7 +2,4 @@ module github.com/et-nik/binngo
 
 go 1.16
 
-require (
-	github.com/cstockton/go-conv v1.0.0
-	github.com/stretchr/testify v1.7.0
-)
+require github.com/stretchr/testify v1.7.0
@@ -0,0 +1,42 @@
+// Package itempath formats the paths to the items of BINN documents,
+// such as .items[2].name for the key "name" of the third item of the
+// list under the key "items". Map keys are written as [12].
+package itempath
+
+import (
+	"strconv"
+
+	"github.com/et-nik/binngo/binn"
+)
+
+// Key returns the path segment of the key of an object or a map.
+// The map keys are given in decimal.
+func Key(t binn.Type, key string) string {
+	switch {
+	case t == binn.MapType:
+		return "[" + key + "]"
+	case isIdentifier(key):
+		return "." + key
+	}
+
+	return "[" + strconv.Quote(key) + "]"
+}
+
+// Index returns the path segment of the i-th item of a list.
+func Index(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
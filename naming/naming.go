@@ -0,0 +1,79 @@
+// Package naming implements the strategies deriving the object keys
+// of the untagged struct fields from the Go field names.
+//
+// The strategies are set with encode.Encoder.SetNaming and
+// decode.Decoder.SetNaming. The encoders and decoders cache the keys
+// of every struct type per strategy, so custom strategies should be
+// created once and reused.
+package naming
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A Strategy maps the Go field names to object keys.
+type Strategy struct {
+	fn func(string) string
+}
+
+var (
+	// SnakeCase maps UserID to user_id and HTTPServer to http_server.
+	SnakeCase = New(snakeCase)
+	// CamelCase maps UserID to userID and HTTPServer to httpServer.
+	CamelCase = New(camelCase)
+	// LowerCase maps UserID to userid.
+	LowerCase = New(strings.ToLower)
+)
+
+// New returns a strategy mapping the field names with fn.
+func New(fn func(name string) string) *Strategy {
+	return &Strategy{fn}
+}
+
+// Key returns the object key of the field name.
+func (s *Strategy) Key(name string) string {
+	return s.fn(name)
+}
+
+// snakeCase lowers the name and separates its words with underscores.
+// A word starts at an upper case letter following a lower case letter
+// or a digit, and at the last letter of an upper case run followed by
+// a lower case letter.
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	b.Grow(len(name) + 4)
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// camelCase lowers the leading upper case run of the name, except for
+// the letter starting the next word.
+func camelCase(name string) string {
+	runes := []rune(name)
+
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+
+		runes[i] = unicode.ToLower(runes[i])
+	}
+
+	return string(runes)
+}
@@ -0,0 +1,40 @@
+package naming_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/et-nik/binngo/naming"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrategies(t *testing.T) {
+	tests := []struct {
+		name  string
+		snake string
+		camel string
+		lower string
+	}{
+		{"Name", "name", "name", "name"},
+		{"UserID", "user_id", "userID", "userid"},
+		{"ID", "id", "id", "id"},
+		{"HTTPServer", "http_server", "httpServer", "httpserver"},
+		{"Version2Beta", "version2_beta", "version2Beta", "version2beta"},
+		{"Already_Snake", "already_snake", "already_Snake", "already_snake"},
+		{"X", "x", "x", "x"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.snake, naming.SnakeCase.Key(test.name))
+			assert.Equal(t, test.camel, naming.CamelCase.Key(test.name))
+			assert.Equal(t, test.lower, naming.LowerCase.Key(test.name))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	s := naming.New(strings.ToUpper)
+
+	assert.Equal(t, "USERID", s.Key("UserID"))
+}
@@ -0,0 +1,191 @@
+// Package rpc implements a BINN ClientCodec and ServerCodec for the
+// net/rpc package.
+//
+// Each request and response is a header followed by a body, both
+// BINN items written back to back. The headers are objects:
+//
+//	request   {"method": "Service.Method", "seq": 1}
+//	response  {"method": "Service.Method", "seq": 1, "error": ""}
+//
+// The bodies are the arguments and the replies encoded with Marshal.
+// The response body of a failed call is an empty object.
+package rpc
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/rpc"
+
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+)
+
+type requestHeader struct {
+	ServiceMethod string `binn:"method"`
+	Seq           uint64 `binn:"seq"`
+}
+
+type responseHeader struct {
+	ServiceMethod string `binn:"method"`
+	Seq           uint64 `binn:"seq"`
+	Error         string `binn:"error"`
+}
+
+// discard skips a body that isn't read into a value.
+type discard struct{}
+
+func (discard) UnmarshalBINN([]byte) error {
+	return nil
+}
+
+// codec reads the items of a connection through a buffer
+// and writes them through another one.
+type codec struct {
+	rwc    io.ReadWriteCloser
+	dec    *decode.Decoder
+	enc    *encode.Encoder
+	encBuf *bufio.Writer
+	closed bool
+}
+
+func newCodec(rwc io.ReadWriteCloser) codec {
+	buf := bufio.NewWriter(rwc)
+
+	return codec{
+		rwc:    rwc,
+		dec:    decode.NewDecoder(bufio.NewReader(rwc)),
+		enc:    encode.NewEncoder(buf),
+		encBuf: buf,
+	}
+}
+
+// write writes a header and a body and flushes them.
+func (c *codec) write(header, body interface{}) error {
+	if err := c.enc.Encode(header); err != nil {
+		return err
+	}
+	if err := c.enc.Encode(body); err != nil {
+		return err
+	}
+
+	return c.encBuf.Flush()
+}
+
+// readBody decodes a body into v, or skips it if v is nil.
+func (c *codec) readBody(v interface{}) error {
+	if v == nil {
+		v = &discard{}
+	}
+
+	return c.dec.Decode(v)
+}
+
+func (c *codec) Close() error {
+	if c.closed {
+		// Only call c.rwc.Close once; otherwise the semantics are undefined.
+		return nil
+	}
+	c.closed = true
+
+	return c.rwc.Close()
+}
+
+type serverCodec struct {
+	codec
+}
+
+// NewServerCodec returns a new rpc.ServerCodec using BINN on conn.
+func NewServerCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
+	return &serverCodec{newCodec(conn)}
+}
+
+func (c *serverCodec) ReadRequestHeader(r *rpc.Request) error {
+	var h requestHeader
+	if err := c.dec.Decode(&h); err != nil {
+		return err
+	}
+
+	r.ServiceMethod = h.ServiceMethod
+	r.Seq = h.Seq
+
+	return nil
+}
+
+func (c *serverCodec) ReadRequestBody(body interface{}) error {
+	return c.readBody(body)
+}
+
+// WriteResponse writes the response header and the body. A body that
+// can't be encoded fails the call with the encoding error instead.
+func (c *serverCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	h := responseHeader{r.ServiceMethod, r.Seq, r.Error}
+
+	b, err := encode.Marshal(body)
+	if err != nil {
+		h.Error = "rpc: binn error encoding body: " + err.Error()
+		b, _ = encode.Marshal(struct{}{})
+	}
+
+	if err := c.enc.Encode(&h); err != nil {
+		return err
+	}
+	if _, err := c.encBuf.Write(b); err != nil {
+		return err
+	}
+
+	return c.encBuf.Flush()
+}
+
+type clientCodec struct {
+	codec
+}
+
+// NewClientCodec returns a new rpc.ClientCodec using BINN on conn.
+func NewClientCodec(conn io.ReadWriteCloser) rpc.ClientCodec {
+	return &clientCodec{newCodec(conn)}
+}
+
+func (c *clientCodec) WriteRequest(r *rpc.Request, body interface{}) error {
+	return c.write(&requestHeader{r.ServiceMethod, r.Seq}, body)
+}
+
+func (c *clientCodec) ReadResponseHeader(r *rpc.Response) error {
+	var h responseHeader
+	if err := c.dec.Decode(&h); err != nil {
+		return err
+	}
+
+	r.ServiceMethod = h.ServiceMethod
+	r.Seq = h.Seq
+	r.Error = h.Error
+
+	return nil
+}
+
+func (c *clientCodec) ReadResponseBody(body interface{}) error {
+	return c.readBody(body)
+}
+
+// ServeConn runs the BINN server on a single connection.
+// ServeConn blocks, serving the connection until the client hangs up.
+// The caller typically invokes ServeConn in a go statement.
+func ServeConn(conn io.ReadWriteCloser) {
+	rpc.ServeCodec(NewServerCodec(conn))
+}
+
+// NewClient returns a new rpc.Client to handle requests to the
+// set of services at the other end of the connection.
+func NewClient(conn io.ReadWriteCloser) *rpc.Client {
+	return rpc.NewClientWithCodec(NewClientCodec(conn))
+}
+
+// Dial connects to a BINN RPC server at the specified network address.
+func Dial(network, address string) (*rpc.Client, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(conn), nil
+}
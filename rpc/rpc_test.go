@@ -0,0 +1,148 @@
+package rpc_test
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"testing"
+
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+	binnrpc "github.com/et-nik/binngo/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Args struct {
+	A int `binn:"a"`
+	B int `binn:"b"`
+}
+
+type Reply struct {
+	C int `binn:"c"`
+}
+
+type Arith int
+
+func (*Arith) Add(args *Args, reply *Reply) error {
+	reply.C = args.A + args.B
+	return nil
+}
+
+func (*Arith) Div(args *Args, reply *Reply) error {
+	if args.B == 0 {
+		return errors.New("divide by zero")
+	}
+	reply.C = args.A / args.B
+	return nil
+}
+
+func (*Arith) Chan(_ *Args, reply *chan int) error {
+	*reply = make(chan int)
+	return nil
+}
+
+func newClient(t *testing.T) *rpc.Client {
+	t.Helper()
+
+	server := rpc.NewServer()
+	require.NoError(t, server.Register(new(Arith)))
+
+	cli, srv := net.Pipe()
+	go server.ServeCodec(binnrpc.NewServerCodec(srv))
+
+	client := binnrpc.NewClient(cli)
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestCall(t *testing.T) {
+	client := newClient(t)
+
+	var reply Reply
+	err := client.Call("Arith.Add", &Args{7, 8}, &reply)
+
+	require.NoError(t, err)
+	assert.Equal(t, 15, reply.C)
+}
+
+func TestCallError(t *testing.T) {
+	client := newClient(t)
+
+	var reply Reply
+	err := client.Call("Arith.Div", &Args{7, 0}, &reply)
+
+	assert.EqualError(t, err, "divide by zero")
+
+	err = client.Call("Arith.Mul", &Args{7, 8}, &reply)
+
+	assert.EqualError(t, err, "rpc: can't find method Arith.Mul")
+
+	// The connection is still usable after the errors.
+	err = client.Call("Arith.Div", &Args{8, 2}, &reply)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, reply.C)
+}
+
+func TestCallReplyEncodingError(t *testing.T) {
+	client := newClient(t)
+
+	var reply chan int
+	err := client.Call("Arith.Chan", &Args{}, &reply)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "rpc: binn error encoding body")
+}
+
+func TestConcurrentCalls(t *testing.T) {
+	client := newClient(t)
+
+	calls := make([]*rpc.Call, 100)
+	for i := range calls {
+		calls[i] = client.Go("Arith.Add", &Args{i, i}, &Reply{}, nil)
+	}
+
+	for i, call := range calls {
+		<-call.Done
+		require.NoError(t, call.Error)
+		assert.Equal(t, 2*i, call.Reply.(*Reply).C)
+	}
+}
+
+func TestWireFormat(t *testing.T) {
+	cli, srv := net.Pipe()
+	defer srv.Close()
+	codec := binnrpc.NewClientCodec(cli)
+	defer codec.Close()
+
+	go func() {
+		_ = codec.WriteRequest(&rpc.Request{ServiceMethod: "Arith.Add", Seq: 3}, &Args{1, 2})
+	}()
+
+	dec := decode.NewDecoder(srv)
+	var header, body map[string]interface{}
+	require.NoError(t, dec.Decode(&header))
+	require.NoError(t, dec.Decode(&body))
+
+	assert.Equal(t, map[string]interface{}{"method": "Arith.Add", "seq": uint8(3)}, header)
+	assert.Equal(t, map[string]interface{}{"a": uint8(1), "b": uint8(2)}, body)
+
+	// A response written by another implementation.
+	go func() {
+		enc := encode.NewEncoder(srv)
+		_ = enc.Encode(map[string]interface{}{"method": "Arith.Add", "seq": 3, "error": ""})
+		_ = enc.Encode(map[string]interface{}{"c": 3})
+	}()
+
+	var (
+		resp  rpc.Response
+		reply Reply
+	)
+	require.NoError(t, codec.ReadResponseHeader(&resp))
+	require.NoError(t, codec.ReadResponseBody(&reply))
+
+	assert.Equal(t, rpc.Response{ServiceMethod: "Arith.Add", Seq: 3}, resp)
+	assert.Equal(t, 3, reply.C)
+}
@@ -0,0 +1,214 @@
+package schema
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/encode"
+)
+
+var (
+	signedTypes = []binn.Type{
+		binn.Uint8Type, binn.Int8Type, binn.Uint16Type, binn.Int16Type,
+		binn.Uint32Type, binn.Int32Type, binn.Uint64Type, binn.Int64Type,
+	}
+	unsignedTypes = []binn.Type{binn.Uint8Type, binn.Uint16Type, binn.Uint32Type, binn.Uint64Type}
+)
+
+var (
+	marshalerType       = reflect.TypeOf((*encode.Marshaler)(nil)).Elem()
+	appenderType        = reflect.TypeOf((*encode.Appender)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Derive returns the schema of the encoding of the values of the type
+// of v by Marshal. Structs are closed objects requiring every field,
+// with the keys from the binn tags as for Marshal, or closed maps if
+// their fields are tagged with map keys. Integers are
+// limited to the range of their Go types, and nil pointers, slices,
+// maps and interfaces are allowed to be null. The types implementing
+// encode.Marshaler may be encoded as any item.
+//
+// The schemas of recursive types refer to themselves.
+func Derive(v interface{}) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("schema: %w", encode.ErrInvalidValue)
+	}
+
+	d := deriver{schemas: make(map[reflect.Type]*Schema)}
+
+	s, err := d.derive(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pointer schemas copy the schemas of their elements,
+	// which may have been incomplete when the copy was made.
+	for _, pt := range d.ptrs {
+		nullable(d.schemas[pt], d.schemas[pt.Elem()])
+	}
+
+	return s, nil
+}
+
+type deriver struct {
+	schemas map[reflect.Type]*Schema
+	// ptrs are the pointer types in the order their schemas
+	// were completed.
+	ptrs []reflect.Type
+}
+
+func (d *deriver) derive(t reflect.Type) (*Schema, error) {
+	if s, ok := d.schemas[t]; ok {
+		return s, nil
+	}
+
+	s := &Schema{}
+	d.schemas[t] = s
+
+	return s, d.fill(s, t)
+}
+
+//nolint:funlen
+func (d *deriver) fill(s *Schema, t reflect.Type) error {
+	pt := reflect.PtrTo(t)
+
+	switch {
+	case t.Implements(appenderType), t.Implements(marshalerType),
+		pt.Implements(appenderType), pt.Implements(marshalerType):
+		return nil
+	case t.Implements(binaryMarshalerType), pt.Implements(binaryMarshalerType):
+		s.Types = []binn.Type{binn.BlobType}
+		return nil
+	case t.Implements(textMarshalerType), pt.Implements(textMarshalerType):
+		s.Types = []binn.Type{binn.StringType}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		s.Types = []binn.Type{binn.True, binn.False}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		shift := uint(64 - t.Bits())
+		s.Types = append([]binn.Type(nil), signedTypes...)
+		s.Range = &Range{Min: math.MinInt64 >> shift, Max: math.MaxInt64 >> shift}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s.Types = append([]binn.Type(nil), unsignedTypes...)
+		if t.Bits() < 64 {
+			s.Range = &Range{Min: 0, Max: 1<<uint(t.Bits()) - 1}
+		}
+	case reflect.Float32:
+		s.Types = []binn.Type{binn.Float32Type}
+	case reflect.Float64:
+		s.Types = []binn.Type{binn.Float64Type}
+	case reflect.String:
+		s.Types = []binn.Type{binn.StringType}
+	case reflect.Interface:
+	case reflect.Ptr:
+		elem, err := d.derive(t.Elem())
+		if err != nil {
+			return err
+		}
+		nullable(s, elem)
+		d.ptrs = append(d.ptrs, t)
+	case reflect.Slice, reflect.Array:
+		s.Types = []binn.Type{binn.ListType}
+		if t.Kind() == reflect.Slice {
+			s.Types = append(s.Types, binn.Null)
+		}
+
+		items, err := d.derive(t.Elem())
+		if err != nil {
+			return err
+		}
+		s.Items = items
+	case reflect.Map:
+		return d.fillMap(s, t)
+	case reflect.Struct:
+		return d.fillStruct(s, t)
+	default:
+		return &encode.UnsupportedTypeError{Type: t}
+	}
+
+	return nil
+}
+
+func (d *deriver) fillMap(s *Schema, t reflect.Type) error {
+	switch t.Key().Kind() {
+	case reflect.String:
+		s.Types = []binn.Type{binn.ObjectType, binn.Null}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s.Types = []binn.Type{binn.MapType, binn.Null}
+	default:
+		if !t.Key().Implements(textMarshalerType) {
+			return &encode.UnsupportedTypeError{Type: t}
+		}
+		s.Types = []binn.Type{binn.ObjectType, binn.Null}
+	}
+
+	items, err := d.derive(t.Elem())
+	if err != nil {
+		return err
+	}
+	s.Items = items
+
+	return nil
+}
+
+func (d *deriver) fillStruct(s *Schema, t reflect.Type) error {
+	// The keys are set before deriving the fields, for the copies
+	// made by the pointers to t among them.
+	s.Types = []binn.Type{binn.ObjectType}
+	s.Closed = true
+	s.Keys = make(map[string]*Schema, t.NumField())
+	s.Required = make([]string, t.NumField())
+
+	for i := range s.Required {
+		f := t.Field(i)
+
+		mapKey, isMapKey, err := encode.FieldMapKey(f)
+		if err != nil {
+			return err
+		}
+
+		if i == 0 && isMapKey {
+			s.Types = []binn.Type{binn.MapType}
+		}
+
+		switch {
+		case isMapKey != (s.Types[0] == binn.MapType):
+			return fmt.Errorf("schema: field %s: %w", f.Name, encode.ErrMixedKeys)
+		case isMapKey:
+			s.Required[i] = strconv.Itoa(int(mapKey))
+		case len(encode.FieldKey(f)) > binn.MaxKeySize:
+			return fmt.Errorf("schema: field %s: %w", f.Name, encode.ErrKeyTooLong)
+		default:
+			s.Required[i] = encode.FieldKey(f)
+		}
+	}
+
+	for i, key := range s.Required {
+		fs, err := d.derive(t.Field(i).Type)
+		if err != nil {
+			return err
+		}
+		s.Keys[key] = fs
+	}
+
+	return nil
+}
+
+// nullable sets s to a copy of elem that allows null items.
+func nullable(s, elem *Schema) {
+	*s = *elem
+	if !elem.allows(binn.Null) {
+		s.Types = append(elem.Types[:len(elem.Types):len(elem.Types)], binn.Null)
+	}
+}
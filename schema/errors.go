@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A Violation is a constraint of a schema the document doesn't meet.
+type Violation struct {
+	// Path is the path to the item, empty for the top level item.
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+
+	return v.Path + ": " + v.Message
+}
+
+// A ValidationError lists the violations found by Validate,
+// in the order of the items in the document.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 1 {
+		return "schema: " + e.Violations[0].String()
+	}
+
+	var b strings.Builder
+
+	b.WriteString("schema: " + strconv.Itoa(len(e.Violations)) + " violations:")
+	for _, v := range e.Violations {
+		b.WriteString("\n\t" + v.String())
+	}
+
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+)
+
+// item is an encoded item.
+type item struct {
+	typ binn.Type
+	// value is the value of the types other than containers: the bytes
+	// of numbers, strings without the null terminator and blobs.
+	value []byte
+	// count is the items count of containers, and items are the bytes
+	// of the items of lists, maps and objects.
+	count int
+	items []byte
+}
+
+// readItem reads the item at the start of b, and returns it with
+// the bytes after it.
+func readItem(b []byte) (item, []byte, error) {
+	raw, rest, err := decode.ReadItemBytes(b)
+	if err != nil {
+		return item{}, nil, err
+	}
+
+	it := item{}
+	it.typ, _ = decode.ReadTypeBytes(raw)
+
+	if !it.typ.IsContainer() {
+		_, it.value, _, err = decode.ReadValueBytes(raw)
+
+		return it, rest, err
+	}
+
+	// ReadItemBytes clips the containers running past the end of b.
+	header := raw[1:]
+	if it.typ > 0xFF {
+		header = raw[2:]
+	}
+
+	size, header, err := decode.ReadSizeBytes(header)
+	if err != nil {
+		return it, nil, err
+	}
+
+	if size != len(raw) {
+		return it, nil, fmt.Errorf("%w: truncated container", decode.ErrInvalidItem)
+	}
+
+	switch it.typ {
+	case binn.ListType:
+		it.count, it.items, _, err = decode.ReadListBytes(raw)
+	case binn.MapType:
+		it.count, it.items, _, err = decode.ReadMapBytes(raw)
+	case binn.ObjectType:
+		it.count, it.items, _, err = decode.ReadObjectBytes(raw)
+	default:
+		it.count, _, err = decode.ReadSizeBytes(header)
+	}
+
+	return it, rest, err
+}
+
+// readKey reads the key of an object or a map item at the start of b,
+// and returns it with the bytes of the value. Map keys are returned
+// in decimal.
+func readKey(b []byte, t binn.Type) (string, []byte, error) {
+	if t == binn.MapType {
+		k, rest, err := decode.ReadMapKeyBytes(b)
+
+		return strconv.Itoa(int(k)), rest, err
+	}
+
+	k, rest, err := decode.ReadObjectKeyBytes(b)
+
+	return string(k), rest, err
+}
+
+// isContainer reports whether the items of the container type
+// are known to the format.
+func isContainer(t binn.Type) bool {
+	return t == binn.ListType || t == binn.MapType || t == binn.ObjectType
+}
+
+func (it *item) int() int64 {
+	switch len(it.value) {
+	case 1:
+		return int64(int8(it.value[0]))
+	case 2:
+		return int64(int16(binary.BigEndian.Uint16(it.value)))
+	case 4:
+		return int64(int32(binary.BigEndian.Uint32(it.value)))
+	}
+
+	return int64(binary.BigEndian.Uint64(it.value))
+}
+
+func (it *item) uint() uint64 {
+	switch len(it.value) {
+	case 1:
+		return uint64(it.value[0])
+	case 2:
+		return uint64(binary.BigEndian.Uint16(it.value))
+	case 4:
+		return uint64(binary.BigEndian.Uint32(it.value))
+	}
+
+	return binary.BigEndian.Uint64(it.value)
+}
+
+// format returns the integer value of the item in decimal.
+func (it *item) format() string {
+	if it.typ&1 == 0 {
+		return strconv.FormatUint(it.uint(), 10)
+	}
+
+	return strconv.FormatInt(it.int(), 10)
+}
+
+// malformed returns the error of the malformed item at the offset off,
+// wrapping decode.ErrInvalidItem.
+func malformed(err error, off int) error {
+	if errors.Is(err, decode.ErrInvalidItem) {
+		return fmt.Errorf("schema: %w at offset %d", err, off)
+	}
+
+	return fmt.Errorf("schema: %w: %v at offset %d", decode.ErrInvalidItem, err, off)
+}
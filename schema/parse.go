@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/internal/itempath"
+)
+
+// document is the form of a schema in BINN and JSON files. The types
+// are written as their names, such as "uint8", or hexadecimal values.
+//
+//	{
+//		"types": ["object"],
+//		"keys": {
+//			"id": {"types": ["uint8", "uint16"], "range": {"min": 1, "max": 1000}},
+//			"tags": {"types": ["list"], "maxLen": 8, "items": {"types": ["string"]}}
+//		},
+//		"required": ["id"],
+//		"closed": true
+//	}
+type document struct {
+	Types    []string             `json:"types" binn:"types"`
+	Keys     map[string]*document `json:"keys" binn:"keys"`
+	Required []string             `json:"required" binn:"required"`
+	Closed   bool                 `json:"closed" binn:"closed"`
+	Items    *document            `json:"items" binn:"items"`
+	MinLen   int                  `json:"minLen" binn:"minLen"`
+	MaxLen   int                  `json:"maxLen" binn:"maxLen"`
+	Range    *Range               `json:"range" binn:"range"`
+}
+
+// Parse parses a schema from a BINN object. The keys of the object
+// are the lower camel case names of the fields of Schema, and the
+// types are written as their names, such as "uint8".
+func Parse(data []byte) (*Schema, error) {
+	var doc document
+	if err := decode.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+
+	return doc.schema("")
+}
+
+// ParseJSON parses a schema from a JSON object of the form
+// accepted by Parse.
+func ParseJSON(data []byte) (*Schema, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var doc document
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+
+	return doc.schema("")
+}
+
+func (doc *document) schema(path string) (*Schema, error) {
+	if doc == nil {
+		return nil, nil
+	}
+
+	s := &Schema{
+		Required: doc.Required,
+		Closed:   doc.Closed,
+		MinLen:   doc.MinLen,
+		MaxLen:   doc.MaxLen,
+		Range:    doc.Range,
+	}
+
+	for _, name := range doc.Types {
+		t, err := binn.ParseType(name)
+		if err != nil {
+			return nil, fmt.Errorf("schema: %s.types: %w", path, err)
+		}
+		s.Types = append(s.Types, t)
+	}
+
+	if doc.Keys != nil {
+		s.Keys = make(map[string]*Schema, len(doc.Keys))
+	}
+
+	for key, kd := range doc.Keys {
+		ks, err := kd.schema(path + ".keys" + itempath.Key(binn.ObjectType, key))
+		if err != nil {
+			return nil, err
+		}
+		s.Keys[key] = ks
+	}
+
+	items, err := doc.Items.schema(path + ".items")
+	if err != nil {
+		return nil, err
+	}
+	s.Items = items
+
+	return s, nil
+}
@@ -0,0 +1,276 @@
+// Package schema describes the expected shapes of BINN documents and
+// validates encoded documents against them.
+//
+// A Schema constrains the type of an item, the keys of objects and maps,
+// the items of lists, the lengths of strings, blobs and containers and
+// the ranges of integers. Schemas can be built in Go, loaded from BINN or
+// JSON with Parse and ParseJSON, or derived from Go types with Derive.
+//
+// Validate walks the encoded bytes without decoding them into Go values,
+// and reports every violation with the path to the item, such as
+// .items[2].name for the key "name" of the third item of the list under
+// the key "items". Map keys are written as [12].
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/internal/itempath"
+)
+
+// A Schema describes a BINN item. The zero Schema accepts any item,
+// and each field adds a constraint.
+type Schema struct {
+	// Types are the allowed types of the item. Any type is allowed
+	// if Types is empty.
+	Types []binn.Type
+
+	// Keys are the schemas of the object keys, or of the map keys
+	// written in decimal.
+	Keys map[string]*Schema
+	// Required are the keys the object or the map must have.
+	Required []string
+	// Closed rejects the object and map keys missing from Keys.
+	Closed bool
+
+	// Items is the schema of the list items, and of the values
+	// of the object and map keys missing from Keys.
+	Items *Schema
+
+	// MinLen and MaxLen bound the length of strings and blobs in
+	// bytes, and of containers in items. MaxLen is ignored if zero.
+	MinLen int
+	MaxLen int
+
+	// Range bounds the values of integers.
+	Range *Range
+}
+
+// Range is an inclusive range of integers.
+type Range struct {
+	Min int64 `json:"min" binn:"min"`
+	Max int64 `json:"max" binn:"max"`
+}
+
+// Validate checks the item in data against the schema. It returns
+// a *ValidationError listing the violations, or an error wrapping
+// decode.ErrInvalidItem if data isn't a single well-formed item.
+// The items the schema doesn't describe are skipped without checking
+// their own items, and the items nested deeper than decode.MaxDepth
+// return decode.ErrTooDeep.
+func (s *Schema) Validate(data []byte) error {
+	v := validator{data: data}
+
+	rest, err := v.validate(s, data)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) > 0 {
+		return malformed(fmt.Errorf("%w: trailing data", decode.ErrInvalidItem), v.offset(rest))
+	}
+
+	if len(v.violations) > 0 {
+		return &ValidationError{v.violations}
+	}
+
+	return nil
+}
+
+type validator struct {
+	data       []byte
+	violations []Violation
+	// path holds the path segments of the item being validated,
+	// they are joined only for the violations.
+	path []string
+}
+
+func (v *validator) add(msg string) {
+	v.violations = append(v.violations, Violation{Path: strings.Join(v.path, ""), Message: msg})
+}
+
+// push appends the path segment of an item of the container
+// being validated.
+func (v *validator) push(segment string) {
+	v.path = append(v.path, segment)
+}
+
+func (v *validator) pop() {
+	v.path = v.path[:len(v.path)-1]
+}
+
+// offset returns the offset of b in the document. The decode readers
+// return the slices of the document, which end where the document does.
+func (v *validator) offset(b []byte) int {
+	return cap(v.data) - cap(b)
+}
+
+// validate checks the item at the start of b and returns the bytes
+// after it. A nil schema only checks that the item is complete,
+// without reading its items.
+func (v *validator) validate(s *Schema, b []byte) ([]byte, error) {
+	it, rest, err := readItem(b)
+	if err != nil {
+		return nil, malformed(err, v.offset(b))
+	}
+
+	if s != nil && !s.allows(it.typ) {
+		v.add("type " + it.typ.String() + ", expected " + typeList(s.Types))
+		s = nil
+	}
+
+	if s == nil || !isContainer(it.typ) {
+		if s != nil {
+			v.checkLen(s, &it)
+			v.checkRange(s, &it)
+		}
+
+		return rest, nil
+	}
+
+	if len(v.path) >= decode.MaxDepth {
+		return nil, decode.ErrTooDeep
+	}
+
+	v.checkLen(s, &it)
+
+	return rest, v.validateItems(s, &it)
+}
+
+func (s *Schema) allows(t binn.Type) bool {
+	if len(s.Types) == 0 {
+		return true
+	}
+
+	for _, allowed := range s.Types {
+		if t == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *validator) checkLen(s *Schema, it *item) {
+	var n int
+
+	switch it.typ.Storage() {
+	case binn.StorageString, binn.StorageBlob:
+		n = len(it.value)
+	case binn.StorageContainer:
+		n = it.count
+	default:
+		return
+	}
+
+	if n < s.MinLen {
+		v.add("length " + strconv.Itoa(n) + ", expected at least " + strconv.Itoa(s.MinLen))
+	}
+
+	if s.MaxLen > 0 && n > s.MaxLen {
+		v.add("length " + strconv.Itoa(n) + ", expected at most " + strconv.Itoa(s.MaxLen))
+	}
+}
+
+func (v *validator) checkRange(s *Schema, it *item) {
+	if s.Range == nil {
+		return
+	}
+
+	var in bool
+
+	switch it.typ {
+	case binn.Int8Type, binn.Int16Type, binn.Int32Type, binn.Int64Type:
+		i := it.int()
+		in = i >= s.Range.Min && i <= s.Range.Max
+	case binn.Uint8Type, binn.Uint16Type, binn.Uint32Type, binn.Uint64Type:
+		u := it.uint()
+		in = (s.Range.Min <= 0 || u >= uint64(s.Range.Min)) && s.Range.Max >= 0 && u <= uint64(s.Range.Max)
+	default:
+		return
+	}
+
+	if !in {
+		v.add("value " + it.format() + " out of range [" +
+			strconv.FormatInt(s.Range.Min, 10) + ", " + strconv.FormatInt(s.Range.Max, 10) + "]")
+	}
+}
+
+func (v *validator) validateItems(s *Schema, it *item) error {
+	var seen map[string]bool
+	if len(s.Required) > 0 {
+		seen = make(map[string]bool, it.count)
+	}
+
+	items := it.items
+
+	for i := 0; i < it.count; i++ {
+		var (
+			key string
+			err error
+		)
+
+		switch it.typ {
+		case binn.ObjectType, binn.MapType:
+			off := v.offset(items)
+			if key, items, err = readKey(items, it.typ); err != nil {
+				return malformed(err, off)
+			}
+			v.push(itempath.Key(it.typ, key))
+		default:
+			v.push(itempath.Index(i))
+		}
+
+		child := s.Items
+
+		if it.typ != binn.ListType {
+			if ks, ok := s.Keys[key]; ok {
+				child = ks
+			} else if s.Closed {
+				v.add("unexpected key")
+			}
+
+			if seen != nil {
+				seen[key] = true
+			}
+		}
+
+		if items, err = v.validate(child, items); err != nil {
+			return err
+		}
+		v.pop()
+	}
+
+	if len(items) > 0 {
+		return malformed(fmt.Errorf("%w: container size mismatch", decode.ErrInvalidItem), v.offset(items))
+	}
+
+	if seen != nil && it.typ != binn.ListType {
+		for _, key := range s.Required {
+			if !seen[key] {
+				v.push(itempath.Key(it.typ, key))
+				v.add("missing required key")
+				v.pop()
+			}
+		}
+	}
+
+	return nil
+}
+
+func typeList(types []binn.Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+
+	if len(names) == 1 {
+		return names[0]
+	}
+
+	return "one of " + strings.Join(names, ", ")
+}
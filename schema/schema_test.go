@@ -0,0 +1,390 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/et-nik/binngo/binn"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+	"github.com/et-nik/binngo/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	b, err := encode.Marshal(v)
+	require.NoError(t, err)
+
+	return b
+}
+
+var userSchema = &schema.Schema{
+	Types: []binn.Type{binn.ObjectType},
+	Keys: map[string]*schema.Schema{
+		"id": {
+			Types: []binn.Type{binn.Uint8Type, binn.Uint16Type},
+			Range: &schema.Range{Min: 1, Max: 1000},
+		},
+		"name": {Types: []binn.Type{binn.StringType}, MinLen: 1, MaxLen: 8},
+		"tags": {
+			Types:  []binn.Type{binn.ListType},
+			MaxLen: 2,
+			Items:  &schema.Schema{Types: []binn.Type{binn.StringType, binn.Null}},
+		},
+		"scores": {
+			Types: []binn.Type{binn.MapType},
+			Items: &schema.Schema{Range: &schema.Range{Min: -10, Max: 10}},
+		},
+	},
+	Required: []string{"id", "name"},
+	Closed:   true,
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		doc        interface{}
+		violations []schema.Violation
+	}{
+		{
+			"valid",
+			map[string]interface{}{
+				"id":     1000,
+				"name":   "gopher",
+				"tags":   []interface{}{"a", nil},
+				"scores": map[int]int{1: -10, 2: 10},
+			},
+			nil,
+		},
+		{
+			"top level type",
+			[]int{1},
+			[]schema.Violation{{"", "type list, expected object"}},
+		},
+		{
+			"types",
+			map[string]interface{}{"id": -1, "name": 1, "tags": []interface{}{true}},
+			[]schema.Violation{
+				{".id", "type int8, expected one of uint8, uint16"},
+				{".name", "type uint8, expected string"},
+				{".tags[0]", "type true, expected one of string, null"},
+			},
+		},
+		{
+			"missing and unexpected keys",
+			map[string]interface{}{"name": "gopher", "e-mail": "gopher@example.com"},
+			[]schema.Violation{
+				{`["e-mail"]`, "unexpected key"},
+				{".id", "missing required key"},
+			},
+		},
+		{
+			"bounds",
+			map[string]interface{}{
+				"id":     1001,
+				"name":   "",
+				"tags":   []string{"a", "b", "c"},
+				"scores": map[int]int{-7: 11},
+			},
+			[]schema.Violation{
+				{".id", "value 1001 out of range [1, 1000]"},
+				{".name", "length 0, expected at least 1"},
+				{".tags", "length 3, expected at most 2"},
+				{".scores[-7]", "value 11 out of range [-10, 10]"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := userSchema.Validate(marshal(t, test.doc))
+
+			if test.violations == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var verr *schema.ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.ElementsMatch(t, test.violations, verr.Violations)
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := userSchema.Validate(marshal(t, map[string]interface{}{"id": 0}))
+
+	assert.EqualError(t, err, "schema: 2 violations:\n"+
+		"\t.id: value 0 out of range [1, 1000]\n"+
+		"\t.name: missing required key")
+
+	err = userSchema.Validate(marshal(t, "gopher"))
+
+	assert.EqualError(t, err, "schema: type string, expected object")
+}
+
+func TestValidateMalformed(t *testing.T) {
+	valid := marshal(t, map[string]interface{}{"id": 1, "name": "gopher"})
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing data", append(valid[:len(valid):len(valid)], binn.Null)},
+		{"unterminated string", []byte{binn.StringType, 0x01, 'a', 'b'}},
+		{"container size mismatch", []byte{binn.ListType, 0x05, 0x01, binn.Null, binn.Null}},
+		{"truncated key", []byte{binn.MapType, 0x05, 0x01, 0x00, 0x00}},
+		{"truncated container", []byte{binn.ListType, 0x06, 0x01, binn.ListType, 0x05, 0x00}},
+		{"truncated user type", []byte{binn.ListType, 0x04, 0x01, 0xF0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := (&schema.Schema{}).Validate(test.data)
+
+			assert.ErrorIs(t, err, decode.ErrInvalidItem)
+		})
+	}
+}
+
+func TestValidateUserContainer(t *testing.T) {
+	data := []byte{binn.ListType, 0x09, 0x02, binn.Null, 0xF0, 0x01, 0x05, 0x01, 0xFF}
+
+	err := (&schema.Schema{Items: &schema.Schema{Types: []binn.Type{binn.Null, 0xF001}}}).Validate(data)
+
+	assert.NoError(t, err)
+}
+
+// nestedLists returns the encoding of n lists nested in each other.
+func nestedLists(n int) []byte {
+	sizes := make([]int, n)
+	for i := range sizes {
+		size := 3
+		if i > 0 {
+			size = sizes[i-1] + 3
+		}
+		if size > 127 {
+			size += 3
+		}
+		sizes[i] = size
+	}
+
+	b := make([]byte, 0, sizes[n-1])
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, binn.ListType)
+		if sizes[i] > 127 {
+			b = append(b, byte(sizes[i]>>24)|0x80, byte(sizes[i]>>16), byte(sizes[i]>>8), byte(sizes[i]))
+		} else {
+			b = append(b, byte(sizes[i]))
+		}
+		if i > 0 {
+			b = append(b, 1)
+		} else {
+			b = append(b, 0)
+		}
+	}
+
+	return b
+}
+
+func TestValidateMaxDepth(t *testing.T) {
+	deep := nestedLists(decode.MaxDepth + 1)
+
+	// The items below the described ones are skipped.
+	shallow := &schema.Schema{Types: []binn.Type{binn.ListType}, Items: &schema.Schema{MaxLen: 1}}
+	assert.NoError(t, shallow.Validate(deep))
+
+	recursive := &schema.Schema{Types: []binn.Type{binn.ListType}}
+	recursive.Items = recursive
+
+	assert.NoError(t, recursive.Validate(nestedLists(decode.MaxDepth)))
+	assert.ErrorIs(t, recursive.Validate(deep), decode.ErrTooDeep)
+}
+
+type node struct {
+	ID       int8              `binn:"id"`
+	Label    string            `binn:"label"`
+	Weight   *float64          `binn:"weight"`
+	Flags    []uint16          `binn:"flags"`
+	Attrs    map[string]string `binn:"attrs"`
+	Children []*node           `binn:"children"`
+	Parent   *node             `binn:"parent"`
+	Any      interface{}       `binn:"any"`
+	Untagged bool
+}
+
+func TestDerive(t *testing.T) {
+	s, err := schema.Derive(node{})
+	require.NoError(t, err)
+
+	weight := 0.5
+	valid := node{
+		ID:       -128,
+		Label:    "root",
+		Weight:   &weight,
+		Flags:    []uint16{65535},
+		Attrs:    map[string]string{"k": "v"},
+		Children: []*node{{ID: 127}, nil},
+		Any:      []interface{}{1, "a"},
+	}
+
+	assert.NoError(t, s.Validate(marshal(t, valid)))
+	assert.Equal(t, []string{"id", "label", "weight", "flags", "attrs", "children", "parent", "any", "Untagged"},
+		s.Required)
+	assert.Same(t, s.Keys["id"], s.Keys["children"].Items.Keys["parent"].Keys["id"])
+
+	invalid := map[string]interface{}{
+		"id":     300,
+		"label":  "root",
+		"weight": float32(1),
+		"flags":  []int{-1},
+		"attrs":  nil,
+		"children": []interface{}{
+			map[string]interface{}{"id": 1},
+		},
+		"parent":   nil,
+		"any":      nil,
+		"Untagged": false,
+		"extra":    1,
+	}
+
+	err = s.Validate(marshal(t, invalid))
+
+	var verr *schema.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.ElementsMatch(t, []string{
+		".id: value 300 out of range [-128, 127]",
+		".weight: type float32, expected one of float64, null",
+		".flags[0]: type int8, expected one of uint8, uint16, uint32, uint64",
+		".children[0].label: missing required key",
+		".children[0].weight: missing required key",
+		".children[0].flags: missing required key",
+		".children[0].attrs: missing required key",
+		".children[0].children: missing required key",
+		".children[0].parent: missing required key",
+		".children[0].any: missing required key",
+		".children[0].Untagged: missing required key",
+		".extra: unexpected key",
+	}, violationStrings(verr))
+}
+
+func violationStrings(err *schema.ValidationError) []string {
+	s := make([]string, len(err.Violations))
+	for i, v := range err.Violations {
+		s[i] = v.String()
+	}
+
+	return s
+}
+
+func TestDeriveMapKeys(t *testing.T) {
+	type record struct {
+		ID   uint8  `binn:"#1"`
+		Name string `binn:"#-2"`
+	}
+
+	s, err := schema.Derive(record{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []binn.Type{binn.MapType}, s.Types)
+	assert.Equal(t, []string{"1", "-2"}, s.Required)
+	assert.NoError(t, s.Validate(marshal(t, record{1, "a"})))
+
+	err = s.Validate(marshal(t, map[int]interface{}{1: 300, 3: "a"}))
+
+	var verr *schema.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.ElementsMatch(t, []string{
+		"[1]: value 300 out of range [0, 255]",
+		"[3]: unexpected key",
+		"[-2]: missing required key",
+	}, violationStrings(verr))
+
+	type mixed struct {
+		ID   int `binn:"#1"`
+		Name string
+	}
+
+	_, err = schema.Derive(mixed{})
+	assert.ErrorIs(t, err, encode.ErrMixedKeys)
+}
+
+func TestDeriveErrors(t *testing.T) {
+	_, err := schema.Derive(nil)
+	assert.ErrorIs(t, err, encode.ErrInvalidValue)
+
+	_, err = schema.Derive(struct{ C chan int }{})
+	var uerr *encode.UnsupportedTypeError
+	assert.ErrorAs(t, err, &uerr)
+}
+
+const userSchemaJSON = `{
+	"types": ["object"],
+	"keys": {
+		"id": {"types": ["uint8", "uint16"], "range": {"min": 1, "max": 1000}},
+		"name": {"types": ["string"], "minLen": 1, "maxLen": 8},
+		"tags": {"types": ["list"], "maxLen": 2, "items": {"types": ["string", "null"]}},
+		"scores": {"types": ["map"], "items": {"range": {"min": -10, "max": 10}}}
+	},
+	"required": ["id", "name"],
+	"closed": true
+}`
+
+func TestParse(t *testing.T) {
+	s, err := schema.ParseJSON([]byte(userSchemaJSON))
+	require.NoError(t, err)
+	assert.Equal(t, userSchema, s)
+
+	doc := map[string]interface{}{
+		"types": []string{"object"},
+		"keys": map[string]interface{}{
+			"id": map[string]interface{}{
+				"types": []string{"uint8", "0x40"},
+				"range": map[string]int{"min": 1, "max": 1000},
+			},
+			"name": map[string]interface{}{"types": []string{"string"}, "minLen": 1, "maxLen": 8},
+			"tags": map[string]interface{}{
+				"types":  []string{"list"},
+				"maxLen": 2,
+				"items":  map[string]interface{}{"types": []string{"string", "null"}},
+			},
+			"scores": map[string]interface{}{
+				"types": []string{"map"},
+				"items": map[string]interface{}{"range": map[string]int{"min": -10, "max": 10}},
+			},
+		},
+		"required": []string{"id", "name"},
+		"closed":   true,
+	}
+
+	s, err = schema.Parse(marshal(t, doc))
+	require.NoError(t, err)
+	assert.Equal(t, userSchema, s)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		err  string
+	}{
+		{"unknown type", `{"keys": {"id": {"types": ["int"]}}}`, `schema: .keys.id.types: binn: unknown type "int"`},
+		{"invalid type", `{"types": ["0x10"]}`, `schema: .types: binn: unknown type "0x10"`},
+		{"unknown field", `{"type": "object"}`, `schema: json: unknown field "type"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := schema.ParseJSON([]byte(test.json))
+
+			assert.EqualError(t, err, test.err)
+		})
+	}
+
+	_, err := schema.Parse(marshal(t, map[string]interface{}{"type": "object"}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,253 @@
+/*
+ * corpus.c writes the golden files of binngo with the reference
+ * BINN implementation, https://github.com/liteserver/binn. It hasn't
+ * been run yet, see ../README.md.
+ *
+ *   cc -o corpus corpus.c binn.c && ./corpus ../testdata
+ *
+ * Each case is a container, because the C library only serializes
+ * containers. Keep the cases in sync with golden_test.go.
+ */
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include "binn.h"
+
+static const char *dir;
+
+static void save(const char *name, binn *item) {
+	char path[1024];
+	FILE *f;
+
+	snprintf(path, sizeof(path), "%s/%s.bin", dir, name);
+	f = fopen(path, "wb");
+	if (f == NULL || fwrite(binn_ptr(item), binn_size(item), 1, f) != 1) {
+		perror(path);
+		exit(1);
+	}
+	fclose(f);
+	binn_free(item);
+}
+
+static char *repeat(char c, int n) {
+	char *s = malloc(n + 1);
+
+	memset(s, c, n);
+	s[n] = 0;
+
+	return s;
+}
+
+static void scalars(void) {
+	binn *list;
+
+	list = binn_list();
+	binn_list_add_null(list);
+	save("null", list);
+
+	list = binn_list();
+	binn_list_add_bool(list, TRUE);
+	binn_list_add_bool(list, FALSE);
+	save("bool", list);
+
+	list = binn_list();
+	binn_list_add_uint8(list, 0);
+	binn_list_add_uint8(list, 255);
+	save("uint8", list);
+
+	list = binn_list();
+	binn_list_add_int8(list, -128);
+	binn_list_add_int8(list, -1);
+	save("int8", list);
+
+	list = binn_list();
+	binn_list_add_uint16(list, 256);
+	binn_list_add_uint16(list, 65535);
+	save("uint16", list);
+
+	list = binn_list();
+	binn_list_add_int16(list, -129);
+	binn_list_add_int16(list, -32768);
+	save("int16", list);
+
+	list = binn_list();
+	binn_list_add_uint32(list, 65536);
+	binn_list_add_uint32(list, 4294967295U);
+	save("uint32", list);
+
+	list = binn_list();
+	binn_list_add_int32(list, -32769);
+	binn_list_add_int32(list, -2147483647 - 1);
+	save("int32", list);
+
+	list = binn_list();
+	binn_list_add_uint64(list, 4294967296ULL);
+	binn_list_add_uint64(list, 18446744073709551615ULL);
+	save("uint64", list);
+
+	list = binn_list();
+	binn_list_add_int64(list, -2147483649LL);
+	binn_list_add_int64(list, -9223372036854775807LL - 1);
+	save("int64", list);
+
+	/* The library stores integers in the smallest type that holds them. */
+	list = binn_list();
+	binn_list_add_int32(list, 200);
+	binn_list_add_int64(list, 70000);
+	binn_list_add_int64(list, 3000000000LL);
+	binn_list_add_uint64(list, 5);
+	binn_list_add_int32(list, -5);
+	save("int_compression", list);
+
+	list = binn_list();
+	binn_list_add_float(list, 1.5f);
+	binn_list_add_float(list, -0.25f);
+	save("float32", list);
+
+	list = binn_list();
+	binn_list_add_double(list, 3.141592653589793);
+	binn_list_add_double(list, -1e300);
+	save("float64", list);
+}
+
+static void strings(void) {
+	int64 currency;
+	binn *list;
+
+	list = binn_list();
+	binn_list_add_str(list, "");
+	binn_list_add_str(list, "hello");
+	binn_list_add_str(list, "UTF-8 \xe2\x9c\x93");
+	binn_list_add_str(list, repeat('a', 127));
+	binn_list_add_str(list, repeat('b', 128));
+	save("string", list);
+
+	list = binn_list();
+	binn_list_add(list, BINN_DATETIME, "2021-08-01 12:30:00", 0);
+	binn_list_add(list, BINN_DATE, "2021-08-01", 0);
+	binn_list_add(list, BINN_TIME, "12:30:00", 0);
+	binn_list_add(list, BINN_DECIMAL, "123.456", 0);
+	binn_list_add(list, BINN_CURRENCYSTR, "9.99", 0);
+	binn_list_add(list, BINN_SINGLE_STR, "1.5", 0);
+	binn_list_add(list, BINN_DOUBLE_STR, "2.25", 0);
+	save("string_types", list);
+
+	list = binn_list();
+	currency = 12345678;
+	binn_list_add(list, BINN_CURRENCY, &currency, 0);
+	currency = -1;
+	binn_list_add(list, BINN_CURRENCY, &currency, 0);
+	save("currency", list);
+}
+
+static void blobs(void) {
+	unsigned char small[10], *big;
+	binn *list;
+	int i;
+
+	for (i = 0; i < 10; i++) {
+		small[i] = i;
+	}
+
+	list = binn_list();
+	binn_list_add_blob(list, small, sizeof(small));
+	binn_list_add_blob(list, small, 0);
+	save("blob", list);
+
+	big = malloc(70000);
+	for (i = 0; i < 70000; i++) {
+		big[i] = i % 251;
+	}
+
+	list = binn_list();
+	binn_list_add_blob(list, big, 70000);
+	save("blob_big", list);
+
+	list = binn_list();
+	binn_list_add(list, BINN_HTML, "<p>hi</p>", 0);
+	binn_list_add(list, BINN_JPEG, "\xff\xd8\xff", 3);
+	save("user_types", list);
+}
+
+static void containers(void) {
+	binn *list, *obj, *map, *inner, *deep;
+	int i;
+
+	list = binn_list();
+	binn_list_add_list(list, binn_list());
+	binn_list_add_object(list, binn_object());
+	binn_list_add_map(list, binn_map());
+	save("empty_containers", list);
+
+	inner = binn_list();
+	binn_list_add_str(inner, "a");
+	binn_list_add_str(inner, "b");
+	obj = binn_object();
+	binn_object_set_int32(obj, "id", 1);
+	binn_object_set_str(obj, "name", "binn");
+	binn_object_set_list(obj, "tags", inner);
+	save("object", obj);
+
+	obj = binn_object();
+	binn_object_set_bool(obj, repeat('k', 255), TRUE);
+	binn_object_set_int32(obj, repeat('m', 127), 1);
+	binn_object_set_int32(obj, repeat('n', 128), 2);
+	save("object_keys", obj);
+
+	map = binn_map();
+	binn_map_set_str(map, 1, "one");
+	binn_map_set_str(map, -2, "minus two");
+	binn_map_set_bool(map, 2147483647, TRUE);
+	binn_map_set_bool(map, -2147483647 - 1, FALSE);
+	save("map", map);
+
+	deep = binn_list();
+	binn_list_add_int32(deep, 3);
+	inner = binn_list();
+	binn_list_add_int32(inner, 2);
+	binn_list_add_list(inner, deep);
+	list = binn_list();
+	binn_list_add_int32(list, 1);
+	binn_list_add_list(list, inner);
+	inner = binn_object();
+	binn_object_set_str(inner, "deep", "yes");
+	map = binn_map();
+	binn_map_set_object(map, 7, inner);
+	obj = binn_object();
+	binn_object_set_list(obj, "list", list);
+	binn_object_set_map(obj, "map", map);
+	list = binn_list();
+	binn_list_add_object(list, obj);
+	save("nested", list);
+
+	list = binn_list();
+	for (i = 0; i < 200; i++) {
+		binn_list_add_int32(list, i);
+	}
+	save("list_count_128", list);
+
+	/* The largest list with a one byte size, and the smallest with four. */
+	list = binn_list();
+	binn_list_add_str(list, repeat('c', 121));
+	save("list_size_127", list);
+
+	list = binn_list();
+	binn_list_add_str(list, repeat('c', 122));
+	save("list_size_128", list);
+}
+
+int main(int argc, char **argv) {
+	if (argc != 2) {
+		fprintf(stderr, "usage: corpus dir\n");
+		return 2;
+	}
+	dir = argv[1];
+
+	scalars();
+	strings();
+	blobs();
+	containers();
+
+	return 0;
+}
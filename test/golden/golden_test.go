@@ -0,0 +1,264 @@
+// Package golden_test checks the encoder and the decoder against
+// the golden BINN files in testdata, see README.md.
+package golden_test
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/et-nik/binngo/binnjson"
+	"github.com/et-nik/binngo/decode"
+	"github.com/et-nik/binngo/encode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type corpusCase struct {
+	name string
+	// json is the binnjson form of the file.
+	json string
+	// marshal is encoded and compared with the file byte by byte,
+	// nil if Marshal can't produce the file.
+	marshal interface{}
+	// want is the value decoded from the file, it's decoded into
+	// a new value of the same type.
+	want interface{}
+}
+
+type object struct {
+	ID   int      `binn:"id"`
+	Name string   `binn:"name"`
+	Tags []string `binn:"tags"`
+}
+
+// blob is a byte slice encoded as a blob, Marshal encodes
+// the other byte slices as lists.
+type blob []byte
+
+func (b blob) MarshalBinary() ([]byte, error) {
+	return b, nil
+}
+
+type nested struct {
+	List []interface{}             `binn:"list"`
+	Map  map[int]map[string]string `binn:"map"`
+}
+
+func corpus() []corpusCase {
+	big := make([]byte, 70000)
+	for i := range big {
+		big[i] = byte(i % 251)
+	}
+
+	count := make([]int, 200)
+	for i := range count {
+		count[i] = i
+	}
+
+	return []corpusCase{
+		{"null", `[null]`, []interface{}{nil}, []interface{}{nil}},
+		{"bool", `[true,false]`, []bool{true, false}, []bool{true, false}},
+		{"uint8", `[0,255]`, []uint{0, 255}, []uint8{0, 255}},
+		{"int8", `[-128,-1]`, []int8{-128, -1}, []int8{-128, -1}},
+		{"uint16", `[256,65535]`, []uint16{256, 65535}, []uint16{256, 65535}},
+		{"int16", `[-129,-32768]`, []int16{-129, -32768}, []int16{-129, -32768}},
+		{"uint32", `[65536,4294967295]`, []uint32{65536, 4294967295}, []uint32{65536, 4294967295}},
+		{"int32", `[-32769,-2147483648]`, []int32{-32769, -2147483648}, []int32{-32769, -2147483648}},
+		{
+			"uint64", `[4294967296,18446744073709551615]`,
+			[]uint64{4294967296, math.MaxUint64}, []uint64{4294967296, math.MaxUint64},
+		},
+		{
+			"int64", `[-2147483649,-9223372036854775808]`,
+			[]int64{-2147483649, math.MinInt64}, []int64{-2147483649, math.MinInt64},
+		},
+		{
+			"int_compression", `[200,70000,3000000000,5,-5]`,
+			[]interface{}{int32(200), int64(70000), int64(3000000000), uint64(5), int32(-5)},
+			[]int64{200, 70000, 3000000000, 5, -5},
+		},
+		{"float32", `[1.5,-0.25]`, []float32{1.5, -0.25}, []float32{1.5, -0.25}},
+		{"float64", `[3.141592653589793,-1e+300]`, []float64{math.Pi, -1e300}, []float64{math.Pi, -1e300}},
+		{
+			"string", `["","hello","UTF-8 ✓","` + strings.Repeat("a", 127) + `","` + strings.Repeat("b", 128) + `"]`,
+			[]string{"", "hello", "UTF-8 ✓", strings.Repeat("a", 127), strings.Repeat("b", 128)},
+			[]string{"", "hello", "UTF-8 ✓", strings.Repeat("a", 127), strings.Repeat("b", 128)},
+		},
+		{
+			"string_types",
+			`[{"$datetime":"2021-08-01 12:30:00"},{"$date":"2021-08-01"},{"$time":"12:30:00"},` +
+				`{"$decimal":"123.456"},{"$currencystr":"9.99"},{"$singlestr":"1.5"},{"$doublestr":"2.25"}]`,
+			nil,
+			[]string{"2021-08-01 12:30:00", "2021-08-01", "12:30:00", "123.456", "9.99", "1.5", "2.25"},
+		},
+		{"currency", `[{"$currency":12345678},{"$currency":-1}]`, nil, nil},
+		{
+			"blob", `[{"$blob":"AAECAwQFBgcICQ=="},{"$blob":""}]`,
+			[]blob{{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, {}},
+			[][]byte{{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, {}},
+		},
+		{"blob_big", "", []blob{big}, [][]byte{big}},
+		{
+			"user_types", `[{"$type":45057,"$data":"PHA+aGk8L3A+"},{"$type":53249,"$data":"/9j/"}]`,
+			nil,
+			[]interface{}{"<p>hi</p>", []byte{0xFF, 0xD8, 0xFF}},
+		},
+		{
+			"empty_containers", `[[],{},{"$map":{}}]`,
+			[]interface{}{[]int{}, struct{}{}, map[int]int{}},
+			[]interface{}{[]interface{}{}, map[string]interface{}{}, map[int]interface{}{}},
+		},
+		{
+			"object", `{"id":1,"name":"binn","tags":["a","b"]}`,
+			object{1, "binn", []string{"a", "b"}}, object{1, "binn", []string{"a", "b"}},
+		},
+		{
+			"object_keys",
+			`{"` + strings.Repeat("k", 255) + `":true,"` + strings.Repeat("m", 127) + `":1,"` +
+				strings.Repeat("n", 128) + `":2}`,
+			longKeys(), longKeys(),
+		},
+		{
+			"map", `{"$map":{"1":"one","-2":"minus two","2147483647":true,"-2147483648":false}}`,
+			nil,
+			map[int32]interface{}{1: "one", -2: "minus two", math.MaxInt32: true, math.MinInt32: false},
+		},
+		{
+			"nested", `[{"list":[1,[2,[3]]],"map":{"$map":{"7":{"deep":"yes"}}}}]`,
+			[]nested{{
+				List: []interface{}{1, []interface{}{2, []int{3}}},
+				Map:  map[int]map[string]string{7: {"deep": "yes"}},
+			}},
+			[]nested{{
+				List: []interface{}{uint8(1), []interface{}{uint8(2), []interface{}{uint8(3)}}},
+				Map:  map[int]map[string]string{7: {"deep": "yes"}},
+			}},
+		},
+		{"list_count_128", "", count, count},
+		{
+			"list_size_127", `["` + strings.Repeat("c", 121) + `"]`,
+			[]string{strings.Repeat("c", 121)}, []string{strings.Repeat("c", 121)},
+		},
+		{
+			"list_size_128", `["` + strings.Repeat("c", 122) + `"]`,
+			[]string{strings.Repeat("c", 122)}, []string{strings.Repeat("c", 122)},
+		},
+	}
+}
+
+// longKeys returns a struct with the keys of 255, 127 and 128 bytes,
+// which don't fit into struct tags written by hand.
+func longKeys() interface{} {
+	t := reflect.StructOf([]reflect.StructField{
+		{Name: "K", Type: reflect.TypeOf(true), Tag: reflect.StructTag(`binn:"` + strings.Repeat("k", 255) + `"`)},
+		{Name: "M", Type: reflect.TypeOf(0), Tag: reflect.StructTag(`binn:"` + strings.Repeat("m", 127) + `"`)},
+		{Name: "N", Type: reflect.TypeOf(0), Tag: reflect.StructTag(`binn:"` + strings.Repeat("n", 128) + `"`)},
+	})
+
+	v := reflect.New(t).Elem()
+	v.Field(0).SetBool(true)
+	v.Field(1).SetInt(1)
+	v.Field(2).SetInt(2)
+
+	return v.Interface()
+}
+
+func readCase(t *testing.T, name string) []byte {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata", name+".bin"))
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestCorpusFiles(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.bin"))
+	require.NoError(t, err)
+
+	names := map[string]bool{}
+	for _, c := range corpus() {
+		names[c.name] = true
+	}
+
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), ".bin")
+		assert.True(t, names[name], "%s has no test case", f)
+	}
+	assert.Len(t, files, len(names))
+}
+
+func TestEncode(t *testing.T) {
+	for _, c := range corpus() {
+		if c.marshal == nil {
+			continue
+		}
+
+		t.Run(c.name, func(t *testing.T) {
+			b, err := encode.Marshal(c.marshal)
+
+			require.NoError(t, err)
+			assert.Equal(t, readCase(t, c.name), b)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, c := range corpus() {
+		if c.want == nil {
+			continue
+		}
+
+		t.Run(c.name, func(t *testing.T) {
+			v := reflect.New(reflect.TypeOf(c.want))
+
+			err := decode.Unmarshal(readCase(t, c.name), v.Interface())
+
+			require.NoError(t, err)
+			assert.Equal(t, c.want, v.Elem().Interface())
+		})
+	}
+}
+
+func TestDecodeInterface(t *testing.T) {
+	for _, c := range corpus() {
+		t.Run(c.name, func(t *testing.T) {
+			var v interface{}
+
+			assert.NoError(t, decode.Unmarshal(readCase(t, c.name), &v))
+		})
+	}
+}
+
+// jsonOptions are the FromJSON options that convert the JSON
+// of the files back to the same bytes.
+var jsonOptions = map[string]*binnjson.Options{
+	"float32": {Float32: true},
+}
+
+func TestJSON(t *testing.T) {
+	for _, c := range corpus() {
+		if c.json == "" {
+			continue
+		}
+
+		t.Run(c.name, func(t *testing.T) {
+			b := readCase(t, c.name)
+
+			var j bytes.Buffer
+			require.NoError(t, binnjson.ToJSON(&j, bytes.NewReader(b)))
+			assert.Equal(t, c.json+"\n", j.String())
+
+			var back bytes.Buffer
+			require.NoError(t, binnjson.FromJSON(&back, &j, jsonOptions[c.name]))
+			if c.marshal != nil {
+				assert.Equal(t, b, back.Bytes())
+			}
+		})
+	}
+}